@@ -0,0 +1,41 @@
+// ============================================
+// Zetl - Embeddable quote widget
+// ============================================
+//
+// Tells the host page how tall the widget is whenever it changes, so the
+// iframe can be sized to fit, and reloads for a fresh quote when the
+// widget was embedded with ?refresh=<seconds>.
+//
+// Host pages size the iframe by listening for the resize messages:
+//
+//   window.addEventListener('message', (e) => {
+//     if (!e.data || e.data.type !== 'zetl:embed-resize') return;
+//     document.querySelectorAll('iframe').forEach(frame => {
+//       if (frame.contentWindow === e.source) frame.style.height = e.data.height + 'px';
+//     });
+//   });
+
+(() => {
+  const widget = document.querySelector('.embed');
+
+  function postHeight() {
+    if (window.parent === window) return;
+    window.parent.postMessage({
+      type: 'zetl:embed-resize',
+      height: Math.ceil(widget.getBoundingClientRect().height),
+    }, '*');
+  }
+
+  postHeight();
+  window.addEventListener('load', postHeight);
+  if ('ResizeObserver' in window) {
+    new ResizeObserver(postHeight).observe(widget);
+  } else {
+    window.addEventListener('resize', postHeight);
+  }
+
+  const refresh = parseInt(document.body.dataset.refresh, 10);
+  if (refresh > 0) {
+    setTimeout(() => window.location.reload(), refresh * 1000);
+  }
+})();
@@ -269,13 +269,15 @@ async function checkSession() {
 // ============================================
 // Edit Modal Functions
 // ============================================
-function openEditModal(quoteId, quote, author, book, tags, notes) {
+function openEditModal(quoteId, quote, author, book, tags, notes, citation, visibility) {
   document.getElementById('edit-quote-id').value = quoteId;
   document.getElementById('edit-quote-text').value = quote;
   document.getElementById('edit-author').value = author;
   document.getElementById('edit-book').value = book || '';
   document.getElementById('edit-tags').value = tags || '';
   document.getElementById('edit-notes').value = notes || '';
+  document.getElementById('edit-visibility').value = visibility || 'public';
+  fillCitationFields('edit', citation || {});
   document.getElementById('edit-error').classList.add('hidden');
 
   const modal = document.getElementById('edit-modal');
@@ -371,6 +373,211 @@ function closeAddModal() {
   document.body.style.overflow = '';
 }
 
+// ============================================
+// Citation Functions
+// ============================================
+const citationFields = {
+  page: 'page',
+  chapter: 'chapter',
+  location: 'location',
+  edition: 'edition',
+  source_url: 'source-url',
+  date_read: 'date-read'
+};
+
+function fillCitationFields(prefix, citation) {
+  Object.entries(citationFields).forEach(([key, id]) => {
+    const input = document.getElementById(`${prefix}-${id}`);
+    if (input) input.value = citation[key] || '';
+  });
+}
+
+function readCitationFields(prefix) {
+  const citation = {};
+  Object.entries(citationFields).forEach(([key, id]) => {
+    const input = document.getElementById(`${prefix}-${id}`);
+    if (input) citation[key] = input.value.trim();
+  });
+  return citation;
+}
+
+// Copy a formatted citation to the clipboard (MLA by default)
+async function copyCitation(quoteId, button, style = 'mla') {
+  const label = button.textContent;
+  try {
+    const response = await fetch(`/quote/${quoteId}/citation?style=${style}`, {
+      credentials: 'same-origin'
+    });
+    const data = await response.json();
+    if (!response.ok) throw new Error(data.error);
+
+    await navigator.clipboard.writeText(data.citations[0].text);
+    button.textContent = 'Copied!';
+  } catch (error) {
+    button.textContent = 'Copy failed';
+  }
+  setTimeout(() => { button.textContent = label; }, 1500);
+}
+
+// ============================================
+// Add to Collection Modal Functions
+// ============================================
+async function openCollectionModal(quoteId) {
+  document.getElementById('collection-quote-id').value = quoteId;
+  document.getElementById('collection-modal-error').classList.add('hidden');
+
+  const select = document.getElementById('collection-select');
+  const empty = document.getElementById('collection-empty');
+  select.innerHTML = '';
+
+  try {
+    const response = await fetch('/api/collections', { credentials: 'same-origin' });
+    const data = await response.json();
+    (data.collections || []).forEach(collection => {
+      const option = document.createElement('option');
+      option.value = collection.collection_id;
+      option.textContent = collection.name;
+      select.appendChild(option);
+    });
+  } catch (error) {
+    console.error('Failed to load collections:', error);
+  }
+
+  const hasCollections = select.options.length > 0;
+  select.classList.toggle('hidden', !hasCollections);
+  empty.classList.toggle('hidden', hasCollections);
+
+  const modal = document.getElementById('collection-modal');
+  modal.classList.add('open');
+  document.body.style.overflow = 'hidden';
+}
+
+function closeCollectionModal() {
+  const modal = document.getElementById('collection-modal');
+  if (!modal) return;
+  modal.classList.remove('open');
+  document.body.style.overflow = '';
+}
+
+async function confirmAddToCollection() {
+  const errorDiv = document.getElementById('collection-modal-error');
+  errorDiv.classList.add('hidden');
+
+  const collectionId = document.getElementById('collection-select').value;
+  const quoteId = parseInt(document.getElementById('collection-quote-id').value);
+  if (!collectionId) return;
+
+  try {
+    const response = await fetch(`/api/collections/${collectionId}/quotes`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      credentials: 'same-origin',
+      body: JSON.stringify({ quote_id: quoteId })
+    });
+
+    if (response.ok) {
+      closeCollectionModal();
+    } else {
+      const data = await response.json();
+      errorDiv.textContent = data.error || 'Failed to add quote to collection.';
+      errorDiv.classList.remove('hidden');
+    }
+  } catch (error) {
+    errorDiv.textContent = 'An error occurred. Please try again.';
+    errorDiv.classList.remove('hidden');
+  }
+}
+
+// ============================================
+// Share Links
+// ============================================
+// kind is 'quote' or 'collection'
+async function createShareLink(kind, id) {
+  const endpoint = kind === 'collection'
+    ? `/api/collections/${id}/share`
+    : `/api/quote/${id}/share`;
+
+  try {
+    const response = await fetch(endpoint, {
+      method: 'POST',
+      credentials: 'same-origin'
+    });
+    const data = await response.json();
+    if (!response.ok) throw new Error(data.error);
+
+    const url = window.location.origin + data.url;
+    try {
+      await navigator.clipboard.writeText(url);
+      alert('Share link copied to clipboard:\n' + url);
+    } catch (error) {
+      prompt('Copy this share link:', url);
+    }
+  } catch (error) {
+    alert(error.message || 'Failed to create share link.');
+  }
+}
+
+// ============================================
+// Likes and Saves
+// ============================================
+// Like or unlike someone else's quote, updating the button in place
+async function toggleLike(quoteId, button) {
+  const liked = button.dataset.liked === 'true';
+
+  try {
+    const response = await fetch(`/api/quote/${quoteId}/like`, {
+      method: liked ? 'DELETE' : 'POST',
+      credentials: 'same-origin'
+    });
+    const data = await response.json();
+    if (!response.ok) throw new Error(data.error);
+
+    button.dataset.liked = String(data.liked);
+    button.querySelector('.like-count').textContent = data.like_count;
+    button.querySelector('svg').setAttribute('fill', data.liked ? 'currentColor' : 'none');
+    button.classList.toggle('text-rose-400', data.liked);
+    button.classList.toggle('text-zinc-500', !data.liked);
+  } catch (error) {
+    alert(error.message || 'Failed to update like.');
+  }
+}
+
+// Save a copy of someone else's quote to the current user's quotes
+async function saveQuote(quoteId) {
+  try {
+    const response = await fetch(`/api/quote/${quoteId}/save`, {
+      method: 'POST',
+      credentials: 'same-origin'
+    });
+    const data = await response.json();
+    if (!response.ok) throw new Error(data.error);
+
+    alert('Saved to your quotes.');
+  } catch (error) {
+    alert(error.message || 'Failed to save quote.');
+  }
+}
+
+// Show who saved a copy of one of the current user's quotes
+async function showSavers(quoteId) {
+  try {
+    const response = await fetch(`/api/quote/${quoteId}/saves`, {
+      credentials: 'same-origin'
+    });
+    const data = await response.json();
+    if (!response.ok) throw new Error(data.error);
+
+    if (data.savers.length === 0) {
+      alert('Nobody has saved this quote yet.');
+      return;
+    }
+    const names = data.savers.map(s => `${s.username} (${new Date(s.saved_at).toLocaleDateString()})`);
+    alert('Saved by:\n' + names.join('\n'));
+  } catch (error) {
+    alert(error.message || 'Failed to load who saved this quote.');
+  }
+}
+
 // ============================================
 // Form Submission Handlers
 // ============================================
@@ -397,7 +604,9 @@ function initFormHandlers() {
       author: document.getElementById('edit-author').value,
       book: document.getElementById('edit-book').value,
       tags: tags,
-      notes: document.getElementById('edit-notes').value
+      notes: document.getElementById('edit-notes').value,
+      visibility: document.getElementById('edit-visibility').value,
+      ...readCitationFields('edit')
     };
 
     try {
@@ -445,7 +654,9 @@ function initFormHandlers() {
       author: document.getElementById('add-author').value,
       book: document.getElementById('add-book').value,
       tags: tags,
-      notes: document.getElementById('add-notes').value
+      notes: document.getElementById('add-notes').value,
+      visibility: document.getElementById('add-visibility').value,
+      ...readCitationFields('add')
     };
 
     try {
@@ -490,9 +701,10 @@ function initModalHandlers() {
   // Close modals on Escape key
   document.addEventListener('keydown', (e) => {
     if (e.key === 'Escape') {
-      closeEditModal();
-      closeDeleteModal();
-      closeAddModal();
+      document.querySelectorAll('.modal-overlay.open').forEach(overlay => {
+        overlay.classList.remove('open');
+      });
+      document.body.style.overflow = '';
     }
   });
 }
@@ -823,10 +1035,88 @@ function initSearch() {
   populateFilterDropdown();
 }
 
-// Hide quote function (for non-owner cards)
-function hideQuote(quoteId) {
-  const card = document.querySelector(`[data-quote-id="${quoteId}"]`);
-  if (card) {
+// Hide quote function (for non-owner cards). Logged-in users' hidden quotes
+// are saved so they stay hidden on reload.
+async function hideQuote(quoteId) {
+  if (document.body.dataset.userId) {
+    try {
+      const response = await fetch(`/api/quote/${quoteId}/hide`, {
+        method: 'POST',
+        credentials: 'same-origin'
+      });
+      if (!response.ok) {
+        const data = await response.json();
+        throw new Error(data.error);
+      }
+    } catch (error) {
+      alert(error.message || 'Failed to hide quote.');
+      return;
+    }
+  }
+
+  removeCards(document.querySelectorAll(`.quote-card[data-quote-id="${quoteId}"]`));
+}
+
+// Mute a user, hiding all of their quotes from the feed
+async function muteUser(userId) {
+  if (!confirm('Hide all quotes from this user? You can unmute them in Settings.')) return;
+
+  try {
+    const response = await fetch(`/api/user/${userId}/mute`, {
+      method: 'POST',
+      credentials: 'same-origin'
+    });
+    if (!response.ok) {
+      const data = await response.json();
+      throw new Error(data.error);
+    }
+  } catch (error) {
+    alert(error.message || 'Failed to mute user.');
+    return;
+  }
+
+  removeCards(document.querySelectorAll(`.quote-card[data-user-id="${userId}"]`));
+}
+
+// Follow a user so their new public quotes appear in the feed
+async function followUser(userId) {
+  try {
+    const response = await fetch(`/api/user/${userId}/follow`, {
+      method: 'POST',
+      credentials: 'same-origin'
+    });
+    const data = await response.json();
+    if (!response.ok) throw new Error(data.error);
+
+    alert('Following. Their new quotes will appear in your feed.');
+  } catch (error) {
+    alert(error.message || 'Failed to follow user.');
+  }
+}
+
+// Unfollow a user from the feed page's following list
+async function unfollowUser(userId, button) {
+  try {
+    const response = await fetch(`/api/user/${userId}/follow`, {
+      method: 'DELETE',
+      credentials: 'same-origin'
+    });
+    if (!response.ok) {
+      const data = await response.json();
+      throw new Error(data.error);
+    }
+  } catch (error) {
+    alert(error.message || 'Failed to unfollow user.');
+    return;
+  }
+
+  button.parentElement.remove();
+  removeCards(document.querySelectorAll(`.quote-card[data-user-id="${userId}"]`));
+}
+
+// Animate cards out and drop them from the filter state
+function removeCards(cards) {
+  cards.forEach(card => {
     // Animate out
     card.style.transition = 'all 0.3s ease';
     card.style.transform = 'scale(0.8)';
@@ -839,7 +1129,7 @@ function hideQuote(quoteId) {
         cardData.splice(index, 1);
       }
     }, 300);
-  }
+  });
 }
 
 // ============================================
@@ -859,6 +1149,13 @@ window.openDeleteModal = openDeleteModal;
 window.closeDeleteModal = closeDeleteModal;
 window.confirmDelete = confirmDelete;
 window.hideQuote = hideQuote;
+window.muteUser = muteUser;
+window.followUser = followUser;
+window.unfollowUser = unfollowUser;
+window.createShareLink = createShareLink;
+window.toggleLike = toggleLike;
+window.saveQuote = saveQuote;
+window.showSavers = showSavers;
 
 // ============================================
 // Writing Prompt Panel
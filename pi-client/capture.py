@@ -19,6 +19,7 @@ import os
 import subprocess
 import sys
 import tempfile
+import uuid
 
 import anthropic
 import requests
@@ -34,9 +35,11 @@ WEBCAM_DEVICE = os.getenv("WEBCAM_DEVICE", "0")
 SYSTEM_PROMPT = (
     "You are an OCR assistant. Extract quote fields from a photo of a handwritten notecard. "
     "Return ONLY valid JSON with no extra text, markdown, or code fences. "
-    "Schema: {\"quote\": string, \"author\": string, \"book\": string, \"tags\": [string], \"notes\": string}. "
+    "Schema: {\"quote\": string, \"author\": string, \"book\": string, \"tags\": [string], \"notes\": string, \"page\": string}. "
     "Rules: quote, author, and book are required — use \"Unknown\" if genuinely illegible. "
-    "tags defaults to [] and notes defaults to \"\" if not present on the card."
+    "tags defaults to [] and notes defaults to \"\" if not present on the card. "
+    "page is the page number or range written on the card (e.g. \"42\" or \"42-43\"), as a string; "
+    "use \"\" if no page is written."
 )
 
 
@@ -96,13 +99,19 @@ def ocr_image(image_path: str) -> dict:
 
 def post_quote(quote_data: dict) -> dict:
     url = f"{ZETL_URL}/api/device/quote"
+    # Sent with the upload so failures can be matched to server log lines
+    request_id = uuid.uuid4().hex
     headers = {
         "Authorization": f"Bearer {API_TOKEN}",
         "Content-Type": "application/json",
+        "X-Request-ID": request_id,
     }
-    resp = requests.post(url, json=quote_data, headers=headers, timeout=10)
+    try:
+        resp = requests.post(url, json=quote_data, headers=headers, timeout=10)
+    except requests.RequestException as e:
+        raise RuntimeError(f"{e} (request ID {request_id})") from e
     if not resp.ok:
-        raise RuntimeError(f"HTTP {resp.status_code}: {resp.text}")
+        raise RuntimeError(f"HTTP {resp.status_code}: {resp.text} (request ID {request_id})")
     return resp.json()
 
 
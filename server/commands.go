@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zach-monroe/zetl/server/config"
+)
+
+const usage = `Usage:
+  zetl                        start the server
+  zetl config check [-config path]
+                              validate configuration and print the effective values`
+
+// runCommand handles command line subcommands and returns the exit code
+func runCommand(args []string) int {
+	if len(args) >= 2 && args[0] == "config" && args[1] == "check" {
+		return configCheck(args[2:])
+	}
+
+	fmt.Fprintln(os.Stderr, usage)
+	return 2
+}
+
+// configCheck loads configuration the same way the server does and prints it
+// with secrets masked, followed by any validation problems
+func configCheck(args []string) int {
+	flags := flag.NewFlagSet("config check", flag.ContinueOnError)
+	path := flags.String("config", "", "path to a YAML config file (defaults to $ZETL_CONFIG)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*path)
+	cfg.WriteReport(os.Stdout)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\n%v\n", err)
+		return 1
+	}
+
+	fmt.Println("\nConfiguration OK")
+	return 0
+}
@@ -16,6 +16,36 @@ const (
 	// Limits
 	MaxQuotesPerPrompt = 10
 
+	// Quote image cards
+	QuoteCardCacheSize = 256
+	QuoteCardMaxAge    = 86400 // 24 hours in seconds
+
+	// How often deleted quotes past their retention are purged
+	TrashPurgeInterval = time.Hour
+
+	// Quote digest emails
+	DigestSendInterval   = 5 * time.Minute
+	DigestBatchSize      = 100
+	DigestLease          = 30 * time.Minute // before another replica may retry a claimed batch
+	DigestQuoteCount     = 5
+	DigestOnThisDayCount = 3
+
+	// RSS, Atom and JSON feeds of public quotes
+	FeedEntryLimit = 50
+	FeedMaxAge     = 900 // 15 minutes in seconds
+
+	// Embeddable quote widgets
+	EmbedMaxAge     = 300 // 5 minutes in seconds
+	EmbedMinRefresh = 30  // seconds
+	EmbedMaxRefresh = 86400
+
+	// Outgoing webhooks
+	MaxWebhooksPerUser    = 10
+	WebhookPollInterval   = 15 * time.Second
+	WebhookBatchSize      = 50
+	WebhookTimeout        = 10 * time.Second
+	WebhookDeliveryLogMax = 50
+
 	// Validation
 	MinPasswordLength = 8
 	MinUsernameLength = 3
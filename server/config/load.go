@@ -0,0 +1,521 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+)
+
+// MaxHeaderBytes caps the size of request headers accepted by the server
+const MaxHeaderBytes = 1 << 20 // 1 MB
+
+// Config is the application's runtime configuration. It is built once at
+// startup by Load and passed to the components that need it.
+type Config struct {
+	Database Database
+	Server   Server
+	Session  Session
+	SMTP     SMTP
+	Gemini   Gemini
+	Device   Device
+	Books    Books
+	Trash    Trash
+
+	// AppURL is the public base URL used in links sent by email and in
+	// absolute links on shared pages, feeds and embeds
+	AppURL   string
+	LogLevel string
+
+	sources map[string]string
+}
+
+// Database holds PostgreSQL connection, TLS and pool settings
+type Database struct {
+	// URL is a complete DSN; when set it replaces the individual fields below
+	URL      string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+
+	// SSLMode is one of disable, require, verify-ca or verify-full. When empty,
+	// URL keeps its own sslmode and the individual fields use disable.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ConnectTimeout is how long startup keeps retrying before giving up
+	ConnectTimeout time.Duration
+}
+
+// sslModes are the modes supported by lib/pq
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// Server holds the HTTP server's listen address, timeouts and shutdown behavior
+type Server struct {
+	ListenAddr string
+	// MetricsAddr is the internal listener serving /metrics, kept off the
+	// public router so the ingress never exposes it. Empty disables it.
+	MetricsAddr       string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout must leave room for slow LLM calls in prompt generation
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// DrainDelay is how long /readyz reports failure before the listener
+	// closes, giving the load balancer time to stop routing new requests
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish
+	ShutdownTimeout time.Duration
+}
+
+// Session holds cookie session settings
+type Session struct {
+	Secret string
+}
+
+// SMTP holds outgoing mail settings. Email is disabled when Host is empty.
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// IsConfigured reports whether outgoing email is enabled
+func (s SMTP) IsConfigured() bool {
+	return s.Host != ""
+}
+
+// Gemini holds LLM settings. Prompt generation is disabled without an API key.
+type Gemini struct {
+	APIKey string
+}
+
+// Device holds the bearer token used by the Pi client
+type Device struct {
+	APIToken string
+	UserID   int
+}
+
+// IsConfigured reports whether device uploads are enabled
+func (d Device) IsConfigured() bool {
+	return d.APIToken != ""
+}
+
+// Books holds book catalog settings
+type Books struct {
+	// CatalogFixture points at a JSON fixture used instead of Open Library
+	CatalogFixture string
+}
+
+// Trash holds soft-delete settings
+type Trash struct {
+	// Retention is how long deleted quotes stay in the trash before they are purged
+	Retention time.Duration
+}
+
+// Default returns the configuration used before any source is applied
+func Default() *Config {
+	return &Config{
+		Database: Database{
+			Port:            5432,
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectTimeout:  time.Minute,
+		},
+		Server: Server{
+			ListenAddr:        ":8080",
+			MetricsAddr:       ":9090",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      90 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			DrainDelay:        5 * time.Second,
+			ShutdownTimeout:   25 * time.Second,
+		},
+		SMTP:     SMTP{Port: 587},
+		Trash:    Trash{Retention: 30 * 24 * time.Hour},
+		LogLevel: "info",
+		sources:  map[string]string{},
+	}
+}
+
+// ValidationError lists every problem found while loading configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, p := range e.Problems {
+		b.WriteString("\n  - ")
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// Load builds the configuration from, in increasing priority: defaults, the
+// YAML file at path (or $ZETL_CONFIG), environment variables (including a
+// .env file next to the server) and NAME_FILE variables that point at files
+// holding a value, as mounted by k8s secrets.
+//
+// The returned config is never nil, so callers can report what was loaded
+// even when the returned error is a *ValidationError.
+func Load(path string) (*Config, error) {
+	loadDotEnv()
+
+	cfg := Default()
+	var problems []string
+
+	if path == "" {
+		path = os.Getenv("ZETL_CONFIG")
+	}
+	if path != "" {
+		problems = append(problems, cfg.applyFile(path)...)
+	}
+	problems = append(problems, cfg.applyEnv()...)
+	problems = append(problems, cfg.Validate()...)
+
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// loadDotEnv loads server/.env for local development. Variables already set
+// in the environment take precedence.
+func loadDotEnv() {
+	_, filename, _, _ := runtime.Caller(0) // Gets load.go location
+	serverDir := filepath.Dir(filepath.Dir(filename))
+	envPath := filepath.Join(serverDir, ".env")
+	if err := godotenv.Load(envPath); err != nil {
+		slog.Debug(".env not found, using system env vars", "path", envPath)
+	}
+}
+
+// applyFile reads a YAML file whose nested keys match the setting keys,
+// e.g. database: {host: db} sets database.host
+func (c *Config) applyFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read config file: %v", err)}
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return []string{fmt.Sprintf("cannot parse config file %s: %v", path, err)}
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+
+	byKey := map[string]setting{}
+	for _, s := range c.settings() {
+		byKey[s.key] = s
+	}
+
+	var problems []string
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s, ok := byKey[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown key %q", path, key))
+			continue
+		}
+		if err := s.set(values[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s: %v", path, key, err))
+			continue
+		}
+		c.sources[s.env] = "file"
+	}
+	return problems
+}
+
+func flatten(prefix string, in map[string]interface{}, out map[string]string) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = fmt.Sprint(v)
+	}
+}
+
+// applyEnv reads NAME and NAME_FILE for every setting
+func (c *Config) applyEnv() []string {
+	var problems []string
+
+	for _, s := range c.settings() {
+		value, inEnv := os.LookupEnv(s.env)
+		filePath, inFile := os.LookupEnv(s.env + "_FILE")
+		source := "env"
+
+		if inEnv && inFile && value != "" && filePath != "" {
+			problems = append(problems, fmt.Sprintf("set only one of %s and %s_FILE", s.env, s.env))
+			continue
+		}
+		if inFile && filePath != "" {
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: %v", s.env, err))
+				continue
+			}
+			value, inEnv = strings.TrimRight(string(data), "\r\n"), true
+			source = "env file"
+		}
+		if !inEnv || value == "" {
+			continue
+		}
+
+		if err := s.set(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", s.env, err))
+			continue
+		}
+		c.sources[s.env] = source
+	}
+	return problems
+}
+
+// Validate checks the configuration and returns a readable description of
+// every problem found
+func (c *Config) Validate() []string {
+	var problems []string
+	require := func(value, env string) {
+		if value == "" {
+			problems = append(problems, env+" is required")
+		}
+	}
+
+	if c.Database.URL == "" {
+		require(c.Database.Host, "DB_HOSTNAME")
+		require(c.Database.User, "DB_USERNAME")
+		require(c.Database.Name, "DB_NAME")
+		if c.Database.Port < 1 || c.Database.Port > 65535 {
+			problems = append(problems, "DB_PORT must be between 1 and 65535")
+		}
+	}
+	if c.Database.SSLMode != "" && !slices.Contains(sslModes, c.Database.SSLMode) {
+		problems = append(problems, "DB_SSLMODE must be one of "+strings.Join(sslModes, ", "))
+	}
+	if (c.Database.SSLCert == "") != (c.Database.SSLKey == "") {
+		problems = append(problems, "DB_SSLCERT and DB_SSLKEY must be set together")
+	}
+	for env, path := range map[string]string{
+		"DB_SSLROOTCERT": c.Database.SSLRootCert,
+		"DB_SSLCERT":     c.Database.SSLCert,
+		"DB_SSLKEY":      c.Database.SSLKey,
+	} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", env, err))
+		}
+	}
+	if c.Database.MaxOpenConns < 1 {
+		problems = append(problems, "DB_MAX_OPEN_CONNS must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+	if c.Database.ConnectTimeout <= 0 {
+		problems = append(problems, "DB_CONNECT_TIMEOUT must be greater than zero")
+	}
+
+	require(c.Session.Secret, "SESSION_SECRET")
+
+	if c.Server.ListenAddr == "" {
+		problems = append(problems, "LISTEN_ADDR is required")
+	}
+	if c.Server.MetricsAddr != "" && c.Server.MetricsAddr == c.Server.ListenAddr {
+		problems = append(problems, "METRICS_ADDR must differ from LISTEN_ADDR")
+	}
+	if c.Server.WriteTimeout <= 0 || c.Server.ReadHeaderTimeout <= 0 {
+		problems = append(problems, "HTTP_WRITE_TIMEOUT and HTTP_READ_HEADER_TIMEOUT must be greater than zero")
+	}
+
+	if c.AppURL != "" {
+		if u, err := url.Parse(c.AppURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, "APP_URL must be an absolute http(s) URL")
+		}
+	}
+
+	if c.SMTP.IsConfigured() {
+		require(c.SMTP.Username, "SMTP_USERNAME")
+		require(c.SMTP.Password, "SMTP_PASSWORD")
+		require(c.SMTP.From, "SMTP_FROM")
+		if c.AppURL == "" {
+			problems = append(problems, "APP_URL is required when SMTP is configured (it is used in reset links)")
+		}
+		if c.SMTP.Port < 1 || c.SMTP.Port > 65535 {
+			problems = append(problems, "SMTP_PORT must be between 1 and 65535")
+		}
+	}
+
+	if c.Device.IsConfigured() && c.Device.UserID <= 0 {
+		problems = append(problems, "API_TOKEN_USER_ID must be set to a user ID when API_TOKEN is set")
+	}
+
+	if c.Books.CatalogFixture != "" {
+		if _, err := os.Stat(c.Books.CatalogFixture); err != nil {
+			problems = append(problems, fmt.Sprintf("BOOK_CATALOG_FIXTURE: %v", err))
+		}
+	}
+
+	if c.Trash.Retention <= 0 {
+		problems = append(problems, "TRASH_RETENTION must be greater than zero")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		problems = append(problems, "LOG_LEVEL must be one of debug, info, warn, error")
+	}
+
+	return problems
+}
+
+// SlogLevel returns LogLevel as a slog.Level, defaulting to info
+func (c *Config) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// setting binds one configuration value to its environment variable and YAML key
+type setting struct {
+	env    string
+	key    string
+	secret bool
+	get    func() string
+	set    func(string) error
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		secretSetting("DATABASE_URL", "database.url", &c.Database.URL),
+		stringSetting("DB_HOSTNAME", "database.host", &c.Database.Host),
+		intSetting("DB_PORT", "database.port", &c.Database.Port),
+		stringSetting("DB_USERNAME", "database.user", &c.Database.User),
+		secretSetting("DB_PASSWORD", "database.password", &c.Database.Password),
+		stringSetting("DB_NAME", "database.name", &c.Database.Name),
+		stringSetting("DB_SSLMODE", "database.sslmode", &c.Database.SSLMode),
+		stringSetting("DB_SSLROOTCERT", "database.sslrootcert", &c.Database.SSLRootCert),
+		stringSetting("DB_SSLCERT", "database.sslcert", &c.Database.SSLCert),
+		stringSetting("DB_SSLKEY", "database.sslkey", &c.Database.SSLKey),
+		intSetting("DB_MAX_OPEN_CONNS", "database.max_open_conns", &c.Database.MaxOpenConns),
+		intSetting("DB_MAX_IDLE_CONNS", "database.max_idle_conns", &c.Database.MaxIdleConns),
+		durationSetting("DB_CONN_MAX_LIFETIME", "database.conn_max_lifetime", &c.Database.ConnMaxLifetime),
+		durationSetting("DB_CONN_MAX_IDLE_TIME", "database.conn_max_idle_time", &c.Database.ConnMaxIdleTime),
+		durationSetting("DB_CONNECT_TIMEOUT", "database.connect_timeout", &c.Database.ConnectTimeout),
+
+		stringSetting("LISTEN_ADDR", "server.listen_addr", &c.Server.ListenAddr),
+		stringSetting("METRICS_ADDR", "server.metrics_addr", &c.Server.MetricsAddr),
+		durationSetting("HTTP_READ_TIMEOUT", "server.read_timeout", &c.Server.ReadTimeout),
+		durationSetting("HTTP_READ_HEADER_TIMEOUT", "server.read_header_timeout", &c.Server.ReadHeaderTimeout),
+		durationSetting("HTTP_WRITE_TIMEOUT", "server.write_timeout", &c.Server.WriteTimeout),
+		durationSetting("HTTP_IDLE_TIMEOUT", "server.idle_timeout", &c.Server.IdleTimeout),
+		durationSetting("SHUTDOWN_DRAIN_DELAY", "server.drain_delay", &c.Server.DrainDelay),
+		durationSetting("SHUTDOWN_TIMEOUT", "server.shutdown_timeout", &c.Server.ShutdownTimeout),
+
+		secretSetting("SESSION_SECRET", "session.secret", &c.Session.Secret),
+		stringSetting("APP_URL", "app_url", &c.AppURL),
+
+		stringSetting("SMTP_HOST", "smtp.host", &c.SMTP.Host),
+		intSetting("SMTP_PORT", "smtp.port", &c.SMTP.Port),
+		stringSetting("SMTP_USERNAME", "smtp.username", &c.SMTP.Username),
+		secretSetting("SMTP_PASSWORD", "smtp.password", &c.SMTP.Password),
+		stringSetting("SMTP_FROM", "smtp.from", &c.SMTP.From),
+
+		secretSetting("GEMINI_API_KEY", "gemini.api_key", &c.Gemini.APIKey),
+
+		secretSetting("API_TOKEN", "device.api_token", &c.Device.APIToken),
+		intSetting("API_TOKEN_USER_ID", "device.user_id", &c.Device.UserID),
+
+		stringSetting("BOOK_CATALOG_FIXTURE", "books.catalog_fixture", &c.Books.CatalogFixture),
+
+		durationSetting("TRASH_RETENTION", "trash.retention", &c.Trash.Retention),
+		stringSetting("LOG_LEVEL", "log_level", &c.LogLevel),
+	}
+}
+
+func stringSetting(env, key string, dst *string) setting {
+	return setting{
+		env: env,
+		key: key,
+		get: func() string { return *dst },
+		set: func(v string) error { *dst = v; return nil },
+	}
+}
+
+func secretSetting(env, key string, dst *string) setting {
+	s := stringSetting(env, key, dst)
+	s.secret = true
+	return s
+}
+
+func intSetting(env, key string, dst *int) setting {
+	return setting{
+		env: env,
+		key: key,
+		get: func() string { return strconv.Itoa(*dst) },
+		set: func(v string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("%q is not a whole number", v)
+			}
+			*dst = n
+			return nil
+		},
+	}
+}
+
+func durationSetting(env, key string, dst *time.Duration) setting {
+	return setting{
+		env: env,
+		key: key,
+		get: func() string { return dst.String() },
+		set: func(v string) error {
+			d, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil || d < 0 {
+				return errors.New("expected a duration such as 30s or 2m")
+			}
+			*dst = d
+			return nil
+		},
+	}
+}
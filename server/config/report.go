@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteReport prints every setting with its effective value and where it came
+// from. Secrets are masked.
+func (c *Config) WriteReport(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tKEY\tVALUE\tSOURCE")
+
+	for _, s := range c.settings() {
+		value := s.get()
+		switch {
+		case s.secret && value != "":
+			value = "********"
+		case value == "":
+			value = "(unset)"
+		}
+
+		source := c.sources[s.env]
+		if source == "" {
+			source = "default"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.env, s.key, value, source)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// EnsureBook records a book title for a user if it hasn't been seen before.
+// Returns the book ID and whether a new row was created.
+func EnsureBook(ctx context.Context, db *sql.DB, userID int, title, author string) (int, bool, error) {
+	query := `
+		INSERT INTO books (user_id, title, author)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, title) DO NOTHING
+		RETURNING book_id
+	`
+
+	var bookID int
+	err := db.QueryRowContext(ctx, query, userID, title, author).Scan(&bookID)
+	if err == nil {
+		return bookID, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, err
+	}
+
+	err = db.QueryRowContext(ctx, `SELECT book_id FROM books WHERE user_id = $1 AND title = $2`, userID, title).Scan(&bookID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return bookID, false, nil
+}
+
+// GetBookByID retrieves a book along with its candidate matches
+func GetBookByID(ctx context.Context, db *sql.DB, bookID int) (*models.Book, error) {
+	query := `
+		SELECT book_id, user_id, title, author, isbn, cover_url, publish_year, page_count, status, created_at, updated_at
+		FROM books
+		WHERE book_id = $1
+	`
+
+	book, err := scanBook(db.QueryRowContext(ctx, query, bookID))
+	if err == sql.ErrNoRows {
+		return nil, ErrBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	book.Candidates, err = getBookCandidates(ctx, db, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// GetBooksByUserID retrieves all books for a user along with their candidate matches
+func GetBooksByUserID(ctx context.Context, db *sql.DB, userID int) ([]models.Book, error) {
+	query := `
+		SELECT book_id, user_id, title, author, isbn, cover_url, publish_year, page_count, status, created_at, updated_at
+		FROM books
+		WHERE user_id = $1
+		ORDER BY title
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make([]models.Book, 0)
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, *book)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range books {
+		books[i].Candidates, err = getBookCandidates(ctx, db, books[i].BookID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return books, nil
+}
+
+// GetBookByTitle retrieves a user's book by its title
+func GetBookByTitle(ctx context.Context, db *sql.DB, userID int, title string) (*models.Book, error) {
+	query := `
+		SELECT book_id, user_id, title, author, isbn, cover_url, publish_year, page_count, status, created_at, updated_at
+		FROM books
+		WHERE user_id = $1 AND title = $2
+	`
+
+	book, err := scanBook(db.QueryRowContext(ctx, query, userID, title))
+	if err == sql.ErrNoRows {
+		return nil, ErrBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// GetPendingBookIDs returns books still waiting for a catalog lookup
+func GetPendingBookIDs(ctx context.Context, db *sql.DB) ([]int, error) {
+	rows, err := db.QueryContext(ctx, `SELECT book_id FROM books WHERE status = $1 ORDER BY book_id`, models.BookStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ReplaceBookCandidates swaps a book's candidate matches and updates its status
+func ReplaceBookCandidates(ctx context.Context, db *sql.DB, bookID int, candidates []models.BookCandidate, status string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM book_candidates WHERE book_id = $1`, bookID); err != nil {
+		return err
+	}
+
+	insert := `
+		INSERT INTO book_candidates (book_id, title, author, isbn, cover_url, publish_year, page_count, source_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for _, c := range candidates {
+		_, err := tx.ExecContext(ctx, insert, bookID, c.Title, c.Author, c.ISBN, c.CoverURL, c.PublishYear, c.PageCount, c.SourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE books SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE book_id = $2`, status, bookID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return ErrBookNotFound
+	}
+
+	return tx.Commit()
+}
+
+// ApplyBookMetadata copies a confirmed match onto the book and clears remaining candidates
+func ApplyBookMetadata(ctx context.Context, db *sql.DB, bookID int, match models.BookCandidate) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE books
+		SET isbn = $1, cover_url = $2, publish_year = $3, page_count = $4, status = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE book_id = $6
+	`
+
+	result, err := tx.ExecContext(ctx, query, match.ISBN, match.CoverURL, match.PublishYear, match.PageCount, models.BookStatusConfirmed, bookID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return ErrBookNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM book_candidates WHERE book_id = $1`, bookID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetBookStatus updates a book's enrichment status
+func SetBookStatus(ctx context.Context, db *sql.DB, bookID int, status string) error {
+	_, err := db.ExecContext(ctx, `UPDATE books SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE book_id = $2`, status, bookID)
+	return err
+}
+
+// VerifyBookOwnership checks if a user owns a specific book
+func VerifyBookOwnership(ctx context.Context, db *sql.DB, bookID, userID int) (bool, error) {
+	var ownerID int
+	err := db.QueryRowContext(ctx, `SELECT user_id FROM books WHERE book_id = $1`, bookID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return false, ErrBookNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return ownerID == userID, nil
+}
+
+// getBookCandidates retrieves the candidate matches for a book
+func getBookCandidates(ctx context.Context, db *sql.DB, bookID int) ([]models.BookCandidate, error) {
+	query := `
+		SELECT candidate_id, book_id, title, author, isbn, cover_url, publish_year, page_count, source_id
+		FROM book_candidates
+		WHERE book_id = $1
+		ORDER BY candidate_id
+	`
+
+	rows, err := db.QueryContext(ctx, query, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]models.BookCandidate, 0)
+	for rows.Next() {
+		var (
+			c     models.BookCandidate
+			year  sql.NullInt64
+			pages sql.NullInt64
+		)
+		if err := rows.Scan(&c.CandidateID, &c.BookID, &c.Title, &c.Author, &c.ISBN, &c.CoverURL, &year, &pages, &c.SourceID); err != nil {
+			return nil, err
+		}
+		c.PublishYear = nullIntPtr(year)
+		c.PageCount = nullIntPtr(pages)
+		candidates = append(candidates, c)
+	}
+
+	return candidates, rows.Err()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBook(row rowScanner) (*models.Book, error) {
+	var (
+		book  models.Book
+		year  sql.NullInt64
+		pages sql.NullInt64
+	)
+
+	err := row.Scan(&book.BookID, &book.UserID, &book.Title, &book.Author, &book.ISBN, &book.CoverURL,
+		&year, &pages, &book.Status, &book.CreatedAt, &book.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	book.PublishYear = nullIntPtr(year)
+	book.PageCount = nullIntPtr(pages)
+	return &book, nil
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+// EnsureBook records a book title for a user if it hasn't been seen before
+func (s *PostgresStore) EnsureBook(ctx context.Context, userID int, title, author string) (int, bool, error) {
+	return EnsureBook(ctx, s.db, userID, title, author)
+}
+
+// GetBookByID retrieves a book along with its candidate matches
+func (s *PostgresStore) GetBookByID(ctx context.Context, bookID int) (*models.Book, error) {
+	return GetBookByID(ctx, s.db, bookID)
+}
+
+// GetPendingBookIDs returns books still waiting for a catalog lookup
+func (s *PostgresStore) GetPendingBookIDs(ctx context.Context) ([]int, error) {
+	return GetPendingBookIDs(ctx, s.db)
+}
+
+// ReplaceBookCandidates swaps a book's candidate matches and updates its status
+func (s *PostgresStore) ReplaceBookCandidates(ctx context.Context, bookID int, candidates []models.BookCandidate, status string) error {
+	return ReplaceBookCandidates(ctx, s.db, bookID, candidates, status)
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// BulkUpdateQuotes applies op to every quote in op.QuoteIDs that userID owns,
+// in a single transaction. Quotes that do not exist, are in the wrong trash
+// state for the action, or belong to someone else are reported in the results
+// and left untouched. Moving to a collection the user does not own returns
+// ErrCollectionNotFound and changes nothing.
+func (s *PostgresStore) BulkUpdateQuotes(ctx context.Context, userID int, op models.BulkQuoteOperation) ([]models.BulkQuoteResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if op.Action == models.BulkMoveToCollection {
+		if err := lockCollectionTx(ctx, tx, op.CollectionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Lock and check ownership of every requested quote in one query
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+quoteColumns+`
+		FROM quotes q
+		WHERE q.quote_id = ANY($1)
+		FOR UPDATE
+	`, pq.Array(op.QuoteIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]models.Quote, len(op.QuoteIDs))
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found[q.QuoteID] = q
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results, allowed := classifyBulkQuotes(op, userID, found)
+	if len(allowed) == 0 {
+		return results, nil
+	}
+
+	ids := make([]int, 0, len(allowed))
+	for _, q := range allowed {
+		ids = append(ids, q.QuoteID)
+	}
+
+	switch op.Action {
+	case models.BulkAddTags, models.BulkRemoveTags:
+		for _, q := range allowed {
+			next := q
+			next.Tags = models.ApplyBulkTags(op.Action, q.Tags, op.Tags)
+			if err := updateQuoteTx(ctx, tx, q.QuoteID, next); err != nil {
+				return nil, err
+			}
+		}
+
+	case models.BulkSetVisibility:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE quotes SET visibility = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE quote_id = ANY($2)
+		`, op.Visibility, pq.Array(ids))
+
+	case models.BulkMoveToCollection:
+		err = addQuotesToCollectionTx(ctx, tx, op.CollectionID, ids)
+
+	case models.BulkDelete:
+		_, err = tx.ExecContext(ctx, `UPDATE quotes SET deleted_at = CURRENT_TIMESTAMP WHERE quote_id = ANY($1)`, pq.Array(ids))
+
+	case models.BulkRestore:
+		_, err = tx.ExecContext(ctx, `UPDATE quotes SET deleted_at = NULL WHERE quote_id = ANY($1)`, pq.Array(ids))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// classifyBulkQuotes builds a result for each requested ID, in request order,
+// and returns the quotes the operation may change. Other users' quotes are
+// reported as not found so callers can't probe which IDs exist.
+func classifyBulkQuotes(op models.BulkQuoteOperation, userID int, found map[int]models.Quote) ([]models.BulkQuoteResult, []models.Quote) {
+	results := make([]models.BulkQuoteResult, 0, len(op.QuoteIDs))
+	allowed := make([]models.Quote, 0, len(op.QuoteIDs))
+
+	for _, id := range op.QuoteIDs {
+		q, ok := found[id]
+		status := models.BulkResultOK
+		switch {
+		case !ok || q.UserID != userID || (q.DeletedAt != nil) != (op.Action == models.BulkRestore):
+			status = models.BulkResultNotFound
+		default:
+			allowed = append(allowed, q)
+		}
+		results = append(results, models.BulkQuoteResult{QuoteID: id, Status: status})
+	}
+
+	return results, allowed
+}
+
+// lockCollectionTx locks one of the user's collections, returning
+// ErrCollectionNotFound when the user has no such collection
+func lockCollectionTx(ctx context.Context, tx *sql.Tx, collectionID, userID int) error {
+	var ownerID int
+	err := tx.QueryRowContext(ctx, `SELECT user_id FROM collections WHERE collection_id = $1 FOR UPDATE`, collectionID).Scan(&ownerID)
+	if err == sql.ErrNoRows || (err == nil && ownerID != userID) {
+		return ErrCollectionNotFound
+	}
+	return err
+}
+
+// addQuotesToCollectionTx appends quotes to the end of a collection locked
+// by lockCollectionTx, in the given order, skipping quotes already in it
+func addQuotesToCollectionTx(ctx context.Context, tx *sql.Tx, collectionID int, quoteIDs []int) error {
+	query := `
+		INSERT INTO collection_quotes (collection_id, quote_id, position)
+		SELECT $1, ids.quote_id,
+		       (SELECT COALESCE(MAX(position), 0) FROM collection_quotes WHERE collection_id = $1) + ids.ord
+		FROM unnest($2::int[]) WITH ORDINALITY AS ids(quote_id, ord)
+		ON CONFLICT (collection_id, quote_id) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, collectionID, pq.Array(quoteIDs)); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE collections SET updated_at = CURRENT_TIMESTAMP WHERE collection_id = $1`, collectionID)
+	return err
+}
@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// collectionColumns selects the fields read by scanCollection from a table aliased as c
+const collectionColumns = `c.collection_id, c.user_id, c.name, c.description, c.visibility, c.created_at, c.updated_at,
+		       (SELECT COUNT(*) FROM collection_quotes cq
+		        JOIN quotes q ON q.quote_id = cq.quote_id
+		        WHERE cq.collection_id = c.collection_id AND q.deleted_at IS NULL) as quote_count`
+
+func scanCollection(row rowScanner) (*models.Collection, error) {
+	var col models.Collection
+	err := row.Scan(&col.CollectionID, &col.UserID, &col.Name, &col.Description, &col.Visibility,
+		&col.CreatedAt, &col.UpdatedAt, &col.QuoteCount)
+	if err != nil {
+		return nil, err
+	}
+	return &col, nil
+}
+
+// mapCollectionError converts unique violations into ErrCollectionExists
+func mapCollectionError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" && pqErr.Constraint == "collections_user_name_key" {
+		return ErrCollectionExists
+	}
+	return err
+}
+
+// CreateCollection inserts a new collection
+func CreateCollection(ctx context.Context, db *sql.DB, col *models.Collection) error {
+	query := `
+		INSERT INTO collections (user_id, name, description, visibility)
+		VALUES ($1, $2, $3, $4)
+		RETURNING collection_id, created_at, updated_at
+	`
+
+	err := db.QueryRowContext(ctx, query, col.UserID, col.Name, col.Description, col.Visibility).
+		Scan(&col.CollectionID, &col.CreatedAt, &col.UpdatedAt)
+	if err != nil {
+		return mapCollectionError(err)
+	}
+
+	return nil
+}
+
+// GetCollectionByID retrieves a collection without its quotes
+func GetCollectionByID(ctx context.Context, db *sql.DB, collectionID int) (*models.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections c
+		WHERE c.collection_id = $1
+	`
+
+	col, err := scanCollection(db.QueryRowContext(ctx, query, collectionID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return col, nil
+}
+
+// GetCollectionsByUserID retrieves all collections owned by a user
+func GetCollectionsByUserID(ctx context.Context, db *sql.DB, userID int) ([]models.Collection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM collections c
+		WHERE c.user_id = $1
+		ORDER BY c.name
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := make([]models.Collection, 0)
+	for rows.Next() {
+		col, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, *col)
+	}
+
+	return collections, rows.Err()
+}
+
+// UpdateCollection updates a collection's name, description and visibility
+func UpdateCollection(ctx context.Context, db *sql.DB, collectionID int, name, description, visibility string) error {
+	query := `
+		UPDATE collections
+		SET name = $1, description = $2, visibility = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE collection_id = $4
+	`
+
+	result, err := db.ExecContext(ctx, query, name, description, visibility, collectionID)
+	if err != nil {
+		return mapCollectionError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+// DeleteCollection removes a collection. Its quotes are left untouched.
+func DeleteCollection(ctx context.Context, db *sql.DB, collectionID int) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM collections WHERE collection_id = $1`, collectionID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+// VerifyCollectionOwnership checks if a user owns a specific collection
+func VerifyCollectionOwnership(ctx context.Context, db *sql.DB, collectionID, userID int) (bool, error) {
+	var ownerID int
+	err := db.QueryRowContext(ctx, `SELECT user_id FROM collections WHERE collection_id = $1`, collectionID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return false, ErrCollectionNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return ownerID == userID, nil
+}
+
+// AddQuoteToCollection appends a quote to the end of a collection.
+// Adding a quote that is already in the collection is a no-op.
+func AddQuoteToCollection(ctx context.Context, db *sql.DB, collectionID, quoteID int) error {
+	query := `
+		INSERT INTO collection_quotes (collection_id, quote_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1
+		FROM collection_quotes
+		WHERE collection_id = $1
+		ON CONFLICT (collection_id, quote_id) DO NOTHING
+	`
+
+	if _, err := db.ExecContext(ctx, query, collectionID, quoteID); err != nil {
+		return err
+	}
+
+	_, err := db.ExecContext(ctx, `UPDATE collections SET updated_at = CURRENT_TIMESTAMP WHERE collection_id = $1`, collectionID)
+	return err
+}
+
+// RemoveQuoteFromCollection removes a quote from a collection
+func RemoveQuoteFromCollection(ctx context.Context, db *sql.DB, collectionID, quoteID int) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM collection_quotes WHERE collection_id = $1 AND quote_id = $2`, collectionID, quoteID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrQuoteNotFound
+	}
+
+	return nil
+}
+
+// CollectionContainsQuote checks if a quote belongs to a collection
+func CollectionContainsQuote(ctx context.Context, db *sql.DB, collectionID, quoteID int) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM collection_quotes cq
+			JOIN quotes q ON q.quote_id = cq.quote_id
+			WHERE cq.collection_id = $1 AND cq.quote_id = $2 AND q.deleted_at IS NULL
+		)`,
+		collectionID, quoteID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// ReorderCollectionQuotes sets the manual order of a collection.
+// quoteIDs must contain every quote in the collection that is not in the
+// trash exactly once; trashed quotes keep their position.
+func ReorderCollectionQuotes(ctx context.Context, db *sql.DB, collectionID int, quoteIDs []int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT cq.quote_id FROM collection_quotes cq
+		JOIN quotes q ON q.quote_id = cq.quote_id
+		WHERE cq.collection_id = $1 AND q.deleted_at IS NULL
+		FOR UPDATE OF cq
+	`, collectionID)
+	if err != nil {
+		return err
+	}
+	current := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		current[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(quoteIDs) != len(current) {
+		return ErrInvalidOrder
+	}
+	seen := make(map[int]bool, len(quoteIDs))
+	for _, id := range quoteIDs {
+		if !current[id] || seen[id] {
+			return ErrInvalidOrder
+		}
+		seen[id] = true
+	}
+
+	for i, id := range quoteIDs {
+		_, err := tx.ExecContext(ctx, `UPDATE collection_quotes SET position = $1 WHERE collection_id = $2 AND quote_id = $3`, i+1, collectionID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE collections SET updated_at = CURRENT_TIMESTAMP WHERE collection_id = $1`, collectionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FetchCollectionQuotes retrieves a collection's quotes in their manual order
+func FetchCollectionQuotes(ctx context.Context, db *sql.DB, collectionID int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM collection_quotes cq
+		JOIN quotes q ON q.quote_id = cq.quote_id
+		WHERE cq.collection_id = $1 AND q.deleted_at IS NULL
+		ORDER BY cq.position
+	`
+
+	rows, err := db.QueryContext(ctx, query, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotes := make(models.Quotes, 0)
+	for rows.Next() {
+		q, err := scanQuote(rows)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q)
+	}
+
+	return quotes, rows.Err()
+}
@@ -1,16 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
-	"runtime"
-	"strconv"
+	"log/slog"
+	"time"
 
-	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/config"
 )
 
 // DBConnection holds the SQL connection for convenience
@@ -18,121 +17,56 @@ type DBConnection struct {
 	DB *sql.DB
 }
 
-// StartDatabase connects to PostgreSQL using environment variables
-func StartDatabase() (*DBConnection, error) {
-	_, filename, _, _ := runtime.Caller(0) // Gets database.go location
-	dir := filepath.Dir(filename)          // /path/to/server/database
-	parentDir := filepath.Dir(dir)         // /path/to/server (parent)
-	envPath := filepath.Join(parentDir, ".env")
-	if err := godotenv.Load(envPath); err != nil {
-		fmt.Printf(".env not found at %s, using system env vars\n", envPath)
-	}
-	host := os.Getenv("DB_HOSTNAME")
-	user := os.Getenv("DB_USERNAME")
-	password := os.Getenv("DB_PASSWORD")
-	portStr := os.Getenv("DB_PORT")
-	dbname := os.Getenv("DB_NAME")
-
-	port, err := strconv.Atoi(portStr)
+// StartDatabase opens the connection pool and waits for PostgreSQL to accept
+// connections, retrying with exponential backoff for up to cfg.ConnectTimeout.
+// Authentication failures are not retried.
+func StartDatabase(ctx context.Context, cfg config.Database) (*DBConnection, error) {
+	dsn, err := connectionString(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
+		return nil, err
 	}
 
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
-
-	db, err := sql.Open("postgres", psqlInfo)
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	fmt.Println("Connected to database successfully.")
-	return &DBConnection{DB: db}, nil
-}
-func FetchQuotesAsJson(db *sql.DB) string {
-	query := "SELECT * FROM quotes"
-	rows, err := db.Query(query)
-	if err != nil {
-		panic(err)
-	}
-	defer rows.Close()
+	backoff := 500 * time.Millisecond
+	deadline := time.Now().Add(cfg.ConnectTimeout)
 
-	columns, err := rows.Columns()
-	if err != nil {
-		panic(err)
-	}
-
-	count := len(columns)
-	tableData := make([]map[string]interface{}, 0)
-	values := make([]interface{}, count)
-	valuePtrs := make([]interface{}, count)
-
-	for i := range values {
-		valuePtrs[i] = &values[i]
-	}
-
-	for rows.Next() {
-		if err := rows.Scan(valuePtrs...); err != nil {
-			panic(err)
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err = db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			break
 		}
 
-		entry := make(map[string]interface{})
-		for i, col := range columns {
-			val := values[i]
-
-			// Handle SQL byte arrays, JSONB, and tags array conversion cleanly
-			switch v := val.(type) {
-			case []byte:
-				if col == "tags" {
-					entry[col] = ParsePostgresTags(v)
-				} else {
-					entry[col] = string(v)
-				}
-			default:
-				entry[col] = v
-			}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code.Class() == "28" { // invalid authorization
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			db.Close()
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
 		}
-		tableData = append(tableData, entry)
-	}
-
-	if err = rows.Err(); err != nil {
-		panic(err)
-	}
-
-	jsonData, err := json.MarshalIndent(tableData, "", "  ")
-	if err != nil {
-		panic(err)
-	}
-
-	return string(jsonData)
-}
-
-func AddQuoteToDatabase(db *sql.DB, jsonBytes []byte) error {
-	var q map[string]interface{}
-	if err := json.Unmarshal(jsonBytes, &q); err != nil {
-		return err
-	}
 
-	userID := int(q["user_id"].(float64))
-	quote := q["quote"].(string)
-	author := q["author"].(string)
-	book := q["book"].(string)
-	tagsRaw := q["tags"].([]interface{})
-	tags := make([]string, len(tagsRaw))
-	for i, t := range tagsRaw {
-		tags[i] = t.(string)
+		slog.Warn("database not ready, retrying", "attempt", attempt, "retry_in", backoff.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 10*time.Second)
 	}
 
-	tagsStr := FormatPostgresTags(tags)
-
-	_, err := db.Exec(`
-        INSERT INTO quotes (user_id, quote, author, book, tags)
-        VALUES ($1, $2, $3, $4, $5)
-    `, userID, quote, author, book, tagsStr)
-
-	return err
+	slog.Info("connected to database", "sslmode", cfg.SSLMode, "max_open_conns", cfg.MaxOpenConns)
+	return &DBConnection{DB: db}, nil
 }
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+const digestColumns = `d.user_id, d.frequency, d.selection, d.timezone, d.send_hour, d.weekday,
+		       d.unsubscribe_token, d.last_sent_at, d.next_send_at`
+
+// scanDigest reads a row selected with digestColumns
+func scanDigest(row rowScanner, extra ...interface{}) (models.DigestSubscription, error) {
+	var sub models.DigestSubscription
+	dest := []interface{}{&sub.UserID, &sub.Frequency, &sub.Selection, &sub.Timezone, &sub.SendHour, &sub.Weekday,
+		&sub.UnsubscribeToken, &sub.LastSentAt, &sub.NextSendAt}
+	err := row.Scan(append(dest, extra...)...)
+	return sub, err
+}
+
+// GetDigestSubscription retrieves a user's digest subscription
+func (s *PostgresStore) GetDigestSubscription(ctx context.Context, userID int) (*models.DigestSubscription, error) {
+	query := `SELECT ` + digestColumns + ` FROM digest_subscriptions d WHERE d.user_id = $1`
+
+	sub, err := scanDigest(s.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrDigestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// SaveDigestSubscription subscribes a user to the digest or updates their
+// schedule. An existing unsubscribe token is kept; new subscriptions get one,
+// which is set on sub.
+func (s *PostgresStore) SaveDigestSubscription(ctx context.Context, sub *models.DigestSubscription) error {
+	token, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO digest_subscriptions (user_id, frequency, selection, timezone, send_hour, weekday, unsubscribe_token, next_send_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			frequency = EXCLUDED.frequency,
+			selection = EXCLUDED.selection,
+			timezone = EXCLUDED.timezone,
+			send_hour = EXCLUDED.send_hour,
+			weekday = EXCLUDED.weekday,
+			next_send_at = EXCLUDED.next_send_at,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING unsubscribe_token
+	`
+
+	return s.db.QueryRowContext(ctx, query, sub.UserID, sub.Frequency, sub.Selection, sub.Timezone,
+		sub.SendHour, int(sub.Weekday), token, sub.NextSendAt.UTC(),
+	).Scan(&sub.UnsubscribeToken)
+}
+
+// DeleteDigestSubscription turns off a user's digest
+func (s *PostgresStore) DeleteDigestSubscription(ctx context.Context, userID int) error {
+	return s.execDigestDelete(ctx, `DELETE FROM digest_subscriptions WHERE user_id = $1`, userID)
+}
+
+// UnsubscribeDigest turns off the digest whose unsubscribe token matches
+func (s *PostgresStore) UnsubscribeDigest(ctx context.Context, token string) error {
+	return s.execDigestDelete(ctx, `DELETE FROM digest_subscriptions WHERE unsubscribe_token = $1`, token)
+}
+
+// execDigestDelete removes a subscription, returning ErrDigestNotFound when
+// there was nothing to remove
+func (s *PostgresStore) execDigestDelete(ctx context.Context, query string, arg interface{}) error {
+	result, err := s.db.ExecContext(ctx, query, arg)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrDigestNotFound
+	}
+
+	return nil
+}
+
+// ClaimDueDigests retrieves up to limit subscriptions of active users whose
+// next send time is not after now, earliest first, and pushes their next
+// send time back to leaseUntil so that other workers skip them while they
+// are being sent
+func (s *PostgresStore) ClaimDueDigests(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.DigestSubscription, error) {
+	query := `
+		UPDATE digest_subscriptions d
+		SET next_send_at = $2
+		FROM users u
+		WHERE u.id = d.user_id AND d.user_id IN (
+			SELECT pd.user_id
+			FROM digest_subscriptions pd
+			JOIN users pu ON pu.id = pd.user_id
+			WHERE pd.next_send_at <= $1 AND pu.is_active = true
+			ORDER BY pd.next_send_at
+			LIMIT $3
+			FOR UPDATE OF pd SKIP LOCKED
+		)
+		RETURNING ` + digestColumns + `, u.username, u.email
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, now.UTC(), leaseUntil.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := make([]models.DigestSubscription, 0)
+	for rows.Next() {
+		var username, email string
+		sub, err := scanDigest(rows, &username, &email)
+		if err != nil {
+			return nil, err
+		}
+		sub.Username, sub.Email = username, email
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// GetDigestQuotes picks up to limit of the user's quotes for a digest. Spaced
+// selection prefers quotes that have never been in a digest, then the ones
+// that appeared longest ago.
+func (s *PostgresStore) GetDigestQuotes(ctx context.Context, userID int, selection string, limit int) (models.Quotes, error) {
+	order := `random()`
+	if selection == models.DigestSpaced {
+		order = `q.last_digested_at NULLS FIRST, random()`
+	}
+
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE q.user_id = $1 AND q.deleted_at IS NULL
+		ORDER BY ` + order + `
+		LIMIT $2
+	`
+
+	return s.queryQuotes(ctx, query, userID, limit)
+}
+
+// GetOnThisDayQuotes retrieves the user's quotes saved on day's month and day
+// in earlier years, newest first. day's location is the user's time zone.
+func (s *PostgresStore) GetOnThisDayQuotes(ctx context.Context, userID int, day time.Time) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		CROSS JOIN LATERAL (SELECT (q.created_at AT TIME ZONE 'UTC') AT TIME ZONE $2 AS local_created) l
+		WHERE q.user_id = $1 AND q.deleted_at IS NULL
+		  AND EXTRACT(MONTH FROM l.local_created) = $3
+		  AND EXTRACT(DAY FROM l.local_created) = $4
+		  AND EXTRACT(YEAR FROM l.local_created) < $5
+		ORDER BY q.created_at DESC
+	`
+
+	return s.queryQuotes(ctx, query, userID, day.Location().String(), int(day.Month()), day.Day(), day.Year())
+}
+
+// MarkDigestSent records that a digest went out with quoteIDs and schedules the next one
+func (s *PostgresStore) MarkDigestSent(ctx context.Context, userID int, sentAt, nextSendAt time.Time, quoteIDs []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE digest_subscriptions
+		SET last_sent_at = $2, next_send_at = $3
+		WHERE user_id = $1
+	`, userID, sentAt.UTC(), nextSendAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	if len(quoteIDs) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE quotes SET last_digested_at = $3
+			WHERE user_id = $1 AND quote_id = ANY($2)
+		`, userID, pq.Array(quoteIDs), sentAt.UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RescheduleDigest moves a digest that was not sent to its next send time
+func (s *PostgresStore) RescheduleDigest(ctx context.Context, userID int, nextSendAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE digest_subscriptions SET next_send_at = $2 WHERE user_id = $1`, userID, nextSendAt.UTC())
+	return err
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zach-monroe/zetl/server/config"
+)
+
+// connectionString builds the lib/pq DSN. A configured URL is used as given,
+// with TLS settings added only where the URL doesn't already specify them.
+func connectionString(cfg config.Database) (string, error) {
+	tls := []struct{ key, value string }{
+		{"sslmode", cfg.SSLMode},
+		{"sslrootcert", cfg.SSLRootCert},
+		{"sslcert", cfg.SSLCert},
+		{"sslkey", cfg.SSLKey},
+	}
+
+	if cfg.URL == "" {
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		tls[0].value = sslMode
+
+		parts := []string{
+			"host=" + quoteDSNValue(cfg.Host),
+			fmt.Sprintf("port=%d", cfg.Port),
+			"user=" + quoteDSNValue(cfg.User),
+			"password=" + quoteDSNValue(cfg.Password),
+			"dbname=" + quoteDSNValue(cfg.Name),
+		}
+		for _, p := range tls {
+			if p.value != "" {
+				parts = append(parts, p.key+"="+quoteDSNValue(p.value))
+			}
+		}
+		return strings.Join(parts, " "), nil
+	}
+
+	if strings.HasPrefix(cfg.URL, "postgres://") || strings.HasPrefix(cfg.URL, "postgresql://") {
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid DATABASE_URL: %w", err)
+		}
+		q := u.Query()
+		for _, p := range tls {
+			if p.value != "" && !q.Has(p.key) {
+				q.Set(p.key, p.value)
+			}
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+
+	// Key/value DSN
+	dsn := cfg.URL
+	for _, p := range tls {
+		if p.value != "" && !strings.Contains(dsn, p.key+"=") {
+			dsn += " " + p.key + "=" + quoteDSNValue(p.value)
+		}
+	}
+	return dsn, nil
+}
+
+// quoteDSNValue quotes a key/value DSN value so spaces and quotes in
+// passwords don't break parsing
+func quoteDSNValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
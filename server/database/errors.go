@@ -3,10 +3,21 @@ package database
 import "errors"
 
 var (
-	ErrQuoteNotFound     = errors.New("quote not found")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrTokenExpired      = errors.New("token expired")
-	ErrTokenInvalid      = errors.New("token invalid")
-	ErrUsernameExists    = errors.New("username already exists")
-	ErrEmailExists       = errors.New("email already exists")
+	ErrQuoteNotFound      = errors.New("quote not found")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrTokenExpired       = errors.New("token expired")
+	ErrTokenInvalid       = errors.New("token invalid")
+	ErrUsernameExists     = errors.New("username already exists")
+	ErrEmailExists        = errors.New("email already exists")
+	ErrBookNotFound       = errors.New("book not found")
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrCollectionExists   = errors.New("collection already exists")
+	ErrInvalidOrder       = errors.New("order must list every quote in the collection exactly once")
+	ErrShareLinkNotFound  = errors.New("share link not found")
+	ErrRevisionNotFound   = errors.New("revision not found")
+	ErrAlreadySaved       = errors.New("quote already saved")
+	ErrDigestNotFound     = errors.New("digest subscription not found")
+	ErrNoReviewDue        = errors.New("no quotes due for review")
+	ErrWebhookNotFound    = errors.New("webhook not found")
+	ErrDeliveryNotFound   = errors.New("webhook delivery not found")
 )
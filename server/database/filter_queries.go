@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// HideQuote hides a quote from the user's feed. Hiding it again is a no-op.
+func (s *PostgresStore) HideQuote(ctx context.Context, userID, quoteID int) error {
+	query := `
+		INSERT INTO hidden_quotes (user_id, quote_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, quote_id) DO NOTHING
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, quoteID)
+	return err
+}
+
+// UnhideQuote shows a hidden quote in the user's feed again
+func (s *PostgresStore) UnhideQuote(ctx context.Context, userID, quoteID int) error {
+	return s.execFilterDelete(ctx, `DELETE FROM hidden_quotes WHERE user_id = $1 AND quote_id = $2`, userID, quoteID, ErrQuoteNotFound)
+}
+
+// GetHiddenQuotes retrieves the quotes a user has hidden, most recently hidden
+// first. Quotes that have since been trashed or made private are left out.
+func (s *PostgresStore) GetHiddenQuotes(ctx context.Context, userID int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM hidden_quotes h
+		JOIN quotes q ON q.quote_id = h.quote_id
+		WHERE h.user_id = $1 AND q.visibility <> 'private' AND q.deleted_at IS NULL
+		ORDER BY h.hidden_at DESC
+	`
+
+	return s.queryQuotes(ctx, query, userID)
+}
+
+// MuteUser hides all of another user's quotes from the user's feed.
+// Muting them again is a no-op.
+func (s *PostgresStore) MuteUser(ctx context.Context, userID, mutedUserID int) error {
+	query := `
+		INSERT INTO muted_users (user_id, muted_user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, muted_user_id) DO NOTHING
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, mutedUserID)
+	return err
+}
+
+// UnmuteUser shows a muted user's quotes in the user's feed again
+func (s *PostgresStore) UnmuteUser(ctx context.Context, userID, mutedUserID int) error {
+	return s.execFilterDelete(ctx, `DELETE FROM muted_users WHERE user_id = $1 AND muted_user_id = $2`, userID, mutedUserID, ErrUserNotFound)
+}
+
+// GetMutedUsers retrieves the users a user has muted, most recently muted first
+func (s *PostgresStore) GetMutedUsers(ctx context.Context, userID int) ([]models.MutedUser, error) {
+	query := `
+		SELECT u.id, u.username, m.muted_at
+		FROM muted_users m
+		JOIN users u ON u.id = m.muted_user_id
+		WHERE m.user_id = $1
+		ORDER BY m.muted_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.MutedUser, 0)
+	for rows.Next() {
+		var u models.MutedUser
+		if err := rows.Scan(&u.ID, &u.Username, &u.MutedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// execFilterDelete removes one of a user's hidden quotes, muted users, likes
+// or follows, returning notFound when there was nothing to remove
+func (s *PostgresStore) execFilterDelete(ctx context.Context, query string, userID, targetID int, notFound error) error {
+	result, err := s.db.ExecContext(ctx, query, userID, targetID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+
+	return nil
+}
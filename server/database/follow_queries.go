@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// FollowUser adds another user's public quotes to the user's feed.
+// Following them again is a no-op.
+func (s *PostgresStore) FollowUser(ctx context.Context, userID, followedID int) error {
+	query := `
+		INSERT INTO follows (follower_id, followed_id)
+		VALUES ($1, $2)
+		ON CONFLICT (follower_id, followed_id) DO NOTHING
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, followedID)
+	return err
+}
+
+// UnfollowUser removes a followed user's quotes from the user's feed
+func (s *PostgresStore) UnfollowUser(ctx context.Context, userID, followedID int) error {
+	return s.execFilterDelete(ctx, `DELETE FROM follows WHERE follower_id = $1 AND followed_id = $2`, userID, followedID, ErrUserNotFound)
+}
+
+// GetFollowing retrieves the users a user follows, most recently followed first
+func (s *PostgresStore) GetFollowing(ctx context.Context, userID int) ([]models.FollowedUser, error) {
+	query := `
+		SELECT u.id, u.username, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = f.followed_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.FollowedUser, 0)
+	for rows.Next() {
+		var u models.FollowedUser
+		if err := rows.Scan(&u.ID, &u.Username, &u.FollowedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// GetFollowFeed retrieves up to limit public quotes from the users a user
+// follows, newest first, starting after cursor. Hidden quotes and muted
+// users are left out.
+func (s *PostgresStore) GetFollowFeed(ctx context.Context, userID int, cursor models.FeedCursor, limit int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		JOIN follows f ON f.followed_id = q.user_id AND f.follower_id = $1
+		WHERE q.visibility = 'public' AND q.deleted_at IS NULL
+		  AND ($2 = 0 OR (q.created_at, q.quote_id) < ($3, $2))
+		  AND NOT EXISTS (SELECT 1 FROM hidden_quotes h WHERE h.user_id = $1 AND h.quote_id = q.quote_id)
+		  AND NOT EXISTS (SELECT 1 FROM muted_users m WHERE m.user_id = $1 AND m.muted_user_id = q.user_id)
+		ORDER BY q.created_at DESC, q.quote_id DESC
+		LIMIT $4
+	`
+
+	return s.queryQuotes(ctx, query, userID, cursor.QuoteID, cursor.CreatedAt, limit)
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// LikeQuote records that the user likes a quote. Liking it again is a no-op.
+func (s *PostgresStore) LikeQuote(ctx context.Context, userID, quoteID int) error {
+	query := `
+		INSERT INTO quote_likes (user_id, quote_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, quote_id) DO NOTHING
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, quoteID)
+	return err
+}
+
+// UnlikeQuote removes the user's like from a quote
+func (s *PostgresStore) UnlikeQuote(ctx context.Context, userID, quoteID int) error {
+	return s.execFilterDelete(ctx, `DELETE FROM quote_likes WHERE user_id = $1 AND quote_id = $2`, userID, quoteID, ErrQuoteNotFound)
+}
+
+// GetLikedQuoteIDs reports which of quoteIDs the user has liked
+func (s *PostgresStore) GetLikedQuoteIDs(ctx context.Context, userID int, quoteIDs []int) (map[int]bool, error) {
+	liked := make(map[int]bool)
+	if len(quoteIDs) == 0 {
+		return liked, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT quote_id FROM quote_likes
+		WHERE user_id = $1 AND quote_id = ANY($2)
+	`, userID, pq.Array(quoteIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		liked[id] = true
+	}
+
+	return liked, rows.Err()
+}
+
+// SaveQuote copies another user's quote into the user's own quotes, keeping
+// attribution to the original quote and its owner. Notes stay with the
+// original. Only public quotes can be saved. Saving a quote the user already
+// has a live copy of returns ErrAlreadySaved.
+func (s *PostgresStore) SaveQuote(ctx context.Context, userID, quoteID int, visibility string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	original, err := scanQuote(tx.QueryRowContext(ctx, `
+		SELECT `+quoteColumns+`
+		FROM quotes q
+		WHERE q.quote_id = $1 AND q.visibility = 'public' AND q.deleted_at IS NULL
+		FOR UPDATE
+	`, quoteID))
+	if err == sql.ErrNoRows {
+		return 0, ErrQuoteNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM quotes
+			WHERE user_id = $1 AND saved_from_quote_id = $2 AND deleted_at IS NULL
+		)
+	`, userID, quoteID).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, ErrAlreadySaved
+	}
+
+	citation := original.Citation
+	var savedID int
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO quotes (user_id, quote, author, book, tags, notes, page, chapter, location, edition, source_url, date_read,
+		                    visibility, saved_from_quote_id, saved_from_user_id)
+		VALUES ($1, $2, $3, $4, $5, '', $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING quote_id
+	`, userID, original.Quote, original.Author, original.Book, pq.Array(original.Tags),
+		citation.Page, citation.Chapter, citation.Location, citation.Edition, citation.SourceURL, nullableDate(citation.DateRead),
+		visibility, original.QuoteID, original.UserID,
+	).Scan(&savedID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return savedID, nil
+}
+
+// GetQuoteSavers lists the users holding a live copy of a quote, most recent first
+func (s *PostgresStore) GetQuoteSavers(ctx context.Context, quoteID int) ([]models.QuoteSaver, error) {
+	query := `
+		SELECT u.id, u.username, q.quote_id, q.created_at
+		FROM quotes q
+		JOIN users u ON u.id = q.user_id
+		WHERE q.saved_from_quote_id = $1 AND q.deleted_at IS NULL
+		ORDER BY q.created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	savers := make([]models.QuoteSaver, 0)
+	for rows.Next() {
+		var saver models.QuoteSaver
+		if err := rows.Scan(&saver.UserID, &saver.Username, &saver.QuoteID, &saver.SavedAt); err != nil {
+			return nil, err
+		}
+		savers = append(savers, saver)
+	}
+
+	return savers, rows.Err()
+}
@@ -0,0 +1,1333 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// MemoryStore implements every store interface except the collection queries in memory.
+// It is intended for tests and mirrors the Postgres store's error behaviour.
+type MemoryStore struct {
+	mu          sync.Mutex
+	quotes      map[int]models.Quote
+	revisions   map[int][]models.QuoteRevision
+	users       map[int]models.User
+	tokens      map[int]PasswordResetToken
+	hidden      map[int]map[int]time.Time // user ID -> quote ID -> hidden at
+	muted       map[int]map[int]time.Time // user ID -> muted user ID -> muted at
+	likes       map[int]map[int]time.Time // quote ID -> user ID -> liked at
+	follows     map[int]map[int]time.Time // user ID -> followed user ID -> followed at
+	digests     map[int]models.DigestSubscription
+	digestedAt  map[int]time.Time                  // quote ID -> last included in a digest
+	reviews     map[int]map[int]models.QuoteReview // user ID -> quote ID -> review state
+	webhooks    map[int]models.Webhook
+	deliveries  map[int]models.WebhookDelivery
+	books       map[int]models.Book
+	nextQuoteID int
+	nextUserID  int
+	nextTokenID int
+
+	nextWebhookID   int
+	nextDeliveryID  int
+	nextBookID      int
+	nextCandidateID int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		quotes:     make(map[int]models.Quote),
+		revisions:  make(map[int][]models.QuoteRevision),
+		users:      make(map[int]models.User),
+		tokens:     make(map[int]PasswordResetToken),
+		hidden:     make(map[int]map[int]time.Time),
+		muted:      make(map[int]map[int]time.Time),
+		likes:      make(map[int]map[int]time.Time),
+		follows:    make(map[int]map[int]time.Time),
+		digests:    make(map[int]models.DigestSubscription),
+		digestedAt: make(map[int]time.Time),
+		reviews:    make(map[int]map[int]models.QuoteReview),
+		webhooks:   make(map[int]models.Webhook),
+		deliveries: make(map[int]models.WebhookDelivery),
+		books:      make(map[int]models.Book),
+	}
+}
+
+var (
+	_ QuoteStore       = (*MemoryStore)(nil)
+	_ BookStore        = (*MemoryStore)(nil)
+	_ UserStore        = (*MemoryStore)(nil)
+	_ TokenStore       = (*MemoryStore)(nil)
+	_ FeedFilterStore  = (*MemoryStore)(nil)
+	_ InteractionStore = (*MemoryStore)(nil)
+	_ FollowStore      = (*MemoryStore)(nil)
+	_ DigestStore      = (*MemoryStore)(nil)
+	_ ReviewStore      = (*MemoryStore)(nil)
+	_ WebhookStore     = (*MemoryStore)(nil)
+)
+
+// CreateQuote stores a new quote and returns its ID
+func (s *MemoryStore) CreateQuote(ctx context.Context, userID int, quote, author, book string, tags []string, notes string, citation models.Citation, visibility string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextQuoteID++
+	now := time.Now()
+	s.quotes[s.nextQuoteID] = models.Quote{
+		QuoteID:    s.nextQuoteID,
+		UserID:     userID,
+		Quote:      quote,
+		Author:     author,
+		Book:       book,
+		Tags:       append([]string{}, tags...),
+		Notes:      notes,
+		Citation:   citation,
+		Visibility: visibility,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	return s.nextQuoteID, nil
+}
+
+// GetQuoteByID retrieves a single quote by its ID
+func (s *MemoryStore) GetQuoteByID(ctx context.Context, quoteID int) (*models.Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.quotes[quoteID]
+	if !ok || q.DeletedAt != nil {
+		return nil, ErrQuoteNotFound
+	}
+	q.LikeCount = len(s.likes[quoteID])
+	return &q, nil
+}
+
+// GetQuotesByUserID retrieves all quotes for a user, newest first
+func (s *MemoryStore) GetQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error) {
+	return s.filterQuotes(func(q models.Quote) bool { return q.UserID == userID && q.DeletedAt == nil }), nil
+}
+
+// GetPublicQuotesByUserID retrieves a user's public quotes, newest first
+func (s *MemoryStore) GetPublicQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error) {
+	return s.filterQuotes(func(q models.Quote) bool {
+		return q.UserID == userID && q.Visibility == models.VisibilityPublic && q.DeletedAt == nil
+	}), nil
+}
+
+// GetPublicQuotesByTag retrieves up to limit public quotes with tag, newest first
+func (s *MemoryStore) GetPublicQuotesByTag(ctx context.Context, tag string, limit int) (models.Quotes, error) {
+	// With no viewer, the filter only passes public quotes
+	quotes := s.filterQuotes(models.QuoteFilter{Tag: tag}.Matches)
+	if len(quotes) > limit {
+		quotes = quotes[:limit]
+	}
+	return quotes, nil
+}
+
+// GetFeedQuotes retrieves every public quote plus all of the viewer's own
+// quotes, newest first, leaving out hidden quotes and muted users
+func (s *MemoryStore) GetFeedQuotes(ctx context.Context, viewerID int) (models.Quotes, error) {
+	return s.filterQuotes(func(q models.Quote) bool {
+		_, hidden := s.hidden[viewerID][q.QuoteID]
+		_, muted := s.muted[viewerID][q.UserID]
+		return (q.Visibility == models.VisibilityPublic || q.UserID == viewerID) && q.DeletedAt == nil && !hidden && !muted
+	}), nil
+}
+
+// filterQuotes returns the quotes that satisfy match, newest first
+func (s *MemoryStore) filterQuotes(match func(models.Quote) bool) models.Quotes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quotes := make(models.Quotes, 0)
+	for _, q := range s.quotes {
+		if match(q) {
+			q.LikeCount = len(s.likes[q.QuoteID])
+			quotes = append(quotes, q)
+		}
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].QuoteID > quotes[j].QuoteID })
+	return quotes
+}
+
+// UpdateQuote replaces a quote's content, recording the previous content as a revision.
+// An empty visibility leaves it unchanged.
+func (s *MemoryStore) UpdateQuote(ctx context.Context, quoteID int, quote, author, book string, tags []string, notes string, citation models.Citation, visibility string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := models.Quote{Quote: quote, Author: author, Book: book, Tags: tags, Notes: notes, Citation: citation, Visibility: visibility}
+	return s.updateQuote(quoteID, next)
+}
+
+// updateQuote applies next to a stored quote; s.mu must be held
+func (s *MemoryStore) updateQuote(quoteID int, next models.Quote) error {
+	q, ok := s.quotes[quoteID]
+	if !ok || q.DeletedAt != nil {
+		return ErrQuoteNotFound
+	}
+
+	now := time.Now()
+	if len(models.DiffQuotes(q, next)) > 0 {
+		s.revisions[quoteID] = append(s.revisions[quoteID], models.QuoteRevision{
+			QuoteID:   quoteID,
+			Revision:  len(s.revisions[quoteID]) + 1,
+			Quote:     q.Quote,
+			Author:    q.Author,
+			Book:      q.Book,
+			Tags:      q.Tags,
+			Notes:     q.Notes,
+			Citation:  q.Citation,
+			CreatedAt: now,
+		})
+	}
+
+	q.Quote, q.Author, q.Book, q.Notes, q.Citation = next.Quote, next.Author, next.Book, next.Notes, next.Citation
+	q.Tags = append([]string{}, next.Tags...)
+	if next.Visibility != "" {
+		q.Visibility = next.Visibility
+	}
+	q.UpdatedAt = now
+	s.quotes[quoteID] = q
+	return nil
+}
+
+// GetQuoteRevisions retrieves a quote's revisions, newest first
+func (s *MemoryStore) GetQuoteRevisions(ctx context.Context, quoteID int) ([]models.QuoteRevision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.revisions[quoteID]
+	revisions := make([]models.QuoteRevision, len(stored))
+	for i, r := range stored {
+		revisions[len(stored)-1-i] = r
+	}
+	return revisions, nil
+}
+
+// RestoreQuoteRevision replaces a quote's content with an earlier revision
+func (s *MemoryStore) RestoreQuoteRevision(ctx context.Context, quoteID, revision int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.revisions[quoteID]
+	if revision < 1 || revision > len(stored) {
+		return ErrRevisionNotFound
+	}
+	return s.updateQuote(quoteID, stored[revision-1].AsQuote())
+}
+
+// DeleteQuote moves a quote to the trash
+func (s *MemoryStore) DeleteQuote(ctx context.Context, quoteID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.quotes[quoteID]
+	if !ok || q.DeletedAt != nil {
+		return ErrQuoteNotFound
+	}
+	now := time.Now()
+	q.DeletedAt = &now
+	s.quotes[quoteID] = q
+	return nil
+}
+
+// VerifyQuoteOwnership checks if a user owns a specific quote
+func (s *MemoryStore) VerifyQuoteOwnership(ctx context.Context, quoteID, userID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.quotes[quoteID]
+	if !ok || q.DeletedAt != nil {
+		return false, ErrQuoteNotFound
+	}
+	return q.UserID == userID, nil
+}
+
+// GetTrashedQuotesByUserID retrieves a user's deleted quotes, most recently deleted first
+func (s *MemoryStore) GetTrashedQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error) {
+	quotes := s.filterQuotes(func(q models.Quote) bool { return q.UserID == userID && q.DeletedAt != nil })
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].DeletedAt.After(*quotes[j].DeletedAt) })
+	return quotes, nil
+}
+
+// trashedQuote returns one of the user's quotes from the trash; s.mu must be held
+func (s *MemoryStore) trashedQuote(quoteID, userID int) (models.Quote, error) {
+	q, ok := s.quotes[quoteID]
+	if !ok || q.UserID != userID || q.DeletedAt == nil {
+		return q, ErrQuoteNotFound
+	}
+	return q, nil
+}
+
+// RestoreQuote moves one of the user's quotes out of the trash
+func (s *MemoryStore) RestoreQuote(ctx context.Context, quoteID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.trashedQuote(quoteID, userID)
+	if err != nil {
+		return err
+	}
+	q.DeletedAt = nil
+	s.quotes[quoteID] = q
+	return nil
+}
+
+// PurgeQuote permanently deletes one of the user's quotes from the trash
+func (s *MemoryStore) PurgeQuote(ctx context.Context, quoteID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.trashedQuote(quoteID, userID); err != nil {
+		return err
+	}
+	s.purge(quoteID)
+	return nil
+}
+
+// PurgeDeletedQuotes permanently deletes every quote trashed before cutoff
+func (s *MemoryStore) PurgeDeletedQuotes(ctx context.Context, cutoff time.Time) (models.Quotes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged models.Quotes
+	for id, q := range s.quotes {
+		if q.DeletedAt != nil && q.DeletedAt.Before(cutoff) {
+			s.purge(id)
+			purged = append(purged, q)
+		}
+	}
+	return purged, nil
+}
+
+// purge permanently deletes a quote along with its revisions, likes and reviews, and
+// detaches any saved copies from it; s.mu must be held
+func (s *MemoryStore) purge(quoteID int) {
+	delete(s.reviews[s.quotes[quoteID].UserID], quoteID)
+	delete(s.quotes, quoteID)
+	delete(s.revisions, quoteID)
+	delete(s.likes, quoteID)
+	for id, q := range s.quotes {
+		if q.SavedFrom != nil && q.SavedFrom.QuoteID != nil && *q.SavedFrom.QuoteID == quoteID {
+			savedFrom := *q.SavedFrom
+			savedFrom.QuoteID = nil
+			q.SavedFrom = &savedFrom
+			s.quotes[id] = q
+		}
+	}
+}
+
+// BulkUpdateQuotes applies op to every quote in op.QuoteIDs that userID owns.
+// The memory store has no collections, so moving quotes to a collection
+// always returns ErrCollectionNotFound.
+func (s *MemoryStore) BulkUpdateQuotes(ctx context.Context, userID int, op models.BulkQuoteOperation) ([]models.BulkQuoteResult, error) {
+	if op.Action == models.BulkMoveToCollection {
+		return nil, ErrCollectionNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := make(map[int]models.Quote, len(op.QuoteIDs))
+	for _, id := range op.QuoteIDs {
+		if q, ok := s.quotes[id]; ok {
+			found[id] = q
+		}
+	}
+
+	results, allowed := classifyBulkQuotes(op, userID, found)
+	now := time.Now()
+	for _, q := range allowed {
+		switch op.Action {
+		case models.BulkAddTags, models.BulkRemoveTags:
+			next := q
+			next.Tags = models.ApplyBulkTags(op.Action, q.Tags, op.Tags)
+			if err := s.updateQuote(q.QuoteID, next); err != nil {
+				return nil, err
+			}
+			continue
+		case models.BulkSetVisibility:
+			q.Visibility = op.Visibility
+			q.UpdatedAt = now
+		case models.BulkDelete:
+			deletedAt := now
+			q.DeletedAt = &deletedAt
+		case models.BulkRestore:
+			q.DeletedAt = nil
+		}
+		s.quotes[q.QuoteID] = q
+	}
+
+	return results, nil
+}
+
+// CountFilteredQuotes counts the quotes passing filter
+func (s *MemoryStore) CountFilteredQuotes(ctx context.Context, filter models.QuoteFilter) (int, error) {
+	return len(s.filteredQuotes(filter)), nil
+}
+
+// GetFilteredQuoteAt retrieves the quote at offset among those passing
+// filter, in quote ID order
+func (s *MemoryStore) GetFilteredQuoteAt(ctx context.Context, filter models.QuoteFilter, offset int) (*models.Quote, error) {
+	quotes := s.filteredQuotes(filter)
+	if offset < 0 || offset >= len(quotes) {
+		return nil, ErrQuoteNotFound
+	}
+	return &quotes[offset], nil
+}
+
+// GetDailyFilteredQuote retrieves the quote passing filter that ranks first
+// for key under QuoteRank
+func (s *MemoryStore) GetDailyFilteredQuote(ctx context.Context, filter models.QuoteFilter, key string) (*models.Quote, error) {
+	quotes := s.filteredQuotes(filter)
+	if len(quotes) == 0 {
+		return nil, ErrQuoteNotFound
+	}
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return QuoteRank(key, quotes[i].QuoteID) < QuoteRank(key, quotes[j].QuoteID)
+	})
+	return &quotes[0], nil
+}
+
+// filteredQuotes lists the quotes passing filter in quote ID order
+func (s *MemoryStore) filteredQuotes(filter models.QuoteFilter) models.Quotes {
+	quotes := s.filterQuotes(func(q models.Quote) bool {
+		_, hidden := s.hidden[filter.ViewerID][q.QuoteID]
+		_, muted := s.muted[filter.ViewerID][q.UserID]
+		return filter.Matches(q) && !hidden && !muted
+	})
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].QuoteID < quotes[j].QuoteID })
+	return quotes
+}
+
+// HideQuote hides a quote from the user's feed
+func (s *MemoryStore) HideQuote(ctx context.Context, userID, quoteID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addFilter(s.hidden, userID, quoteID)
+	return nil
+}
+
+// UnhideQuote shows a hidden quote in the user's feed again
+func (s *MemoryStore) UnhideQuote(ctx context.Context, userID, quoteID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return removeFilter(s.hidden, userID, quoteID, ErrQuoteNotFound)
+}
+
+// GetHiddenQuotes retrieves the quotes a user has hidden, most recently hidden first
+func (s *MemoryStore) GetHiddenQuotes(ctx context.Context, userID int) (models.Quotes, error) {
+	s.mu.Lock()
+	hidden := make(map[int]time.Time, len(s.hidden[userID]))
+	for id, at := range s.hidden[userID] {
+		hidden[id] = at
+	}
+	s.mu.Unlock()
+
+	quotes := s.filterQuotes(func(q models.Quote) bool {
+		_, ok := hidden[q.QuoteID]
+		return ok && q.IsLinkable() && q.DeletedAt == nil
+	})
+	sort.SliceStable(quotes, func(i, j int) bool { return hidden[quotes[i].QuoteID].After(hidden[quotes[j].QuoteID]) })
+	return quotes, nil
+}
+
+// MuteUser hides all of another user's quotes from the user's feed
+func (s *MemoryStore) MuteUser(ctx context.Context, userID, mutedUserID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addFilter(s.muted, userID, mutedUserID)
+	return nil
+}
+
+// UnmuteUser shows a muted user's quotes in the user's feed again
+func (s *MemoryStore) UnmuteUser(ctx context.Context, userID, mutedUserID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return removeFilter(s.muted, userID, mutedUserID, ErrUserNotFound)
+}
+
+// GetMutedUsers retrieves the users a user has muted, most recently muted first
+func (s *MemoryStore) GetMutedUsers(ctx context.Context, userID int) ([]models.MutedUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]models.MutedUser, 0)
+	for id, at := range s.muted[userID] {
+		if u, ok := s.users[id]; ok {
+			users = append(users, models.MutedUser{ID: id, Username: u.Username, MutedAt: at})
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].MutedAt.After(users[j].MutedAt) })
+	return users, nil
+}
+
+// addFilter records targetID for userID unless it is already there; s.mu must be held
+func addFilter(filters map[int]map[int]time.Time, userID, targetID int) {
+	if filters[userID] == nil {
+		filters[userID] = make(map[int]time.Time)
+	}
+	if _, ok := filters[userID][targetID]; !ok {
+		filters[userID][targetID] = time.Now()
+	}
+}
+
+// removeFilter deletes targetID for userID, returning notFound when it was
+// not there; s.mu must be held
+func removeFilter(filters map[int]map[int]time.Time, userID, targetID int, notFound error) error {
+	if _, ok := filters[userID][targetID]; !ok {
+		return notFound
+	}
+	delete(filters[userID], targetID)
+	return nil
+}
+
+// LikeQuote records that the user likes a quote
+func (s *MemoryStore) LikeQuote(ctx context.Context, userID, quoteID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addFilter(s.likes, quoteID, userID)
+	return nil
+}
+
+// UnlikeQuote removes the user's like from a quote
+func (s *MemoryStore) UnlikeQuote(ctx context.Context, userID, quoteID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return removeFilter(s.likes, quoteID, userID, ErrQuoteNotFound)
+}
+
+// GetLikedQuoteIDs reports which of quoteIDs the user has liked
+func (s *MemoryStore) GetLikedQuoteIDs(ctx context.Context, userID int, quoteIDs []int) (map[int]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	liked := make(map[int]bool)
+	for _, id := range quoteIDs {
+		if _, ok := s.likes[id][userID]; ok {
+			liked[id] = true
+		}
+	}
+	return liked, nil
+}
+
+// SaveQuote copies another user's quote into the user's own quotes, keeping
+// attribution to the original quote and its owner
+func (s *MemoryStore) SaveQuote(ctx context.Context, userID, quoteID int, visibility string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original, ok := s.quotes[quoteID]
+	if !ok || original.Visibility != models.VisibilityPublic || original.DeletedAt != nil {
+		return 0, ErrQuoteNotFound
+	}
+	for _, q := range s.quotes {
+		if q.UserID == userID && q.DeletedAt == nil && q.SavedFrom != nil &&
+			q.SavedFrom.QuoteID != nil && *q.SavedFrom.QuoteID == quoteID {
+			return 0, ErrAlreadySaved
+		}
+	}
+
+	originalID := original.QuoteID
+	s.nextQuoteID++
+	now := time.Now()
+	s.quotes[s.nextQuoteID] = models.Quote{
+		QuoteID:    s.nextQuoteID,
+		UserID:     userID,
+		Quote:      original.Quote,
+		Author:     original.Author,
+		Book:       original.Book,
+		Tags:       append([]string{}, original.Tags...),
+		Citation:   original.Citation,
+		Visibility: visibility,
+		SavedFrom: &models.SavedFrom{
+			QuoteID:  &originalID,
+			UserID:   original.UserID,
+			Username: s.users[original.UserID].Username,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return s.nextQuoteID, nil
+}
+
+// GetQuoteSavers lists the users holding a live copy of a quote, most recent first
+func (s *MemoryStore) GetQuoteSavers(ctx context.Context, quoteID int) ([]models.QuoteSaver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	savers := make([]models.QuoteSaver, 0)
+	for _, q := range s.quotes {
+		if q.DeletedAt == nil && q.SavedFrom != nil && q.SavedFrom.QuoteID != nil && *q.SavedFrom.QuoteID == quoteID {
+			savers = append(savers, models.QuoteSaver{
+				UserID:   q.UserID,
+				Username: s.users[q.UserID].Username,
+				QuoteID:  q.QuoteID,
+				SavedAt:  q.CreatedAt,
+			})
+		}
+	}
+	sort.Slice(savers, func(i, j int) bool { return savers[i].QuoteID > savers[j].QuoteID })
+	return savers, nil
+}
+
+// FollowUser adds another user's public quotes to the user's feed
+func (s *MemoryStore) FollowUser(ctx context.Context, userID, followedID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addFilter(s.follows, userID, followedID)
+	return nil
+}
+
+// UnfollowUser removes a followed user's quotes from the user's feed
+func (s *MemoryStore) UnfollowUser(ctx context.Context, userID, followedID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return removeFilter(s.follows, userID, followedID, ErrUserNotFound)
+}
+
+// GetFollowing retrieves the users a user follows, most recently followed first
+func (s *MemoryStore) GetFollowing(ctx context.Context, userID int) ([]models.FollowedUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]models.FollowedUser, 0)
+	for id, at := range s.follows[userID] {
+		if u, ok := s.users[id]; ok {
+			users = append(users, models.FollowedUser{ID: id, Username: u.Username, FollowedAt: at})
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].FollowedAt.After(users[j].FollowedAt) })
+	return users, nil
+}
+
+// GetFollowFeed retrieves up to limit public quotes from the users a user
+// follows, newest first, starting after cursor
+func (s *MemoryStore) GetFollowFeed(ctx context.Context, userID int, cursor models.FeedCursor, limit int) (models.Quotes, error) {
+	quotes := s.filterQuotes(func(q models.Quote) bool {
+		_, followed := s.follows[userID][q.UserID]
+		_, hidden := s.hidden[userID][q.QuoteID]
+		_, muted := s.muted[userID][q.UserID]
+		return followed && !hidden && !muted && q.Visibility == models.VisibilityPublic && q.DeletedAt == nil && cursor.Before(q)
+	})
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return !quotes[i].CreatedAt.Equal(quotes[j].CreatedAt) && quotes[i].CreatedAt.After(quotes[j].CreatedAt)
+	})
+	if len(quotes) > limit {
+		quotes = quotes[:limit]
+	}
+	return quotes, nil
+}
+
+// GetDigestSubscription retrieves a user's digest subscription
+func (s *MemoryStore) GetDigestSubscription(ctx context.Context, userID int) (*models.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.digests[userID]
+	if !ok {
+		return nil, ErrDigestNotFound
+	}
+	return &sub, nil
+}
+
+// SaveDigestSubscription subscribes a user to the digest or updates their
+// schedule, keeping any existing unsubscribe token
+func (s *MemoryStore) SaveDigestSubscription(ctx context.Context, sub *models.DigestSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.digests[sub.UserID]; ok {
+		sub.UnsubscribeToken = existing.UnsubscribeToken
+		sub.LastSentAt = existing.LastSentAt
+	} else {
+		token, err := GenerateToken()
+		if err != nil {
+			return err
+		}
+		sub.UnsubscribeToken = token
+	}
+	s.digests[sub.UserID] = *sub
+	return nil
+}
+
+// DeleteDigestSubscription turns off a user's digest
+func (s *MemoryStore) DeleteDigestSubscription(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.digests[userID]; !ok {
+		return ErrDigestNotFound
+	}
+	delete(s.digests, userID)
+	return nil
+}
+
+// UnsubscribeDigest turns off the digest whose unsubscribe token matches
+func (s *MemoryStore) UnsubscribeDigest(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, sub := range s.digests {
+		if sub.UnsubscribeToken == token {
+			delete(s.digests, userID)
+			return nil
+		}
+	}
+	return ErrDigestNotFound
+}
+
+// ClaimDueDigests retrieves up to limit subscriptions of active users whose
+// next send time is not after now, earliest first, and pushes their next
+// send time back to leaseUntil
+func (s *MemoryStore) ClaimDueDigests(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]models.DigestSubscription, 0)
+	for userID, sub := range s.digests {
+		u, ok := s.users[userID]
+		if !ok || !u.IsActive || sub.NextSendAt.After(now) {
+			continue
+		}
+		sub.Username, sub.Email = u.Username, u.Email
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].NextSendAt.Before(subs[j].NextSendAt) })
+	if len(subs) > limit {
+		subs = subs[:limit]
+	}
+
+	for i := range subs {
+		stored := s.digests[subs[i].UserID]
+		stored.NextSendAt = leaseUntil
+		s.digests[stored.UserID] = stored
+		subs[i].NextSendAt = leaseUntil
+	}
+	return subs, nil
+}
+
+// GetDigestQuotes picks up to limit of the user's quotes for a digest. The
+// memory store is deterministic: spaced selection takes the quotes digested
+// longest ago, oldest quote first, and random selection takes the newest.
+func (s *MemoryStore) GetDigestQuotes(ctx context.Context, userID int, selection string, limit int) (models.Quotes, error) {
+	s.mu.Lock()
+	digestedAt := make(map[int]time.Time, len(s.digestedAt))
+	for id, at := range s.digestedAt {
+		digestedAt[id] = at
+	}
+	s.mu.Unlock()
+
+	quotes := s.filterQuotes(func(q models.Quote) bool { return q.UserID == userID && q.DeletedAt == nil })
+	if selection == models.DigestSpaced {
+		sort.SliceStable(quotes, func(i, j int) bool {
+			a, b := digestedAt[quotes[i].QuoteID], digestedAt[quotes[j].QuoteID]
+			if a.Equal(b) {
+				return quotes[i].QuoteID < quotes[j].QuoteID
+			}
+			return a.Before(b)
+		})
+	}
+	if len(quotes) > limit {
+		quotes = quotes[:limit]
+	}
+	return quotes, nil
+}
+
+// GetOnThisDayQuotes retrieves the user's quotes saved on day's month and day
+// in earlier years, newest first
+func (s *MemoryStore) GetOnThisDayQuotes(ctx context.Context, userID int, day time.Time) (models.Quotes, error) {
+	return s.filterQuotes(func(q models.Quote) bool {
+		created := q.CreatedAt.In(day.Location())
+		return q.UserID == userID && q.DeletedAt == nil &&
+			created.Month() == day.Month() && created.Day() == day.Day() && created.Year() < day.Year()
+	}), nil
+}
+
+// MarkDigestSent records that a digest went out with quoteIDs and schedules the next one
+func (s *MemoryStore) MarkDigestSent(ctx context.Context, userID int, sentAt, nextSendAt time.Time, quoteIDs []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.digests[userID]; ok {
+		sub.LastSentAt = &sentAt
+		sub.NextSendAt = nextSendAt
+		s.digests[userID] = sub
+	}
+	for _, id := range quoteIDs {
+		if q, ok := s.quotes[id]; ok && q.UserID == userID {
+			s.digestedAt[id] = sentAt
+		}
+	}
+	return nil
+}
+
+// RescheduleDigest moves a digest that was not sent to its next send time
+func (s *MemoryStore) RescheduleDigest(ctx context.Context, userID int, nextSendAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.digests[userID]; ok {
+		sub.NextSendAt = nextSendAt
+		s.digests[userID] = sub
+	}
+	return nil
+}
+
+// GetNextReview retrieves the user's quote most in need of review at now:
+// the one overdue longest, or failing that their oldest unreviewed quote
+func (s *MemoryStore) GetNextReview(ctx context.Context, userID int, now time.Time, filter models.ReviewFilter) (*models.ReviewCard, error) {
+	cards := s.dueReviews(userID, now, filter)
+	if len(cards) == 0 {
+		return nil, ErrNoReviewDue
+	}
+	return &cards[0], nil
+}
+
+// CountDueReviews counts the user's quotes matching filter that are due for review at now
+func (s *MemoryStore) CountDueReviews(ctx context.Context, userID int, now time.Time, filter models.ReviewFilter) (int, error) {
+	return len(s.dueReviews(userID, now, filter)), nil
+}
+
+// dueReviews lists the user's due quotes in review order
+func (s *MemoryStore) dueReviews(userID int, now time.Time, filter models.ReviewFilter) []models.ReviewCard {
+	quotes := s.filterQuotes(func(q models.Quote) bool {
+		return q.UserID == userID && q.DeletedAt == nil && filter.Matches(q)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cards := make([]models.ReviewCard, 0, len(quotes))
+	for _, q := range quotes {
+		if r, ok := s.reviews[userID][q.QuoteID]; !ok {
+			cards = append(cards, models.ReviewCard{Quote: q})
+		} else if !r.DueAt.After(now) {
+			cards = append(cards, models.ReviewCard{Quote: q, Review: &r})
+		}
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := cards[i].Review, cards[j].Review
+		switch {
+		case a != nil && b != nil && !a.DueAt.Equal(b.DueAt):
+			return a.DueAt.Before(b.DueAt)
+		case (a == nil) != (b == nil):
+			return a != nil
+		}
+		return cards[i].Quote.QuoteID < cards[j].Quote.QuoteID
+	})
+	return cards
+}
+
+// GetQuoteReview retrieves the user's review state for a quote, or the
+// starting state if they have never reviewed it
+func (s *MemoryStore) GetQuoteReview(ctx context.Context, userID, quoteID int) (*models.QuoteReview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.reviews[userID][quoteID]
+	if !ok {
+		r = models.NewQuoteReview(quoteID)
+	}
+	return &r, nil
+}
+
+// SaveQuoteReview stores the user's review state for a quote
+func (s *MemoryStore) SaveQuoteReview(ctx context.Context, userID int, review *models.QuoteReview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reviews[userID] == nil {
+		s.reviews[userID] = make(map[int]models.QuoteReview)
+	}
+	s.reviews[userID][review.QuoteID] = *review
+	return nil
+}
+
+// CreateWebhook stores a new webhook, setting its ID and timestamps
+func (s *MemoryStore) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextWebhookID++
+	now := time.Now()
+	hook.WebhookID = s.nextWebhookID
+	hook.CreatedAt = now
+	hook.UpdatedAt = now
+	stored := *hook
+	stored.Events = append([]string{}, hook.Events...)
+	s.webhooks[hook.WebhookID] = stored
+	return nil
+}
+
+// GetWebhooksByUserID retrieves a user's webhooks, oldest first
+func (s *MemoryStore) GetWebhooksByUserID(ctx context.Context, userID int) ([]models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hooks := make([]models.Webhook, 0)
+	for _, hook := range s.webhooks {
+		if hook.UserID == userID {
+			hooks = append(hooks, hook)
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].WebhookID < hooks[j].WebhookID })
+	return hooks, nil
+}
+
+// GetWebhook retrieves one of a user's webhooks
+func (s *MemoryStore) GetWebhook(ctx context.Context, userID, webhookID int) (*models.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook, ok := s.webhooks[webhookID]
+	if !ok || hook.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+	return &hook, nil
+}
+
+// UpdateWebhook changes a webhook's URL, events and active flag. The secret
+// is never changed.
+func (s *MemoryStore) UpdateWebhook(ctx context.Context, hook *models.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.webhooks[hook.WebhookID]
+	if !ok || stored.UserID != hook.UserID {
+		return ErrWebhookNotFound
+	}
+	stored.URL = hook.URL
+	stored.Events = append([]string{}, hook.Events...)
+	stored.Active = hook.Active
+	stored.UpdatedAt = time.Now()
+	s.webhooks[hook.WebhookID] = stored
+	hook.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// DeleteWebhook removes one of a user's webhooks along with its deliveries
+func (s *MemoryStore) DeleteWebhook(ctx context.Context, userID, webhookID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook, ok := s.webhooks[webhookID]
+	if !ok || hook.UserID != userID {
+		return ErrWebhookNotFound
+	}
+	delete(s.webhooks, webhookID)
+	for id, d := range s.deliveries {
+		if d.WebhookID == webhookID {
+			delete(s.deliveries, id)
+		}
+	}
+	return nil
+}
+
+// EnqueueWebhookEvent queues a delivery of payload to each of the user's
+// active webhooks subscribed to event, due at now, and returns how many
+// were queued
+func (s *MemoryStore) EnqueueWebhookEvent(ctx context.Context, userID int, event string, payload []byte, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queued := 0
+	for _, hook := range s.webhooks {
+		if hook.UserID == userID && hook.Subscribes(event) {
+			s.addDelivery(hook.WebhookID, event, payload, now)
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// GetWebhookDeliveries retrieves up to limit of a webhook's deliveries, newest first
+func (s *MemoryStore) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for _, d := range s.deliveries {
+		if d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].DeliveryID > deliveries[j].DeliveryID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a new delivery of an earlier delivery's event and
+// payload, due at now
+func (s *MemoryStore) RedeliverWebhook(ctx context.Context, webhookID, deliveryID int, now time.Time) (*models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	original, ok := s.deliveries[deliveryID]
+	if !ok || original.WebhookID != webhookID {
+		return nil, ErrDeliveryNotFound
+	}
+	d := s.addDelivery(webhookID, original.Event, original.Payload, now)
+	return &d, nil
+}
+
+// ClaimWebhookDeliveries retrieves up to limit pending deliveries to active
+// webhooks that are due at now, earliest first, and pushes their next
+// attempt back to leaseUntil so that other workers skip them while they
+// are being sent
+func (s *MemoryStore) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := make([]models.WebhookDelivery, 0)
+	for _, d := range s.deliveries {
+		hook := s.webhooks[d.WebhookID]
+		if d.Status == models.DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) && hook.Active {
+			d.URL, d.Secret = hook.URL, hook.Secret
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		stored := s.deliveries[due[i].DeliveryID]
+		lease := leaseUntil
+		stored.NextAttemptAt = &lease
+		s.deliveries[stored.DeliveryID] = stored
+		due[i].NextAttemptAt = &lease
+	}
+	return due, nil
+}
+
+// SaveWebhookDeliveryAttempt records the outcome of sending a delivery
+func (s *MemoryStore) SaveWebhookDeliveryAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.deliveries[d.DeliveryID]
+	if !ok {
+		return ErrDeliveryNotFound
+	}
+	stored.Status = d.Status
+	stored.Attempts = d.Attempts
+	stored.NextAttemptAt = d.NextAttemptAt
+	stored.LastAttemptAt = d.LastAttemptAt
+	stored.ResponseStatus = d.ResponseStatus
+	stored.Error = d.Error
+	s.deliveries[d.DeliveryID] = stored
+	return nil
+}
+
+// addDelivery stores a new pending delivery due at now. Callers hold s.mu.
+func (s *MemoryStore) addDelivery(webhookID int, event string, payload []byte, now time.Time) models.WebhookDelivery {
+	s.nextDeliveryID++
+	d := models.WebhookDelivery{
+		DeliveryID:    s.nextDeliveryID,
+		WebhookID:     webhookID,
+		Event:         event,
+		Payload:       append([]byte{}, payload...),
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+	s.deliveries[d.DeliveryID] = d
+	return d
+}
+
+// EnsureBook records a book title for a user if it hasn't been seen before.
+// Returns the book ID and whether a new book was created.
+func (s *MemoryStore) EnsureBook(ctx context.Context, userID int, title, author string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.books {
+		if b.UserID == userID && b.Title == title {
+			return b.BookID, false, nil
+		}
+	}
+
+	s.nextBookID++
+	now := time.Now()
+	s.books[s.nextBookID] = models.Book{
+		BookID:    s.nextBookID,
+		UserID:    userID,
+		Title:     title,
+		Author:    author,
+		Status:    models.BookStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return s.nextBookID, true, nil
+}
+
+// GetBookByID retrieves a book along with its candidate matches
+func (s *MemoryStore) GetBookByID(ctx context.Context, bookID int) (*models.Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[bookID]
+	if !ok {
+		return nil, ErrBookNotFound
+	}
+	b.Candidates = append(make([]models.BookCandidate, 0, len(b.Candidates)), b.Candidates...)
+	return &b, nil
+}
+
+// GetPendingBookIDs returns books still waiting for a catalog lookup
+func (s *MemoryStore) GetPendingBookIDs(ctx context.Context) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []int
+	for id, b := range s.books {
+		if b.Status == models.BookStatusPending {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// ReplaceBookCandidates swaps a book's candidate matches and updates its status
+func (s *MemoryStore) ReplaceBookCandidates(ctx context.Context, bookID int, candidates []models.BookCandidate, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[bookID]
+	if !ok {
+		return ErrBookNotFound
+	}
+
+	b.Candidates = make([]models.BookCandidate, len(candidates))
+	for i, c := range candidates {
+		s.nextCandidateID++
+		c.CandidateID = s.nextCandidateID
+		c.BookID = bookID
+		b.Candidates[i] = c
+	}
+	b.Status = status
+	b.UpdatedAt = time.Now()
+	s.books[bookID] = b
+	return nil
+}
+
+// CreateUser stores a new user, enforcing unique usernames and emails
+func (s *MemoryStore) CreateUser(ctx context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkUnique(0, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	s.nextUserID++
+	now := time.Now()
+	user.ID = s.nextUserID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	stored := *user
+	stored.PrivacySettings = models.DefaultPrivacySettings()
+	s.users[user.ID] = stored
+	return nil
+}
+
+// checkUnique reports a conflict with any user other than exceptID
+func (s *MemoryStore) checkUnique(exceptID int, username, email string) error {
+	for id, u := range s.users {
+		if id == exceptID {
+			continue
+		}
+		if u.Username == username {
+			return ErrUsernameExists
+		}
+		if u.Email == email {
+			return ErrEmailExists
+		}
+	}
+	return nil
+}
+
+// getUserBy returns a copy of the first user that satisfies match
+func (s *MemoryStore) getUserBy(match func(models.User) bool) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if match(u) {
+			if u.PrivacySettings != nil {
+				settings := *u.PrivacySettings
+				u.PrivacySettings = &settings
+			}
+			return &u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetUserByUsername retrieves a user by username
+func (s *MemoryStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.getUserBy(func(u models.User) bool { return u.Username == username })
+}
+
+// GetUserByEmail retrieves a user by email
+func (s *MemoryStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.getUserBy(func(u models.User) bool { return u.Email == email })
+}
+
+// GetUserByID retrieves a user by ID
+func (s *MemoryStore) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	return s.getUserBy(func(u models.User) bool { return u.ID == id })
+}
+
+// updateUser applies fn to a stored user; missing users are ignored as in Postgres
+func (s *MemoryStore) updateUser(userID int, fn func(*models.User) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return nil
+	}
+	if err := fn(&u); err != nil {
+		return err
+	}
+	s.users[userID] = u
+	return nil
+}
+
+// UpdateLastLogin updates the last login timestamp for a user
+func (s *MemoryStore) UpdateLastLogin(ctx context.Context, userID int) error {
+	return s.updateUser(userID, func(u *models.User) error {
+		now := time.Now()
+		u.LastLogin = &now
+		return nil
+	})
+}
+
+// UpdateUserProfile updates a user's username, email, and bio
+func (s *MemoryStore) UpdateUserProfile(ctx context.Context, userID int, username, email, bio string) error {
+	return s.updateUser(userID, func(u *models.User) error {
+		if err := s.checkUnique(userID, username, email); err != nil {
+			return err
+		}
+		u.Username, u.Email, u.Bio = username, email, bio
+		u.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// UpdateUserPassword updates a user's password hash
+func (s *MemoryStore) UpdateUserPassword(ctx context.Context, userID int, passwordHash string) error {
+	return s.updateUser(userID, func(u *models.User) error {
+		u.PasswordHash = passwordHash
+		u.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// UpdateUserPrivacy updates a user's privacy settings
+func (s *MemoryStore) UpdateUserPrivacy(ctx context.Context, userID int, settings *models.PrivacySettings) error {
+	return s.updateUser(userID, func(u *models.User) error {
+		stored := *settings
+		u.PrivacySettings = &stored
+		u.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+// CreatePasswordResetToken creates a new password reset token for a user
+func (s *MemoryStore) CreatePasswordResetToken(ctx context.Context, userID int) (*PasswordResetToken, error) {
+	token, err := GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextTokenID++
+	now := time.Now()
+	prt := PasswordResetToken{
+		ID:        s.nextTokenID,
+		UserID:    userID,
+		Token:     token,
+		ExpiresAt: now.Add(config.PasswordResetExpiry),
+		CreatedAt: now,
+	}
+	s.tokens[prt.ID] = prt
+	return &prt, nil
+}
+
+// GetPasswordResetToken retrieves a valid, unused token
+func (s *MemoryStore) GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, prt := range s.tokens {
+		if prt.Token == token && !prt.Used && prt.ExpiresAt.After(now) {
+			return &prt, nil
+		}
+	}
+	return nil, ErrTokenInvalid
+}
+
+// MarkTokenAsUsed marks a token as used
+func (s *MemoryStore) MarkTokenAsUsed(ctx context.Context, tokenID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prt, ok := s.tokens[tokenID]; ok {
+		prt.Used = true
+		s.tokens[tokenID] = prt
+	}
+	return nil
+}
+
+// InvalidateUserTokens invalidates all pending tokens for a user
+func (s *MemoryStore) InvalidateUserTokens(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, prt := range s.tokens {
+		if prt.UserID == userID {
+			prt.Used = true
+			s.tokens[id] = prt
+		}
+	}
+	return nil
+}
+
+// CleanupExpiredTokens removes used tokens and those expired past the cleanup age
+func (s *MemoryStore) CleanupExpiredTokens(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-config.TokenCleanupAge)
+	for id, prt := range s.tokens {
+		if prt.Used || prt.ExpiresAt.Before(cutoff) {
+			delete(s.tokens, id)
+		}
+	}
+	return nil
+}
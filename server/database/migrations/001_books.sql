@@ -0,0 +1,30 @@
+-- Books referenced by quotes, enriched from an external catalog
+CREATE TABLE IF NOT EXISTS books (
+    book_id      SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title        TEXT NOT NULL,
+    author       TEXT NOT NULL DEFAULT '',
+    isbn         TEXT NOT NULL DEFAULT '',
+    cover_url    TEXT NOT NULL DEFAULT '',
+    publish_year INTEGER,
+    page_count   INTEGER,
+    status       TEXT NOT NULL DEFAULT 'pending',
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, title)
+);
+
+-- Candidate catalog matches awaiting user confirmation
+CREATE TABLE IF NOT EXISTS book_candidates (
+    candidate_id SERIAL PRIMARY KEY,
+    book_id      INTEGER NOT NULL REFERENCES books(book_id) ON DELETE CASCADE,
+    title        TEXT NOT NULL,
+    author       TEXT NOT NULL DEFAULT '',
+    isbn         TEXT NOT NULL DEFAULT '',
+    cover_url    TEXT NOT NULL DEFAULT '',
+    publish_year INTEGER,
+    page_count   INTEGER,
+    source_id    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_book_candidates_book_id ON book_candidates(book_id);
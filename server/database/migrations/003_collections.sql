@@ -0,0 +1,21 @@
+-- Named, ordered collections of quotes
+CREATE TABLE IF NOT EXISTS collections (
+    collection_id SERIAL PRIMARY KEY,
+    user_id       INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name          TEXT NOT NULL,
+    description   TEXT NOT NULL DEFAULT '',
+    visibility    TEXT NOT NULL DEFAULT 'inherit' CHECK (visibility IN ('inherit', 'public', 'private')),
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT collections_user_name_key UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS collection_quotes (
+    collection_id INTEGER NOT NULL REFERENCES collections(collection_id) ON DELETE CASCADE,
+    quote_id      INTEGER NOT NULL REFERENCES quotes(quote_id) ON DELETE CASCADE,
+    position      INTEGER NOT NULL,
+    added_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (collection_id, quote_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_quotes_quote_id ON collection_quotes(quote_id);
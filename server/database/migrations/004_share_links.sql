@@ -0,0 +1,14 @@
+-- Unguessable read-only links to a single quote or collection
+CREATE TABLE IF NOT EXISTS share_links (
+    share_id      SERIAL PRIMARY KEY,
+    token         TEXT NOT NULL UNIQUE,
+    user_id       INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quote_id      INTEGER REFERENCES quotes(quote_id) ON DELETE CASCADE,
+    collection_id INTEGER REFERENCES collections(collection_id) ON DELETE CASCADE,
+    expires_at    TIMESTAMP,
+    revoked_at    TIMESTAMP,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((quote_id IS NULL) <> (collection_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_user_id ON share_links(user_id);
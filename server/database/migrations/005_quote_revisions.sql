@@ -0,0 +1,20 @@
+-- Prior content of edited quotes; a row is written in the same transaction
+-- as each update that changes the quote
+CREATE TABLE IF NOT EXISTS quote_revisions (
+    revision_id SERIAL PRIMARY KEY,
+    quote_id    INTEGER NOT NULL REFERENCES quotes(quote_id) ON DELETE CASCADE,
+    revision    INTEGER NOT NULL,
+    quote       TEXT NOT NULL,
+    author      TEXT NOT NULL,
+    book        TEXT NOT NULL,
+    tags        TEXT[] NOT NULL DEFAULT '{}',
+    notes       TEXT NOT NULL DEFAULT '',
+    page        TEXT NOT NULL DEFAULT '',
+    chapter     TEXT NOT NULL DEFAULT '',
+    location    TEXT NOT NULL DEFAULT '',
+    edition     TEXT NOT NULL DEFAULT '',
+    source_url  TEXT NOT NULL DEFAULT '',
+    date_read   DATE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (quote_id, revision)
+);
@@ -0,0 +1,5 @@
+-- Soft delete: quotes with deleted_at set are in the owner's trash and are
+-- hidden from every read path until restored or purged
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_quotes_deleted_at ON quotes(deleted_at) WHERE deleted_at IS NOT NULL;
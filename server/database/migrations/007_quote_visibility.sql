@@ -0,0 +1,12 @@
+-- Per-quote visibility
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+-- Quotes of accounts that had turned off QuotesPublic stay hidden now that
+-- visibility is set per quote
+UPDATE quotes q
+SET visibility = 'private'
+FROM users u
+WHERE q.user_id = u.id
+  AND q.visibility = 'public'
+  AND u.privacy_settings->>'quotes_public' = 'false';
@@ -0,0 +1,15 @@
+-- Quotes and users a viewer has chosen not to see in their feed
+CREATE TABLE IF NOT EXISTS hidden_quotes (
+    user_id   INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quote_id  INTEGER NOT NULL REFERENCES quotes(quote_id) ON DELETE CASCADE,
+    hidden_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, quote_id)
+);
+
+CREATE TABLE IF NOT EXISTS muted_users (
+    user_id       INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, muted_user_id),
+    CHECK (user_id <> muted_user_id)
+);
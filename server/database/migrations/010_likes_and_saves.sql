@@ -0,0 +1,16 @@
+-- Likes on quotes
+CREATE TABLE IF NOT EXISTS quote_likes (
+    user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quote_id   INTEGER NOT NULL REFERENCES quotes(quote_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, quote_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_likes_quote_id ON quote_likes(quote_id);
+
+-- Saved copies keep attribution to the quote and owner they were saved from.
+-- The user is kept separately so attribution survives the original being purged.
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS saved_from_quote_id INTEGER REFERENCES quotes(quote_id) ON DELETE SET NULL;
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS saved_from_user_id  INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_quotes_saved_from_quote_id ON quotes(saved_from_quote_id) WHERE saved_from_quote_id IS NOT NULL;
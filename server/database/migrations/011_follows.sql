@@ -0,0 +1,13 @@
+-- Users following other users for the personalized feed
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followed_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followed_id),
+    CHECK (follower_id <> followed_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follows_followed_id ON follows(followed_id);
+
+-- Keyset pagination of a user's public quotes, newest first
+CREATE INDEX IF NOT EXISTS idx_quotes_user_created ON quotes(user_id, created_at DESC, quote_id DESC) WHERE deleted_at IS NULL;
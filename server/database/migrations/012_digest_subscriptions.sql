@@ -0,0 +1,19 @@
+-- Opt-in quote digest emails. A row exists only while the user is subscribed.
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    user_id           INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    frequency         VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    selection         VARCHAR(10) NOT NULL DEFAULT 'random' CHECK (selection IN ('random', 'spaced')),
+    timezone          VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    send_hour         SMALLINT NOT NULL DEFAULT 8 CHECK (send_hour BETWEEN 0 AND 23),
+    weekday           SMALLINT NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+    unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+    last_sent_at      TIMESTAMP,
+    next_send_at      TIMESTAMP NOT NULL,
+    created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_subscriptions_next_send_at ON digest_subscriptions(next_send_at);
+
+-- When each quote last appeared in a digest, for spaced selection
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS last_digested_at TIMESTAMP;
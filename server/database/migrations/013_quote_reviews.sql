@@ -0,0 +1,15 @@
+-- Spaced-repetition (SM-2) review state. A quote has no row until it is
+-- first reviewed, so new quotes are always due.
+CREATE TABLE IF NOT EXISTS quote_reviews (
+    user_id          INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    quote_id         INTEGER NOT NULL REFERENCES quotes(quote_id) ON DELETE CASCADE,
+    ease_factor      REAL NOT NULL DEFAULT 2.5 CHECK (ease_factor >= 1.3),
+    interval_days    INTEGER NOT NULL DEFAULT 0 CHECK (interval_days >= 0),
+    repetitions      INTEGER NOT NULL DEFAULT 0 CHECK (repetitions >= 0),
+    last_grade       SMALLINT NOT NULL CHECK (last_grade BETWEEN 0 AND 5),
+    last_reviewed_at TIMESTAMP NOT NULL,
+    due_at           TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, quote_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_reviews_user_due ON quote_reviews(user_id, due_at);
@@ -0,0 +1,32 @@
+-- Outgoing webhooks for quote lifecycle events
+CREATE TABLE IF NOT EXISTS webhooks (
+    webhook_id SERIAL PRIMARY KEY,
+    user_id    INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url        TEXT NOT NULL,
+    secret     VARCHAR(64) NOT NULL,
+    events     TEXT[] NOT NULL,
+    active     BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id);
+
+-- One row per event sent to a webhook. Pending deliveries are retried with
+-- exponential backoff until they succeed or run out of attempts.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id     SERIAL PRIMARY KEY,
+    webhook_id      INTEGER NOT NULL REFERENCES webhooks(webhook_id) ON DELETE CASCADE,
+    event           VARCHAR(32) NOT NULL,
+    payload         JSONB NOT NULL,
+    status          VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    last_attempt_at TIMESTAMP,
+    response_status INTEGER,
+    error           TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, delivery_id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
@@ -29,7 +29,7 @@ func GenerateToken() (string, error) {
 }
 
 // CreatePasswordResetToken creates a new password reset token for a user
-func CreatePasswordResetToken(ctx context.Context, db *sql.DB, userID int) (*PasswordResetToken, error) {
+func (s *PostgresStore) CreatePasswordResetToken(ctx context.Context, userID int) (*PasswordResetToken, error) {
 	token, err := GenerateToken()
 	if err != nil {
 		return nil, err
@@ -50,7 +50,7 @@ func CreatePasswordResetToken(ctx context.Context, db *sql.DB, userID int) (*Pas
 		Used:      false,
 	}
 
-	err = db.QueryRowContext(ctx, query, userID, token, expiresAt).Scan(&prt.ID, &prt.CreatedAt)
+	err = s.db.QueryRowContext(ctx, query, userID, token, expiresAt).Scan(&prt.ID, &prt.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +59,7 @@ func CreatePasswordResetToken(ctx context.Context, db *sql.DB, userID int) (*Pas
 }
 
 // GetPasswordResetToken retrieves a valid, unused token
-func GetPasswordResetToken(ctx context.Context, db *sql.DB, token string) (*PasswordResetToken, error) {
+func (s *PostgresStore) GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetToken, error) {
 	query := `
 		SELECT id, user_id, token, expires_at, used, created_at
 		FROM password_reset_tokens
@@ -67,7 +67,7 @@ func GetPasswordResetToken(ctx context.Context, db *sql.DB, token string) (*Pass
 	`
 
 	prt := &PasswordResetToken{}
-	err := db.QueryRowContext(ctx, query, token, time.Now()).Scan(
+	err := s.db.QueryRowContext(ctx, query, token, time.Now()).Scan(
 		&prt.ID,
 		&prt.UserID,
 		&prt.Token,
@@ -87,36 +87,36 @@ func GetPasswordResetToken(ctx context.Context, db *sql.DB, token string) (*Pass
 }
 
 // MarkTokenAsUsed marks a token as used
-func MarkTokenAsUsed(ctx context.Context, db *sql.DB, tokenID int) error {
+func (s *PostgresStore) MarkTokenAsUsed(ctx context.Context, tokenID int) error {
 	query := `
 		UPDATE password_reset_tokens
 		SET used = true
 		WHERE id = $1
 	`
 
-	_, err := db.ExecContext(ctx, query, tokenID)
+	_, err := s.db.ExecContext(ctx, query, tokenID)
 	return err
 }
 
 // InvalidateUserTokens invalidates all pending tokens for a user
-func InvalidateUserTokens(ctx context.Context, db *sql.DB, userID int) error {
+func (s *PostgresStore) InvalidateUserTokens(ctx context.Context, userID int) error {
 	query := `
 		UPDATE password_reset_tokens
 		SET used = true
 		WHERE user_id = $1 AND used = false
 	`
 
-	_, err := db.ExecContext(ctx, query, userID)
+	_, err := s.db.ExecContext(ctx, query, userID)
 	return err
 }
 
 // CleanupExpiredTokens removes old expired tokens
-func CleanupExpiredTokens(ctx context.Context, db *sql.DB) error {
+func (s *PostgresStore) CleanupExpiredTokens(ctx context.Context) error {
 	query := `
 		DELETE FROM password_reset_tokens
 		WHERE expires_at < $1 OR used = true
 	`
 
-	_, err := db.ExecContext(ctx, query, time.Now().Add(-config.TokenCleanupAge))
+	_, err := s.db.ExecContext(ctx, query, time.Now().Add(-config.TokenCleanupAge))
 	return err
 }
@@ -8,77 +8,134 @@ import (
 	"github.com/zach-monroe/zetl/server/models"
 )
 
-// GetQuoteByID retrieves a single quote by its ID
-func GetQuoteByID(ctx context.Context, db *sql.DB, quoteID int) (map[string]interface{}, error) {
-	query := `
-		SELECT quote_id, user_id, quote, author, book, tags, COALESCE(notes, '') as notes
-		FROM quotes
-		WHERE quote_id = $1
-	`
-
+// quoteColumns selects the fields read by scanQuote from a table aliased as q
+const quoteColumns = `q.quote_id, q.user_id, q.quote, q.author, q.book, q.tags, COALESCE(q.notes, '') as notes,
+		       q.page, q.chapter, q.location, q.edition, q.source_url,
+		       COALESCE(to_char(q.date_read, 'YYYY-MM-DD'), '') as date_read,
+		       q.visibility, q.saved_from_quote_id, q.saved_from_user_id,
+		       COALESCE((SELECT u.username FROM users u WHERE u.id = q.saved_from_user_id), '') AS saved_from_username,
+		       (SELECT COUNT(*) FROM quote_likes l WHERE l.quote_id = q.quote_id) AS like_count,
+		       q.created_at, q.updated_at, q.deleted_at`
+
+// scanQuote reads a row selected with quoteColumns
+func scanQuote(row rowScanner) (models.Quote, error) {
 	var (
-		qID    int
-		userID int
-		quote  string
-		author string
-		book   string
-		tags   []byte
-		notes  string
+		q             models.Quote
+		tags          []byte
+		savedFromID   sql.NullInt64
+		savedFromUser sql.NullInt64
+		savedFromName string
 	)
 
-	err := db.QueryRowContext(ctx, query, quoteID).Scan(&qID, &userID, &quote, &author, &book, &tags, &notes)
-	if err == sql.ErrNoRows {
-		return nil, ErrQuoteNotFound
-	}
+	err := row.Scan(&q.QuoteID, &q.UserID, &q.Quote, &q.Author, &q.Book, &tags, &q.Notes,
+		&q.Page, &q.Chapter, &q.Location, &q.Edition, &q.SourceURL, &q.DateRead,
+		&q.Visibility, &savedFromID, &savedFromUser, &savedFromName, &q.LikeCount,
+		&q.CreatedAt, &q.UpdatedAt, &q.DeletedAt)
 	if err != nil {
-		return nil, err
+		return q, err
 	}
 
-	result := map[string]interface{}{
-		"quote_id": qID,
-		"user_id":  userID,
-		"quote":    quote,
-		"author":   author,
-		"book":     book,
-		"tags":     ParsePostgresTags(tags),
-		"notes":    notes,
+	q.Tags = ParsePostgresTags(tags)
+	if savedFromUser.Valid {
+		q.SavedFrom = &models.SavedFrom{
+			QuoteID:  nullIntPtr(savedFromID),
+			UserID:   int(savedFromUser.Int64),
+			Username: savedFromName,
+		}
 	}
-
-	return result, nil
+	return q, nil
 }
 
-// UpdateQuote updates a quote's content
-func UpdateQuote(ctx context.Context, db *sql.DB, quoteID int, quote, author, book string, tags []string, notes string) error {
-	tagsStr := FormatPostgresTags(tags)
+// nullableDate maps an empty YYYY-MM-DD string to NULL
+func nullableDate(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
 
+// GetQuoteByID retrieves a single quote by its ID. Quotes in the trash are not found.
+func (s *PostgresStore) GetQuoteByID(ctx context.Context, quoteID int) (*models.Quote, error) {
 	query := `
-		UPDATE quotes
-		SET quote = $1, author = $2, book = $3, tags = $4, notes = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE quote_id = $6
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE q.quote_id = $1 AND q.deleted_at IS NULL
 	`
 
-	result, err := db.ExecContext(ctx, query, quote, author, book, tagsStr, notes, quoteID)
+	q, err := scanQuote(s.db.QueryRowContext(ctx, query, quoteID))
+	if err == sql.ErrNoRows {
+		return nil, ErrQuoteNotFound
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return &q, nil
+}
+
+// UpdateQuote updates a quote's content, recording the previous content as
+// a revision in the same transaction. An empty visibility leaves it unchanged.
+func (s *PostgresStore) UpdateQuote(ctx context.Context, quoteID int, quote, author, book string, tags []string, notes string, citation models.Citation, visibility string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
+	next := models.Quote{Quote: quote, Author: author, Book: book, Tags: tags, Notes: notes, Citation: citation, Visibility: visibility}
+	if err := updateQuoteTx(ctx, tx, quoteID, next); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateQuoteTx locks the quote, saves its current content as the next
+// revision when next differs from it, then writes next. Visibility is not
+// revisioned and is kept when next.Visibility is empty.
+func updateQuoteTx(ctx context.Context, tx *sql.Tx, quoteID int, next models.Quote) error {
+	current, err := scanQuote(tx.QueryRowContext(ctx, `
+		SELECT `+quoteColumns+`
+		FROM quotes q
+		WHERE q.quote_id = $1 AND q.deleted_at IS NULL
+		FOR UPDATE
+	`, quoteID))
+	if err == sql.ErrNoRows {
 		return ErrQuoteNotFound
 	}
+	if err != nil {
+		return err
+	}
 
-	return nil
+	if len(models.DiffQuotes(current, next)) > 0 {
+		if err := insertRevision(ctx, tx, current); err != nil {
+			return err
+		}
+	}
+
+	if next.Visibility == "" {
+		next.Visibility = current.Visibility
+	}
+
+	query := `
+		UPDATE quotes
+		SET quote = $1, author = $2, book = $3, tags = $4, notes = $5,
+		    page = $6, chapter = $7, location = $8, edition = $9, source_url = $10, date_read = $11,
+		    visibility = $12, updated_at = CURRENT_TIMESTAMP
+		WHERE quote_id = $13
+	`
+
+	_, err = tx.ExecContext(ctx, query, next.Quote, next.Author, next.Book, FormatPostgresTags(next.Tags), next.Notes,
+		next.Page, next.Chapter, next.Location, next.Edition, next.SourceURL, nullableDate(next.DateRead),
+		next.Visibility, quoteID)
+	return err
 }
 
-// DeleteQuote removes a quote from the database
-func DeleteQuote(ctx context.Context, db *sql.DB, quoteID int) error {
-	query := `DELETE FROM quotes WHERE quote_id = $1`
+// DeleteQuote moves a quote to the trash
+func (s *PostgresStore) DeleteQuote(ctx context.Context, quoteID int) error {
+	query := `UPDATE quotes SET deleted_at = CURRENT_TIMESTAMP WHERE quote_id = $1 AND deleted_at IS NULL`
 
-	result, err := db.ExecContext(ctx, query, quoteID)
+	result, err := s.db.ExecContext(ctx, query, quoteID)
 	if err != nil {
 		return err
 	}
@@ -95,26 +152,12 @@ func DeleteQuote(ctx context.Context, db *sql.DB, quoteID int) error {
 	return nil
 }
 
-// GetQuotesByUserID retrieves all quotes for a specific user as maps
-func GetQuotesByUserID(ctx context.Context, db *sql.DB, userID int) ([]map[string]interface{}, error) {
-	quotes, err := FetchQuotesByUserID(ctx, db, userID)
-	if err != nil {
-		return nil, err
-	}
-
-	result := make([]map[string]interface{}, len(quotes))
-	for i, q := range quotes {
-		result[i] = q.ToMap()
-	}
-	return result, nil
-}
-
 // VerifyQuoteOwnership checks if a user owns a specific quote
-func VerifyQuoteOwnership(ctx context.Context, db *sql.DB, quoteID, userID int) (bool, error) {
-	query := `SELECT user_id FROM quotes WHERE quote_id = $1`
+func (s *PostgresStore) VerifyQuoteOwnership(ctx context.Context, quoteID, userID int) (bool, error) {
+	query := `SELECT user_id FROM quotes WHERE quote_id = $1 AND deleted_at IS NULL`
 
 	var ownerID int
-	err := db.QueryRowContext(ctx, query, quoteID).Scan(&ownerID)
+	err := s.db.QueryRowContext(ctx, query, quoteID).Scan(&ownerID)
 	if err == sql.ErrNoRows {
 		return false, ErrQuoteNotFound
 	}
@@ -126,17 +169,20 @@ func VerifyQuoteOwnership(ctx context.Context, db *sql.DB, quoteID, userID int)
 }
 
 // CreateQuote inserts a new quote into the database
-func CreateQuote(ctx context.Context, db *sql.DB, userID int, quote, author, book string, tags []string, notes string) (int, error) {
+func (s *PostgresStore) CreateQuote(ctx context.Context, userID int, quote, author, book string, tags []string, notes string, citation models.Citation, visibility string) (int, error) {
 	tagsArray := pq.Array(tags)
 
 	query := `
-		INSERT INTO quotes (user_id, quote, author, book, tags, notes)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO quotes (user_id, quote, author, book, tags, notes, page, chapter, location, edition, source_url, date_read, visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING quote_id
 	`
 
 	var quoteID int
-	err := db.QueryRowContext(ctx, query, userID, quote, author, book, tagsArray, notes).Scan(&quoteID)
+	err := s.db.QueryRowContext(ctx, query, userID, quote, author, book, tagsArray, notes,
+		citation.Page, citation.Chapter, citation.Location, citation.Edition, citation.SourceURL, nullableDate(citation.DateRead),
+		visibility,
+	).Scan(&quoteID)
 	if err != nil {
 		return 0, err
 	}
@@ -144,16 +190,62 @@ func CreateQuote(ctx context.Context, db *sql.DB, userID int, quote, author, boo
 	return quoteID, nil
 }
 
-// FetchQuotesByUserID retrieves all quotes for a specific user as models.Quotes
-func FetchQuotesByUserID(ctx context.Context, db *sql.DB, userID int) (models.Quotes, error) {
+// GetQuotesByUserID retrieves all quotes for a specific user, newest first
+func (s *PostgresStore) GetQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error) {
 	query := `
-		SELECT quote_id, user_id, quote, author, book, tags, COALESCE(notes, '') as notes
-		FROM quotes
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE q.user_id = $1 AND q.deleted_at IS NULL
+		ORDER BY q.created_at DESC
 	`
 
-	rows, err := db.QueryContext(ctx, query, userID)
+	return s.queryQuotes(ctx, query, userID)
+}
+
+// GetPublicQuotesByUserID retrieves a user's public quotes, newest first
+func (s *PostgresStore) GetPublicQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE q.user_id = $1 AND q.visibility = 'public' AND q.deleted_at IS NULL
+		ORDER BY q.created_at DESC
+	`
+
+	return s.queryQuotes(ctx, query, userID)
+}
+
+// GetPublicQuotesByTag retrieves up to limit public quotes with tag, newest first
+func (s *PostgresStore) GetPublicQuotesByTag(ctx context.Context, tag string, limit int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE $1 = ANY(q.tags) AND q.visibility = 'public' AND q.deleted_at IS NULL
+		ORDER BY q.created_at DESC
+		LIMIT $2
+	`
+
+	return s.queryQuotes(ctx, query, tag, limit)
+}
+
+// GetFeedQuotes retrieves every public quote plus all of the viewer's own
+// quotes, newest first, leaving out quotes and users the viewer has hidden
+// or muted. Pass a viewerID of 0 for anonymous visitors.
+func (s *PostgresStore) GetFeedQuotes(ctx context.Context, viewerID int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE (q.visibility = 'public' OR q.user_id = $1) AND q.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM hidden_quotes h WHERE h.user_id = $1 AND h.quote_id = q.quote_id)
+		  AND NOT EXISTS (SELECT 1 FROM muted_users m WHERE m.user_id = $1 AND m.muted_user_id = q.user_id)
+		ORDER BY q.created_at DESC
+	`
+
+	return s.queryQuotes(ctx, query, viewerID)
+}
+
+// queryQuotes runs a query selecting quoteColumns and scans every row
+func (s *PostgresStore) queryQuotes(ctx context.Context, query string, args ...interface{}) (models.Quotes, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,30 +254,11 @@ func FetchQuotesByUserID(ctx context.Context, db *sql.DB, userID int) (models.Qu
 	quotes := make(models.Quotes, 0)
 
 	for rows.Next() {
-		var (
-			qID    int
-			uID    int
-			quote  string
-			author string
-			book   string
-			tags   []byte
-			notes  string
-		)
-
-		if err := rows.Scan(&qID, &uID, &quote, &author, &book, &tags, &notes); err != nil {
+		q, err := scanQuote(rows)
+		if err != nil {
 			return nil, err
 		}
 
-		q := models.Quote{
-			QuoteID: qID,
-			UserID:  uID,
-			Quote:   quote,
-			Author:  author,
-			Book:    book,
-			Tags:    ParsePostgresTags(tags),
-			Notes:   notes,
-		}
-
 		quotes = append(quotes, q)
 	}
 
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// quoteFilterFrom selects the quotes passing a models.QuoteFilter whose
+// fields are bound to $1 through $4 by quoteFilterArgs
+const quoteFilterFrom = `
+	FROM quotes q
+	WHERE q.deleted_at IS NULL
+	  AND (q.visibility = 'public' OR ($1 <> 0 AND q.user_id = $1))
+	  AND ($2 = 0 OR q.user_id = $2)
+	  AND ($3::text = '' OR $3::text = ANY(q.tags))
+	  AND ($4::text = '' OR strpos(lower(q.author), lower($4::text)) > 0)
+	  AND NOT EXISTS (SELECT 1 FROM hidden_quotes h WHERE h.user_id = $1 AND h.quote_id = q.quote_id)
+	  AND NOT EXISTS (SELECT 1 FROM muted_users m WHERE m.user_id = $1 AND m.muted_user_id = q.user_id)
+`
+
+// quoteFilterArgs are the query arguments for quoteFilterFrom
+func quoteFilterArgs(filter models.QuoteFilter) []interface{} {
+	return []interface{}{filter.ViewerID, filter.UserID, filter.Tag, filter.Author}
+}
+
+// CountFilteredQuotes counts the quotes passing filter
+func (s *PostgresStore) CountFilteredQuotes(ctx context.Context, filter models.QuoteFilter) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) `+quoteFilterFrom, quoteFilterArgs(filter)...).Scan(&count)
+	return count, err
+}
+
+// GetFilteredQuoteAt retrieves the quote at offset among those passing
+// filter, in quote ID order. It returns ErrQuoteNotFound when offset is past
+// the last one.
+func (s *PostgresStore) GetFilteredQuoteAt(ctx context.Context, filter models.QuoteFilter, offset int) (*models.Quote, error) {
+	query := `SELECT ` + quoteColumns + quoteFilterFrom + ` ORDER BY q.quote_id LIMIT 1 OFFSET $5`
+
+	q, err := scanQuote(s.db.QueryRowContext(ctx, query, append(quoteFilterArgs(filter), offset)...))
+	if err == sql.ErrNoRows {
+		return nil, ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// GetDailyFilteredQuote retrieves the quote passing filter that ranks first
+// for key under QuoteRank. A quote's rank doesn't depend on the other
+// candidates, so the pick only changes when the picked quote itself stops
+// matching or a new quote outranks it.
+func (s *PostgresStore) GetDailyFilteredQuote(ctx context.Context, filter models.QuoteFilter, key string) (*models.Quote, error) {
+	query := `SELECT ` + quoteColumns + quoteFilterFrom + ` ORDER BY md5($5 || q.quote_id::text) COLLATE "C", q.quote_id LIMIT 1`
+
+	q, err := scanQuote(s.db.QueryRowContext(ctx, query, append(quoteFilterArgs(filter), key)...))
+	if err == sql.ErrNoRows {
+		return nil, ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// QuoteRank orders quotes for a daily pick identified by key; the lowest
+// rank wins. It matches the ordering GetDailyFilteredQuote uses in Postgres.
+func QuoteRank(key string, quoteID int) string {
+	sum := md5.Sum([]byte(key + strconv.Itoa(quoteID)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// reviewDueFrom selects a user's quotes that are due for review at $2 and
+// pass the tag filter in $3 (any of) and $4 (none of), with their review
+// state. Quotes that have never been reviewed have no review row and are
+// always due.
+const reviewDueFrom = `
+	FROM quotes q
+	LEFT JOIN quote_reviews r ON r.user_id = q.user_id AND r.quote_id = q.quote_id
+	WHERE q.user_id = $1 AND q.deleted_at IS NULL
+	  AND (r.due_at IS NULL OR r.due_at <= $2)
+	  AND (cardinality($3::text[]) = 0 OR q.tags && $3::text[])
+	  AND NOT (q.tags && $4::text[])
+`
+
+// reviewFilterArgs are the query arguments for reviewDueFrom
+func reviewFilterArgs(userID int, now time.Time, filter models.ReviewFilter) []interface{} {
+	return []interface{}{userID, now.UTC(), pq.Array(nonNilTags(filter.Tags)), pq.Array(nonNilTags(filter.ExcludeTags))}
+}
+
+// nonNilTags keeps pq from sending a nil slice as NULL
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// GetNextReview retrieves the user's quote most in need of review at now:
+// the one overdue longest, or failing that their oldest unreviewed quote.
+// It returns ErrNoReviewDue when nothing matching filter is due.
+func (s *PostgresStore) GetNextReview(ctx context.Context, userID int, now time.Time, filter models.ReviewFilter) (*models.ReviewCard, error) {
+	query := `
+		SELECT q.quote_id, r.ease_factor, r.interval_days, r.repetitions, r.last_grade, r.last_reviewed_at, r.due_at
+		` + reviewDueFrom + `
+		ORDER BY r.due_at NULLS LAST, q.quote_id
+		LIMIT 1
+	`
+
+	var (
+		quoteID        int
+		easeFactor     sql.NullFloat64
+		intervalDays   sql.NullInt64
+		repetitions    sql.NullInt64
+		lastGrade      sql.NullInt64
+		lastReviewedAt sql.NullTime
+		dueAt          sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, query, reviewFilterArgs(userID, now, filter)...).Scan(
+		&quoteID, &easeFactor, &intervalDays, &repetitions, &lastGrade, &lastReviewedAt, &dueAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoReviewDue
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &models.ReviewCard{Quote: *quote}
+	if dueAt.Valid {
+		card.Review = &models.QuoteReview{
+			QuoteID:        quoteID,
+			EaseFactor:     easeFactor.Float64,
+			IntervalDays:   int(intervalDays.Int64),
+			Repetitions:    int(repetitions.Int64),
+			LastGrade:      int(lastGrade.Int64),
+			LastReviewedAt: lastReviewedAt.Time,
+			DueAt:          dueAt.Time,
+		}
+	}
+	return card, nil
+}
+
+// CountDueReviews counts the user's quotes matching filter that are due for review at now
+func (s *PostgresStore) CountDueReviews(ctx context.Context, userID int, now time.Time, filter models.ReviewFilter) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) `+reviewDueFrom, reviewFilterArgs(userID, now, filter)...).Scan(&count)
+	return count, err
+}
+
+// GetQuoteReview retrieves the user's review state for a quote, or the
+// starting state if they have never reviewed it
+func (s *PostgresStore) GetQuoteReview(ctx context.Context, userID, quoteID int) (*models.QuoteReview, error) {
+	query := `
+		SELECT quote_id, ease_factor, interval_days, repetitions, last_grade, last_reviewed_at, due_at
+		FROM quote_reviews
+		WHERE user_id = $1 AND quote_id = $2
+	`
+
+	var r models.QuoteReview
+	err := s.db.QueryRowContext(ctx, query, userID, quoteID).Scan(
+		&r.QuoteID, &r.EaseFactor, &r.IntervalDays, &r.Repetitions, &r.LastGrade, &r.LastReviewedAt, &r.DueAt)
+	if err == sql.ErrNoRows {
+		r = models.NewQuoteReview(quoteID)
+		return &r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// SaveQuoteReview stores the user's review state for a quote
+func (s *PostgresStore) SaveQuoteReview(ctx context.Context, userID int, review *models.QuoteReview) error {
+	query := `
+		INSERT INTO quote_reviews (user_id, quote_id, ease_factor, interval_days, repetitions, last_grade, last_reviewed_at, due_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, quote_id) DO UPDATE SET
+			ease_factor = EXCLUDED.ease_factor,
+			interval_days = EXCLUDED.interval_days,
+			repetitions = EXCLUDED.repetitions,
+			last_grade = EXCLUDED.last_grade,
+			last_reviewed_at = EXCLUDED.last_reviewed_at,
+			due_at = EXCLUDED.due_at
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, review.QuoteID, review.EaseFactor, review.IntervalDays,
+		review.Repetitions, review.LastGrade, review.LastReviewedAt.UTC(), review.DueAt.UTC())
+	return err
+}
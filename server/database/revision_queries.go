@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// insertRevision saves a quote's content as its next revision. The caller
+// must hold the quote's row lock so revision numbers cannot collide.
+func insertRevision(ctx context.Context, tx *sql.Tx, q models.Quote) error {
+	var revision int
+	err := tx.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(revision), 0) + 1 FROM quote_revisions WHERE quote_id = $1`, q.QuoteID,
+	).Scan(&revision)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO quote_revisions (quote_id, revision, quote, author, book, tags, notes,
+		                             page, chapter, location, edition, source_url, date_read)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = tx.ExecContext(ctx, query, q.QuoteID, revision, q.Quote, q.Author, q.Book, pq.Array(q.Tags), q.Notes,
+		q.Page, q.Chapter, q.Location, q.Edition, q.SourceURL, nullableDate(q.DateRead))
+	return err
+}
+
+// revisionColumns selects the fields read by scanRevision
+const revisionColumns = `quote_id, revision, quote, author, book, tags, notes,
+		       page, chapter, location, edition, source_url,
+		       COALESCE(to_char(date_read, 'YYYY-MM-DD'), '') as date_read, created_at`
+
+func scanRevision(row rowScanner) (models.QuoteRevision, error) {
+	var (
+		r    models.QuoteRevision
+		tags []byte
+	)
+
+	err := row.Scan(&r.QuoteID, &r.Revision, &r.Quote, &r.Author, &r.Book, &tags, &r.Notes,
+		&r.Page, &r.Chapter, &r.Location, &r.Edition, &r.SourceURL, &r.DateRead, &r.CreatedAt)
+	if err != nil {
+		return r, err
+	}
+
+	r.Tags = ParsePostgresTags(tags)
+	return r, nil
+}
+
+// GetQuoteRevisions retrieves a quote's revisions, newest first
+func (s *PostgresStore) GetQuoteRevisions(ctx context.Context, quoteID int) ([]models.QuoteRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM quote_revisions
+		WHERE quote_id = $1
+		ORDER BY revision DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]models.QuoteRevision, 0)
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+
+	return revisions, rows.Err()
+}
+
+// RestoreQuoteRevision replaces a quote's content with an earlier revision.
+// The content being replaced is itself recorded, so a restore can be undone.
+func (s *PostgresStore) RestoreQuoteRevision(ctx context.Context, quoteID, revision int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	r, err := scanRevision(tx.QueryRowContext(ctx, `
+		SELECT `+revisionColumns+`
+		FROM quote_revisions
+		WHERE quote_id = $1 AND revision = $2
+	`, quoteID, revision))
+	if err == sql.ErrNoRows {
+		return ErrRevisionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := updateQuoteTx(ctx, tx, quoteID, r.AsQuote()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
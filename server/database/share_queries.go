@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+const shareLinkColumns = `share_id, token, user_id, quote_id, collection_id, expires_at, revoked_at, created_at`
+
+func scanShareLink(row rowScanner) (*models.ShareLink, error) {
+	var (
+		link         models.ShareLink
+		quoteID      sql.NullInt64
+		collectionID sql.NullInt64
+		expiresAt    sql.NullTime
+		revokedAt    sql.NullTime
+	)
+
+	err := row.Scan(&link.ShareID, &link.Token, &link.UserID, &quoteID, &collectionID, &expiresAt, &revokedAt, &link.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	link.QuoteID = nullIntPtr(quoteID)
+	link.CollectionID = nullIntPtr(collectionID)
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		link.RevokedAt = &revokedAt.Time
+	}
+	return &link, nil
+}
+
+// CreateShareLink generates a token and stores a new share link.
+// Exactly one of link.QuoteID or link.CollectionID must be set.
+func CreateShareLink(ctx context.Context, db *sql.DB, link *models.ShareLink) error {
+	token, err := GenerateToken()
+	if err != nil {
+		return err
+	}
+	link.Token = token
+
+	query := `
+		INSERT INTO share_links (token, user_id, quote_id, collection_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING share_id, created_at
+	`
+
+	return db.QueryRowContext(ctx, query, link.Token, link.UserID, link.QuoteID, link.CollectionID, link.ExpiresAt).
+		Scan(&link.ShareID, &link.CreatedAt)
+}
+
+// GetActiveShareLink retrieves a share link by token if it is neither revoked nor expired
+func GetActiveShareLink(ctx context.Context, db *sql.DB, token string) (*models.ShareLink, error) {
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE token = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`
+
+	link, err := scanShareLink(db.QueryRowContext(ctx, query, token, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+// GetShareLinksByUserID retrieves all of a user's share links, newest first
+func GetShareLinksByUserID(ctx context.Context, db *sql.DB, userID int) ([]models.ShareLink, error) {
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]models.ShareLink, 0)
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+// RevokeShareLink marks one of a user's share links as revoked
+func RevokeShareLink(ctx context.Context, db *sql.DB, shareID, userID int) error {
+	query := `
+		UPDATE share_links
+		SET revoked_at = $1
+		WHERE share_id = $2 AND user_id = $3 AND revoked_at IS NULL
+	`
+
+	result, err := db.ExecContext(ctx, query, time.Now(), shareID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrShareLinkNotFound
+	}
+
+	return nil
+}
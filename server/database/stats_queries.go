@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SiteTotals holds row counts used for monitoring
+type SiteTotals struct {
+	Users       int
+	Quotes      int
+	Collections int
+	Books       int
+}
+
+// GetSiteTotals counts users, quotes, collections and books in one round trip
+func GetSiteTotals(ctx context.Context, db *sql.DB) (*SiteTotals, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM quotes WHERE deleted_at IS NULL),
+			(SELECT COUNT(*) FROM collections),
+			(SELECT COUNT(*) FROM books)
+	`
+
+	var t SiteTotals
+	if err := db.QueryRowContext(ctx, query).Scan(&t.Users, &t.Quotes, &t.Collections, &t.Books); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
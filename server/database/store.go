@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// QuoteStore persists quotes
+type QuoteStore interface {
+	CreateQuote(ctx context.Context, userID int, quote, author, book string, tags []string, notes string, citation models.Citation, visibility string) (int, error)
+	GetQuoteByID(ctx context.Context, quoteID int) (*models.Quote, error)
+	GetQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error)
+	GetPublicQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error)
+	GetPublicQuotesByTag(ctx context.Context, tag string, limit int) (models.Quotes, error)
+	GetFeedQuotes(ctx context.Context, viewerID int) (models.Quotes, error)
+	UpdateQuote(ctx context.Context, quoteID int, quote, author, book string, tags []string, notes string, citation models.Citation, visibility string) error
+	DeleteQuote(ctx context.Context, quoteID int) error
+	VerifyQuoteOwnership(ctx context.Context, quoteID, userID int) (bool, error)
+	GetQuoteRevisions(ctx context.Context, quoteID int) ([]models.QuoteRevision, error)
+	RestoreQuoteRevision(ctx context.Context, quoteID, revision int) error
+	GetTrashedQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error)
+	RestoreQuote(ctx context.Context, quoteID, userID int) error
+	PurgeQuote(ctx context.Context, quoteID, userID int) error
+	PurgeDeletedQuotes(ctx context.Context, cutoff time.Time) (models.Quotes, error)
+	BulkUpdateQuotes(ctx context.Context, userID int, op models.BulkQuoteOperation) ([]models.BulkQuoteResult, error)
+	CountFilteredQuotes(ctx context.Context, filter models.QuoteFilter) (int, error)
+	GetFilteredQuoteAt(ctx context.Context, filter models.QuoteFilter, offset int) (*models.Quote, error)
+	GetDailyFilteredQuote(ctx context.Context, filter models.QuoteFilter, key string) (*models.Quote, error)
+}
+
+// BookStore persists the books seen on users' quotes and their catalog
+// candidates while they await enrichment
+type BookStore interface {
+	EnsureBook(ctx context.Context, userID int, title, author string) (int, bool, error)
+	GetBookByID(ctx context.Context, bookID int) (*models.Book, error)
+	GetPendingBookIDs(ctx context.Context) ([]int, error)
+	ReplaceBookCandidates(ctx context.Context, bookID int, candidates []models.BookCandidate, status string) error
+}
+
+// UserStore persists user accounts and their settings
+type UserStore interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	UpdateLastLogin(ctx context.Context, userID int) error
+	UpdateUserProfile(ctx context.Context, userID int, username, email, bio string) error
+	UpdateUserPassword(ctx context.Context, userID int, passwordHash string) error
+	UpdateUserPrivacy(ctx context.Context, userID int, settings *models.PrivacySettings) error
+}
+
+// TokenStore persists password reset tokens
+type TokenStore interface {
+	CreatePasswordResetToken(ctx context.Context, userID int) (*PasswordResetToken, error)
+	GetPasswordResetToken(ctx context.Context, token string) (*PasswordResetToken, error)
+	MarkTokenAsUsed(ctx context.Context, tokenID int) error
+	InvalidateUserTokens(ctx context.Context, userID int) error
+	CleanupExpiredTokens(ctx context.Context) error
+}
+
+// FeedFilterStore persists the quotes and users a viewer has hidden from their feed
+type FeedFilterStore interface {
+	HideQuote(ctx context.Context, userID, quoteID int) error
+	UnhideQuote(ctx context.Context, userID, quoteID int) error
+	GetHiddenQuotes(ctx context.Context, userID int) (models.Quotes, error)
+	MuteUser(ctx context.Context, userID, mutedUserID int) error
+	UnmuteUser(ctx context.Context, userID, mutedUserID int) error
+	GetMutedUsers(ctx context.Context, userID int) ([]models.MutedUser, error)
+}
+
+// InteractionStore persists likes and saved copies of other users' quotes
+type InteractionStore interface {
+	LikeQuote(ctx context.Context, userID, quoteID int) error
+	UnlikeQuote(ctx context.Context, userID, quoteID int) error
+	GetLikedQuoteIDs(ctx context.Context, userID int, quoteIDs []int) (map[int]bool, error)
+	SaveQuote(ctx context.Context, userID, quoteID int, visibility string) (int, error)
+	GetQuoteSavers(ctx context.Context, quoteID int) ([]models.QuoteSaver, error)
+}
+
+// FollowStore persists who follows whom and reads the followed users' quotes
+type FollowStore interface {
+	FollowUser(ctx context.Context, userID, followedID int) error
+	UnfollowUser(ctx context.Context, userID, followedID int) error
+	GetFollowing(ctx context.Context, userID int) ([]models.FollowedUser, error)
+	GetFollowFeed(ctx context.Context, userID int, cursor models.FeedCursor, limit int) (models.Quotes, error)
+}
+
+// DigestStore persists digest email subscriptions and selects the quotes
+// that go into each digest
+type DigestStore interface {
+	GetDigestSubscription(ctx context.Context, userID int) (*models.DigestSubscription, error)
+	SaveDigestSubscription(ctx context.Context, sub *models.DigestSubscription) error
+	DeleteDigestSubscription(ctx context.Context, userID int) error
+	UnsubscribeDigest(ctx context.Context, token string) error
+	ClaimDueDigests(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.DigestSubscription, error)
+	GetDigestQuotes(ctx context.Context, userID int, selection string, limit int) (models.Quotes, error)
+	GetOnThisDayQuotes(ctx context.Context, userID int, day time.Time) (models.Quotes, error)
+	MarkDigestSent(ctx context.Context, userID int, sentAt, nextSendAt time.Time, quoteIDs []int) error
+	RescheduleDigest(ctx context.Context, userID int, nextSendAt time.Time) error
+}
+
+// ReviewStore persists spaced-repetition review state for users' own quotes
+type ReviewStore interface {
+	GetNextReview(ctx context.Context, userID int, now time.Time, filter models.ReviewFilter) (*models.ReviewCard, error)
+	CountDueReviews(ctx context.Context, userID int, now time.Time, filter models.ReviewFilter) (int, error)
+	GetQuoteReview(ctx context.Context, userID, quoteID int) (*models.QuoteReview, error)
+	SaveQuoteReview(ctx context.Context, userID int, review *models.QuoteReview) error
+}
+
+// WebhookStore persists users' webhooks and the deliveries of their events
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, hook *models.Webhook) error
+	GetWebhooksByUserID(ctx context.Context, userID int) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, userID, webhookID int) (*models.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, userID, webhookID int) error
+	EnqueueWebhookEvent(ctx context.Context, userID int, event string, payload []byte, now time.Time) (int, error)
+	GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, webhookID, deliveryID int, now time.Time) (*models.WebhookDelivery, error)
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	SaveWebhookDeliveryAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// PostgresStore implements every store interface on PostgreSQL
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store backed by db
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+var (
+	_ QuoteStore       = (*PostgresStore)(nil)
+	_ BookStore        = (*PostgresStore)(nil)
+	_ UserStore        = (*PostgresStore)(nil)
+	_ TokenStore       = (*PostgresStore)(nil)
+	_ FeedFilterStore  = (*PostgresStore)(nil)
+	_ InteractionStore = (*PostgresStore)(nil)
+	_ FollowStore      = (*PostgresStore)(nil)
+	_ DigestStore      = (*PostgresStore)(nil)
+	_ ReviewStore      = (*PostgresStore)(nil)
+	_ WebhookStore     = (*PostgresStore)(nil)
+)
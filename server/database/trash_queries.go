@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// GetTrashedQuotesByUserID retrieves a user's deleted quotes, most recently deleted first
+func (s *PostgresStore) GetTrashedQuotesByUserID(ctx context.Context, userID int) (models.Quotes, error) {
+	query := `
+		SELECT ` + quoteColumns + `
+		FROM quotes q
+		WHERE q.user_id = $1 AND q.deleted_at IS NOT NULL
+		ORDER BY q.deleted_at DESC
+	`
+
+	return s.queryQuotes(ctx, query, userID)
+}
+
+// RestoreQuote moves one of the user's quotes out of the trash
+func (s *PostgresStore) RestoreQuote(ctx context.Context, quoteID, userID int) error {
+	query := `
+		UPDATE quotes SET deleted_at = NULL
+		WHERE quote_id = $1 AND user_id = $2 AND deleted_at IS NOT NULL
+	`
+
+	return s.execTrash(ctx, query, quoteID, userID)
+}
+
+// PurgeQuote permanently deletes one of the user's quotes from the trash
+func (s *PostgresStore) PurgeQuote(ctx context.Context, quoteID, userID int) error {
+	query := `DELETE FROM quotes WHERE quote_id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+
+	return s.execTrash(ctx, query, quoteID, userID)
+}
+
+// execTrash runs a statement on a single trashed quote, returning
+// ErrQuoteNotFound when the user has no such quote in the trash
+func (s *PostgresStore) execTrash(ctx context.Context, query string, quoteID, userID int) error {
+	result, err := s.db.ExecContext(ctx, query, quoteID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrQuoteNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeletedQuotes permanently deletes every quote trashed before cutoff
+// and returns the quotes removed
+func (s *PostgresStore) PurgeDeletedQuotes(ctx context.Context, cutoff time.Time) (models.Quotes, error) {
+	query := `
+		DELETE FROM quotes q
+		WHERE q.deleted_at < $1
+		RETURNING ` + quoteColumns
+
+	return s.queryQuotes(ctx, query, cutoff)
+}
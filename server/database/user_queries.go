@@ -11,14 +11,14 @@ import (
 )
 
 // CreateUser inserts a new user into the database
-func CreateUser(ctx context.Context, db *sql.DB, user *models.User) error {
+func (s *PostgresStore) CreateUser(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (username, email, password_hash, is_active)
 		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := db.QueryRowContext(ctx, query, user.Username, user.Email, user.PasswordHash, user.IsActive).
+	err := s.db.QueryRowContext(ctx, query, user.Username, user.Email, user.PasswordHash, user.IsActive).
 		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
@@ -38,7 +38,7 @@ func CreateUser(ctx context.Context, db *sql.DB, user *models.User) error {
 }
 
 // getUserBy is a generic helper to retrieve a user by a specified column
-func getUserBy(ctx context.Context, db *sql.DB, whereClause string, arg interface{}) (*models.User, error) {
+func (s *PostgresStore) getUserBy(ctx context.Context, whereClause string, arg interface{}) (*models.User, error) {
 	user := &models.User{}
 	var privacySettingsJSON []byte
 
@@ -49,7 +49,7 @@ func getUserBy(ctx context.Context, db *sql.DB, whereClause string, arg interfac
 		FROM users
 		WHERE ` + whereClause
 
-	err := db.QueryRowContext(ctx, query, arg).Scan(
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
@@ -74,41 +74,41 @@ func getUserBy(ctx context.Context, db *sql.DB, whereClause string, arg interfac
 }
 
 // GetUserByUsername retrieves a user by username
-func GetUserByUsername(ctx context.Context, db *sql.DB, username string) (*models.User, error) {
-	return getUserBy(ctx, db, "username = $1", username)
+func (s *PostgresStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.getUserBy(ctx, "username = $1", username)
 }
 
 // GetUserByEmail retrieves a user by email
-func GetUserByEmail(ctx context.Context, db *sql.DB, email string) (*models.User, error) {
-	return getUserBy(ctx, db, "email = $1", email)
+func (s *PostgresStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return s.getUserBy(ctx, "email = $1", email)
 }
 
 // GetUserByID retrieves a user by ID
-func GetUserByID(ctx context.Context, db *sql.DB, id int) (*models.User, error) {
-	return getUserBy(ctx, db, "id = $1", id)
+func (s *PostgresStore) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	return s.getUserBy(ctx, "id = $1", id)
 }
 
 // UpdateLastLogin updates the last_login timestamp for a user
-func UpdateLastLogin(ctx context.Context, db *sql.DB, userID int) error {
+func (s *PostgresStore) UpdateLastLogin(ctx context.Context, userID int) error {
 	query := `
 		UPDATE users
 		SET last_login = $1
 		WHERE id = $2
 	`
 
-	_, err := db.ExecContext(ctx, query, time.Now(), userID)
+	_, err := s.db.ExecContext(ctx, query, time.Now(), userID)
 	return err
 }
 
 // UpdateUserProfile updates user's username, email, and bio
-func UpdateUserProfile(ctx context.Context, db *sql.DB, userID int, username, email, bio string) error {
+func (s *PostgresStore) UpdateUserProfile(ctx context.Context, userID int, username, email, bio string) error {
 	query := `
 		UPDATE users
 		SET username = $1, email = $2, bio = $3, updated_at = $4
 		WHERE id = $5
 	`
 
-	_, err := db.ExecContext(ctx, query, username, email, bio, time.Now(), userID)
+	_, err := s.db.ExecContext(ctx, query, username, email, bio, time.Now(), userID)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
 			if pqErr.Constraint == "users_username_key" {
@@ -124,19 +124,19 @@ func UpdateUserProfile(ctx context.Context, db *sql.DB, userID int, username, em
 }
 
 // UpdateUserPassword updates user's password hash
-func UpdateUserPassword(ctx context.Context, db *sql.DB, userID int, passwordHash string) error {
+func (s *PostgresStore) UpdateUserPassword(ctx context.Context, userID int, passwordHash string) error {
 	query := `
 		UPDATE users
 		SET password_hash = $1, updated_at = $2
 		WHERE id = $3
 	`
 
-	_, err := db.ExecContext(ctx, query, passwordHash, time.Now(), userID)
+	_, err := s.db.ExecContext(ctx, query, passwordHash, time.Now(), userID)
 	return err
 }
 
 // UpdateUserPrivacy updates user's privacy settings
-func UpdateUserPrivacy(ctx context.Context, db *sql.DB, userID int, settings *models.PrivacySettings) error {
+func (s *PostgresStore) UpdateUserPrivacy(ctx context.Context, userID int, settings *models.PrivacySettings) error {
 	query := `
 		UPDATE users
 		SET privacy_settings = $1, updated_at = $2
@@ -148,6 +148,6 @@ func UpdateUserPrivacy(ctx context.Context, db *sql.DB, userID int, settings *mo
 		return err
 	}
 
-	_, err = db.ExecContext(ctx, query, settingsJSON, time.Now(), userID)
+	_, err = s.db.ExecContext(ctx, query, settingsJSON, time.Now(), userID)
 	return err
 }
@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+const webhookColumns = `w.webhook_id, w.user_id, w.url, w.secret, w.events, w.active, w.created_at, w.updated_at`
+
+// scanWebhook reads a row selected with webhookColumns
+func scanWebhook(row rowScanner) (models.Webhook, error) {
+	var hook models.Webhook
+	err := row.Scan(&hook.WebhookID, &hook.UserID, &hook.URL, &hook.Secret, pq.Array(&hook.Events),
+		&hook.Active, &hook.CreatedAt, &hook.UpdatedAt)
+	return hook, err
+}
+
+const deliveryColumns = `d.delivery_id, d.webhook_id, d.event, d.payload, d.status, d.attempts,
+		       d.next_attempt_at, d.last_attempt_at, d.response_status, d.error, d.created_at`
+
+// scanDelivery reads a row selected with deliveryColumns
+func scanDelivery(row rowScanner, extra ...interface{}) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	dest := []interface{}{&d.DeliveryID, &d.WebhookID, &d.Event, (*[]byte)(&d.Payload), &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.Error, &d.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	return d, err
+}
+
+// CreateWebhook stores a new webhook, setting its ID and timestamps
+func (s *PostgresStore) CreateWebhook(ctx context.Context, hook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, events, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING webhook_id, created_at, updated_at
+	`
+
+	return s.db.QueryRowContext(ctx, query, hook.UserID, hook.URL, hook.Secret, pq.Array(hook.Events), hook.Active).
+		Scan(&hook.WebhookID, &hook.CreatedAt, &hook.UpdatedAt)
+}
+
+// GetWebhooksByUserID retrieves a user's webhooks, oldest first
+func (s *PostgresStore) GetWebhooksByUserID(ctx context.Context, userID int) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks w WHERE w.user_id = $1 ORDER BY w.webhook_id`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := make([]models.Webhook, 0)
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// GetWebhook retrieves one of a user's webhooks
+func (s *PostgresStore) GetWebhook(ctx context.Context, userID, webhookID int) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks w WHERE w.webhook_id = $1 AND w.user_id = $2`
+
+	hook, err := scanWebhook(s.db.QueryRowContext(ctx, query, webhookID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &hook, nil
+}
+
+// UpdateWebhook changes a webhook's URL, events and active flag. The secret
+// is never changed.
+func (s *PostgresStore) UpdateWebhook(ctx context.Context, hook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $3, events = $4, active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE webhook_id = $1 AND user_id = $2
+		RETURNING updated_at
+	`
+
+	err := s.db.QueryRowContext(ctx, query, hook.WebhookID, hook.UserID, hook.URL, pq.Array(hook.Events), hook.Active).
+		Scan(&hook.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+// DeleteWebhook removes one of a user's webhooks along with its deliveries
+func (s *PostgresStore) DeleteWebhook(ctx context.Context, userID, webhookID int) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE webhook_id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueWebhookEvent queues a delivery of payload to each of the user's
+// active webhooks subscribed to event, due at now, and returns how many
+// were queued
+func (s *PostgresStore) EnqueueWebhookEvent(ctx context.Context, userID int, event string, payload []byte, now time.Time) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, next_attempt_at)
+		SELECT w.webhook_id, $2, $3, $4
+		FROM webhooks w
+		WHERE w.user_id = $1 AND w.active = true AND $2 = ANY(w.events)
+	`
+
+	result, err := s.db.ExecContext(ctx, query, userID, event, string(payload), now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	queued, err := result.RowsAffected()
+	return int(queued), err
+}
+
+// GetWebhookDeliveries retrieves up to limit of a webhook's deliveries, newest first
+func (s *PostgresStore) GetWebhookDeliveries(ctx context.Context, webhookID, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		ORDER BY d.delivery_id DESC
+		LIMIT $2
+	`
+
+	return s.queryDeliveries(ctx, query, webhookID, limit)
+}
+
+// RedeliverWebhook queues a new delivery of an earlier delivery's event and
+// payload, due at now
+func (s *PostgresStore) RedeliverWebhook(ctx context.Context, webhookID, deliveryID int, now time.Time) (*models.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, next_attempt_at)
+		SELECT webhook_id, event, payload, $3
+		FROM webhook_deliveries
+		WHERE delivery_id = $2 AND webhook_id = $1
+		RETURNING delivery_id, webhook_id, event, payload, status, attempts,
+		          next_attempt_at, last_attempt_at, response_status, error, created_at
+	`
+
+	d, err := scanDelivery(s.db.QueryRowContext(ctx, query, webhookID, deliveryID, now.UTC()))
+	if err == sql.ErrNoRows {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// ClaimWebhookDeliveries retrieves up to limit pending deliveries to active
+// webhooks that are due at now, earliest first, and pushes their next
+// attempt back to leaseUntil so that other workers skip them while they
+// are being sent
+func (s *PostgresStore) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhooks w
+		WHERE w.webhook_id = d.webhook_id AND d.delivery_id IN (
+			SELECT pd.delivery_id
+			FROM webhook_deliveries pd
+			JOIN webhooks pw ON pw.webhook_id = pd.webhook_id
+			WHERE pd.status = 'pending' AND pd.next_attempt_at <= $1 AND pw.active = true
+			ORDER BY pd.next_attempt_at
+			LIMIT $3
+			FOR UPDATE OF pd SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns + `, w.url, w.secret
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, now.UTC(), leaseUntil.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		var url, secret string
+		d, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		d.URL, d.Secret = url, secret
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// SaveWebhookDeliveryAttempt records the outcome of sending a delivery
+func (s *PostgresStore) SaveWebhookDeliveryAttempt(ctx context.Context, d *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_attempt_at = $5, response_status = $6, error = $7
+		WHERE delivery_id = $1
+	`
+
+	result, err := s.db.ExecContext(ctx, query, d.DeliveryID, d.Status, d.Attempts,
+		utcOrNil(d.NextAttemptAt), utcOrNil(d.LastAttemptAt), d.ResponseStatus, d.Error)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// queryDeliveries runs a query selecting deliveryColumns
+func (s *PostgresStore) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]models.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]models.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// utcOrNil converts an optional time to UTC for storage
+func utcOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
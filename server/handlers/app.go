@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// BookTracker records the books quotes are taken from and schedules
+// catalog lookups for them
+type BookTracker interface {
+	TrackBook(ctx context.Context, userID int, title, author string)
+	Enqueue(ctx context.Context, bookID int)
+}
+
+// App holds the dependencies shared by the HTTP handlers. Quotes, users,
+// reset tokens, feed filters, likes, follows, digests, reviews and webhooks go
+// through the store interfaces; books, collections and share links still query DB directly.
+type App struct {
+	DB           *sql.DB
+	Quotes       database.QuoteStore
+	Users        database.UserStore
+	Tokens       database.TokenStore
+	Filters      database.FeedFilterStore
+	Interactions database.InteractionStore
+	Follows      database.FollowStore
+	Digests      database.DigestStore
+	Reviews      database.ReviewStore
+	Webhooks     database.WebhookStore
+
+	Email     *services.EmailService
+	Gemini    *services.GeminiService
+	Catalog   services.BookCatalog
+	Enricher  BookTracker
+	Cards     *services.QuoteCardRenderer
+	Readiness *Readiness
+	Device    config.Device
+
+	// WebhookGuard rejects webhook URLs that point at internal addresses
+	WebhookGuard services.WebhookGuard
+
+	// AppURL is the public base URL used for absolute links in shared pages,
+	// feeds and embeds
+	AppURL string
+
+	// TrashRetention is how long deleted quotes stay restorable
+	TrashRetention time.Duration
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+const (
+	testPassword    = "Password123"
+	testDeviceToken = "device-token"
+
+	// testDeviceUserID is the ID given to the first user to sign up
+	testDeviceUserID = 1
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeBookTracker records tracked books instead of touching the database
+type fakeBookTracker struct {
+	mu     sync.Mutex
+	titles []string
+}
+
+func (f *fakeBookTracker) TrackBook(ctx context.Context, userID int, title, author string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.titles = append(f.titles, title)
+}
+
+func (f *fakeBookTracker) Enqueue(ctx context.Context, bookID int) {}
+
+// testEnv is an App backed by the in-memory store and served over HTTP
+type testEnv struct {
+	t      *testing.T
+	app    *App
+	store  *database.MemoryStore
+	books  *fakeBookTracker
+	server *httptest.Server
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	store := database.NewMemoryStore()
+	books := &fakeBookTracker{}
+	app := &App{
+		Quotes:       store,
+		Users:        store,
+		Tokens:       store,
+		Filters:      store,
+		Interactions: store,
+		Follows:      store,
+		Digests:      store,
+		Reviews:      store,
+		Webhooks:     store,
+		Email:        services.NewEmailService(config.SMTP{}, "http://zetl.test"),
+		Enricher:     books,
+		Readiness:    &Readiness{},
+		Device:       config.Device{APIToken: testDeviceToken, UserID: testDeviceUserID},
+
+		// Webhook receivers run on localhost and example.com can't be resolved offline
+		WebhookGuard: services.WebhookGuard{AllowPrivate: true},
+
+		TrashRetention: 30 * 24 * time.Hour,
+	}
+
+	r := gin.New()
+	r.Use(sessions.Sessions("zetl_session", cookie.NewStore([]byte("test-session-secret"))))
+	r.SetFuncMap(template.FuncMap{"join": strings.Join})
+	r.LoadHTMLGlob("../../client/templates/*.html")
+	app.Routes(r)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return &testEnv{t: t, app: app, store: store, books: books, server: server}
+}
+
+// testClient is a browser-like client with its own session cookie
+type testClient struct {
+	env    *testEnv
+	http   *http.Client
+	header http.Header
+}
+
+func (e *testEnv) client() *testClient {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	return &testClient{env: e, http: &http.Client{Jar: jar}, header: http.Header{}}
+}
+
+// do sends body as JSON and decodes the JSON response
+func (c *testClient) do(method, path string, body interface{}) (int, map[string]interface{}) {
+	t := c.env.t
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.env.server.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && err != io.EOF {
+		t.Fatalf("%s %s: decoding response: %v", method, path, err)
+	}
+	return resp.StatusCode, out
+}
+
+// fetch sends a GET with extra headers and returns the response with its
+// body read, for endpoints that don't answer in JSON
+func (c *testClient) fetch(path string, header http.Header) (*http.Response, string) {
+	t := c.env.t
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, c.env.server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if host := header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+// signup registers a user, leaving the client logged in, and returns the user ID
+func (c *testClient) signup(username string) int {
+	t := c.env.t
+	t.Helper()
+
+	status, body := c.do(http.MethodPost, "/auth/signup", gin.H{
+		"username": username,
+		"email":    username + "@example.com",
+		"password": testPassword,
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("signup %s: status %d, body %v", username, status, body)
+	}
+	return int(body["user"].(map[string]interface{})["id"].(float64))
+}
+
+// createQuote creates a quote as the logged-in user and returns its ID
+func (c *testClient) createQuote(quote string) int {
+	t := c.env.t
+	t.Helper()
+
+	status, body := c.do(http.MethodPost, "/api/quote", gin.H{
+		"quote":  quote,
+		"author": "Marcus Aurelius",
+		"book":   "Meditations",
+		"tags":   []string{"stoicism"},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("create quote: status %d, body %v", status, body)
+	}
+	return int(body["quote_id"].(float64))
+}
+
+func expectStatus(t *testing.T, what string, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("%s: status %d, want %d", what, got, want)
+	}
+}
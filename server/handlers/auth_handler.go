@@ -1,171 +1,164 @@
 package handlers
 
 import (
-	"database/sql"
 	"errors"
-	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/metrics"
 	"github.com/zach-monroe/zetl/server/models"
 	"github.com/zach-monroe/zetl/server/services"
 )
 
 // SignupHandler handles user registration
-func SignupHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req models.SignupRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		// Validate username
-		if err := services.ValidateUsername(req.Username); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+func (a *App) SignupHandler(c *gin.Context) {
+	var req models.SignupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Validate email
-		if err := services.ValidateEmail(req.Email); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	// Validate username
+	if err := services.ValidateUsername(req.Username); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Validate password
-		if err := services.ValidatePassword(req.Password); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	// Validate email
+	if err := services.ValidateEmail(req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Hash password
-		hashedPassword, err := services.HashPassword(req.Password)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
-			return
-		}
+	// Validate password
+	if err := services.ValidatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Create user
-		user := &models.User{
-			Username:     req.Username,
-			Email:        req.Email,
-			PasswordHash: hashedPassword,
-			IsActive:     true,
-		}
+	// Hash password
+	hashedPassword, err := services.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
 
-		if err := database.CreateUser(c.Request.Context(), db, user); err != nil {
-			if errors.Is(err, database.ErrUsernameExists) {
-				c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
-			} else if errors.Is(err, database.ErrEmailExists) {
-				c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-			}
-			return
-		}
+	// Create user
+	user := &models.User{
+		Username:     req.Username,
+		Email:        req.Email,
+		PasswordHash: hashedPassword,
+		IsActive:     true,
+	}
 
-		// Create session
-		if err := CreateUserSession(c, user.ID); err != nil {
-			log.Printf("[Signup] Failed to create session: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
-			return
+	if err := a.Users.CreateUser(c.Request.Context(), user); err != nil {
+		if errors.Is(err, database.ErrUsernameExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		} else if errors.Is(err, database.ErrEmailExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		}
+		return
+	}
+	metrics.SignupsTotal.Inc()
 
-		c.JSON(http.StatusCreated, gin.H{
-			"message": "User created successfully",
-			"user":    user.ToResponse(),
-		})
+	// Create session
+	if err := CreateUserSession(c, user.ID); err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to create session after signup", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
 	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User created successfully",
+		"user":    user.ToResponse(),
+	})
 }
 
 // LoginHandler handles user authentication
-func LoginHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req models.LoginRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+func (a *App) LoginHandler(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Try to find user by username or email
-		var user *models.User
-		var err error
+	// Try to find user by username or email
+	var user *models.User
+	var err error
 
-		// Check if it's an email (contains @)
-		if strings.Contains(req.UsernameOrEmail, "@") {
-			user, err = database.GetUserByEmail(c.Request.Context(), db, req.UsernameOrEmail)
-		} else {
-			user, err = database.GetUserByUsername(c.Request.Context(), db, req.UsernameOrEmail)
-		}
-
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-			return
-		}
+	// Check if it's an email (contains @)
+	if strings.Contains(req.UsernameOrEmail, "@") {
+		user, err = a.Users.GetUserByEmail(c.Request.Context(), req.UsernameOrEmail)
+	} else {
+		user, err = a.Users.GetUserByUsername(c.Request.Context(), req.UsernameOrEmail)
+	}
 
-		// Check if user is active
-		if !user.IsActive {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is inactive"})
-			return
-		}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
 
-		// Verify password
-		if err := services.VerifyPassword(user.PasswordHash, req.Password); err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-			return
-		}
+	// Check if user is active
+	if !user.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is inactive"})
+		return
+	}
 
-		// Update last login
-		if err := database.UpdateLastLogin(c.Request.Context(), db, user.ID); err != nil {
-			log.Printf("[Login Debug] Failed to update last_login: %v", err)
-		}
+	// Verify password
+	if err := services.VerifyPassword(user.PasswordHash, req.Password); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
 
-		// Create session
-		if err := CreateUserSession(c, user.ID); err != nil {
-			log.Printf("[Login] Failed to create session: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
-			return
-		}
+	// Update last login
+	if err := a.Users.UpdateLastLogin(c.Request.Context(), user.ID); err != nil {
+		logging.FromContext(c.Request.Context()).Warn("failed to update last login", "user_id", user.ID, "error", err)
+	}
 
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Login successful",
-			"user":    user.ToResponse(),
-		})
+	// Create session
+	if err := CreateUserSession(c, user.ID); err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to create session after login", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login successful",
+		"user":    user.ToResponse(),
+	})
 }
 
 // LogoutHandler handles user logout
-func LogoutHandler() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		session := sessions.Default(c)
-		session.Clear()
-		if err := session.Save(); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+func (a *App) LogoutHandler(c *gin.Context) {
+	session := sessions.Default(c)
+	session.Clear()
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
 }
 
 // GetCurrentUserHandler returns the currently logged in user
-func GetCurrentUserHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Not logged in"})
-			return
-		}
-
-		user, err := database.GetUserByID(c.Request.Context(), db, userID.(int))
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
+func (a *App) GetCurrentUserHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not logged in"})
+		return
+	}
 
-		c.JSON(http.StatusOK, gin.H{"user": user.ToResponse()})
+	user, err := a.Users.GetUserByID(c.Request.Context(), userID.(int))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user.ToResponse()})
 }
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSignupLogsIn(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+
+	id := alice.signup("alice")
+
+	status, body := alice.do(http.MethodGet, "/api/user", nil)
+	expectStatus(t, "current user", status, http.StatusOK)
+	if got := int(body["user"].(map[string]interface{})["id"].(float64)); got != id {
+		t.Fatalf("current user id = %d, want %d", got, id)
+	}
+}
+
+func TestSignupRejectsDuplicates(t *testing.T) {
+	env := newTestEnv(t)
+	env.client().signup("alice")
+
+	tests := []struct {
+		name     string
+		username string
+		email    string
+	}{
+		{"username", "alice", "other@example.com"},
+		{"email", "other", "alice@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := env.client().do(http.MethodPost, "/auth/signup", gin.H{
+				"username": tt.username,
+				"email":    tt.email,
+				"password": testPassword,
+			})
+			expectStatus(t, "duplicate signup", status, http.StatusConflict)
+		})
+	}
+}
+
+func TestSignupValidatesPassword(t *testing.T) {
+	env := newTestEnv(t)
+
+	status, _ := env.client().do(http.MethodPost, "/auth/signup", gin.H{
+		"username": "alice",
+		"email":    "alice@example.com",
+		"password": "alllowercase",
+	})
+	expectStatus(t, "weak password", status, http.StatusBadRequest)
+}
+
+func TestLogin(t *testing.T) {
+	env := newTestEnv(t)
+	env.client().signup("alice")
+
+	tests := []struct {
+		name       string
+		login      string
+		password   string
+		wantStatus int
+	}{
+		{"username", "alice", testPassword, http.StatusOK},
+		{"email", "alice@example.com", testPassword, http.StatusOK},
+		{"wrong password", "alice", "Wrong123", http.StatusUnauthorized},
+		{"unknown user", "bob", testPassword, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := env.client()
+			status, _ := client.do(http.MethodPost, "/auth/login", gin.H{
+				"username_or_email": tt.login,
+				"password":          tt.password,
+			})
+			expectStatus(t, "login", status, tt.wantStatus)
+
+			wantUser := http.StatusUnauthorized
+			if tt.wantStatus == http.StatusOK {
+				wantUser = http.StatusOK
+			}
+			status, _ = client.do(http.MethodGet, "/api/user", nil)
+			expectStatus(t, "current user after login", status, wantUser)
+		})
+	}
+}
+
+func TestLogoutEndsSession(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+
+	status, _ := alice.do(http.MethodPost, "/auth/logout", nil)
+	expectStatus(t, "logout", status, http.StatusOK)
+
+	status, _ = alice.do(http.MethodGet, "/api/user", nil)
+	expectStatus(t, "current user after logout", status, http.StatusUnauthorized)
+}
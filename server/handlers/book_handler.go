@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// GetBooksHandler returns the current user's books with any pending matches
+func (a *App) GetBooksHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	books, err := database.GetBooksByUserID(c.Request.Context(), a.DB, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch books"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"books": books})
+}
+
+// GetBookHandler returns a single book with its candidate matches
+func (a *App) GetBookHandler(c *gin.Context) {
+	// Get book_id from context (set by BookOwnershipRequired middleware)
+	bookID, exists := c.Get("book_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID"})
+		return
+	}
+
+	book, err := database.GetBookByID(c.Request.Context(), a.DB, bookID.(int))
+	if err != nil {
+		if errors.Is(err, database.ErrBookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"book": book})
+}
+
+// ConfirmBookHandler applies a chosen candidate match, or looks up an ISBN
+// supplied by the user, and stores its metadata on the book
+func (a *App) ConfirmBookHandler(c *gin.Context) {
+	bookID, exists := c.Get("book_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID"})
+		return
+	}
+
+	var req models.ConfirmBookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.CandidateID == 0 && req.ISBN == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "candidate_id or isbn is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	book, err := database.GetBookByID(ctx, a.DB, bookID.(int))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		return
+	}
+
+	var match *models.BookCandidate
+	if req.CandidateID != 0 {
+		for i := range book.Candidates {
+			if book.Candidates[i].CandidateID == req.CandidateID {
+				match = &book.Candidates[i]
+				break
+			}
+		}
+		if match == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Candidate does not belong to this book"})
+			return
+		}
+	} else {
+		if services.NormalizeISBN(req.ISBN) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ISBN"})
+			return
+		}
+
+		meta, err := a.Catalog.LookupISBN(ctx, req.ISBN)
+		if err != nil {
+			if errors.Is(err, services.ErrBookNotInCatalog) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "No catalog record for that ISBN"})
+			} else {
+				c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to look up ISBN"})
+			}
+			return
+		}
+		candidate := services.MetadataToCandidate(*meta)
+		match = &candidate
+	}
+
+	if err := database.ApplyBookMetadata(ctx, a.DB, book.BookID, *match); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update book"})
+		return
+	}
+
+	updated, err := database.GetBookByID(ctx, a.DB, book.BookID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch book"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Book updated successfully", "book": updated})
+}
+
+// RefreshBookHandler re-queues a book for a fresh catalog lookup
+func (a *App) RefreshBookHandler(c *gin.Context) {
+	bookID, exists := c.Get("book_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID"})
+		return
+	}
+
+	if err := database.SetBookStatus(c.Request.Context(), a.DB, bookID.(int), models.BookStatusPending); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh book"})
+		return
+	}
+
+	a.Enricher.Enqueue(c.Request.Context(), bookID.(int))
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Book lookup queued"})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/metrics"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+type BulkQuoteRequest struct {
+	Action       string   `json:"action" binding:"required,oneof=add_tags remove_tags set_visibility move_to_collection delete restore"`
+	QuoteIDs     []int    `json:"quote_ids" binding:"required,min=1,max=500,dive,gt=0"`
+	Tags         []string `json:"tags"`
+	Visibility   string   `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	CollectionID int      `json:"collection_id"`
+}
+
+// BulkQuotesHandler applies one action to many of the current user's quotes
+// in a single transaction and reports the outcome for each quote ID
+func (a *App) BulkQuotesHandler(c *gin.Context) {
+	var req BulkQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	op := models.BulkQuoteOperation{
+		Action:       req.Action,
+		Visibility:   req.Visibility,
+		CollectionID: req.CollectionID,
+	}
+	for _, id := range req.QuoteIDs {
+		if !slices.Contains(op.QuoteIDs, id) {
+			op.QuoteIDs = append(op.QuoteIDs, id)
+		}
+	}
+	for _, tag := range req.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			op.Tags = append(op.Tags, tag)
+		}
+	}
+
+	switch {
+	case (op.Action == models.BulkAddTags || op.Action == models.BulkRemoveTags) && len(op.Tags) == 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tags are required"})
+		return
+	case op.Action == models.BulkSetVisibility && op.Visibility == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Visibility is required"})
+		return
+	case op.Action == models.BulkMoveToCollection && op.CollectionID <= 0:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Collection ID is required"})
+		return
+	}
+
+	results, err := a.Quotes.BulkUpdateQuotes(c.Request.Context(), c.GetInt("user_id"), op)
+	if err != nil {
+		if errors.Is(err, database.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quotes"})
+		}
+		return
+	}
+
+	var changed []int
+	for _, r := range results {
+		if r.Status == models.BulkResultOK {
+			changed = append(changed, r.QuoteID)
+		}
+	}
+	updated := len(changed)
+	if op.Action == models.BulkDelete {
+		metrics.QuotesDeletedTotal.Add(float64(updated))
+	}
+	if event := bulkWebhookEvent(op.Action); event != "" {
+		a.emitQuoteEvents(c, event, changed)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated, "results": results})
+}
+
+// bulkWebhookEvent is the webhook event announcing each quote changed by a
+// bulk action. Moving quotes into a collection doesn't change the quotes.
+func bulkWebhookEvent(action string) string {
+	switch action {
+	case models.BulkAddTags, models.BulkRemoveTags, models.BulkSetVisibility:
+		return models.WebhookQuoteUpdated
+	case models.BulkDelete:
+		return models.WebhookQuoteDeleted
+	case models.BulkRestore:
+		return models.WebhookQuoteRestored
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bulkStatuses maps each quote ID in a bulk response to its status
+func bulkStatuses(body map[string]interface{}) map[int]string {
+	statuses := make(map[int]string)
+	for _, r := range body["results"].([]interface{}) {
+		result := r.(map[string]interface{})
+		statuses[int(result["quote_id"].(float64))] = result["status"].(string)
+	}
+	return statuses
+}
+
+func TestBulkTags(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	first := alice.createQuote("first")
+	second := alice.createQuote("second")
+
+	bob := env.client()
+	bob.signup("bob")
+	bobs := bob.createQuote("bob's")
+
+	status, body := alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":    "add_tags",
+		"quote_ids": []int{first, second, bobs, 9999},
+		"tags":      []string{"favorites", "stoicism"},
+	})
+	expectStatus(t, "add tags", status, http.StatusOK)
+
+	want := map[int]string{first: "ok", second: "ok", bobs: "not_found", 9999: "not_found"}
+	for id, s := range bulkStatuses(body) {
+		if want[id] != s {
+			t.Errorf("quote %d: status %q, want %q", id, s, want[id])
+		}
+	}
+
+	ctx := context.Background()
+	q, _ := env.store.GetQuoteByID(ctx, first)
+	if !slices.Equal(q.Tags, []string{"stoicism", "favorites"}) {
+		t.Fatalf("tags after add = %v", q.Tags)
+	}
+	if q, _ := env.store.GetQuoteByID(ctx, bobs); slices.Contains(q.Tags, "favorites") {
+		t.Fatalf("bob's quote was tagged: %v", q.Tags)
+	}
+
+	status, _ = alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":    "remove_tags",
+		"quote_ids": []int{first},
+		"tags":      []string{"stoicism"},
+	})
+	expectStatus(t, "remove tags", status, http.StatusOK)
+
+	q, _ = env.store.GetQuoteByID(ctx, first)
+	if !slices.Equal(q.Tags, []string{"favorites"}) {
+		t.Fatalf("tags after remove = %v", q.Tags)
+	}
+
+	revisions, _ := env.store.GetQuoteRevisions(ctx, first)
+	if len(revisions) != 2 {
+		t.Fatalf("got %d revisions, want one per tag change", len(revisions))
+	}
+}
+
+func TestBulkDeleteAndRestore(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	first := alice.createQuote("first")
+	second := alice.createQuote("second")
+
+	status, body := alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":    "delete",
+		"quote_ids": []int{first, second, first},
+	})
+	expectStatus(t, "bulk delete", status, http.StatusOK)
+	if body["updated"].(float64) != 2 {
+		t.Fatalf("updated = %v, want 2", body["updated"])
+	}
+
+	_, body = alice.do(http.MethodGet, "/api/trash", nil)
+	if trashed := body["quotes"].([]interface{}); len(trashed) != 2 {
+		t.Fatalf("got %d trashed quotes, want 2", len(trashed))
+	}
+
+	status, body = alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":    "restore",
+		"quote_ids": []int{first},
+	})
+	expectStatus(t, "bulk restore", status, http.StatusOK)
+	if s := bulkStatuses(body)[first]; s != "ok" {
+		t.Fatalf("restore status = %q", s)
+	}
+
+	_, body = alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":    "delete",
+		"quote_ids": []int{second},
+	})
+	if s := bulkStatuses(body)[second]; s != "not_found" {
+		t.Fatalf("deleting a trashed quote: status %q, want not_found", s)
+	}
+}
+
+func TestBulkSetVisibility(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("secret")
+
+	status, _ := alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":    "set_visibility",
+		"quote_ids": []int{quoteID},
+	})
+	expectStatus(t, "missing visibility", status, http.StatusBadRequest)
+
+	status, _ = alice.do(http.MethodPost, "/api/quotes/bulk", gin.H{
+		"action":     "set_visibility",
+		"quote_ids":  []int{quoteID},
+		"visibility": "private",
+	})
+	expectStatus(t, "set visibility", status, http.StatusOK)
+
+	q, _ := env.store.GetQuoteByID(context.Background(), quoteID)
+	if q.Visibility != "private" {
+		t.Fatalf("visibility = %q, want private", q.Visibility)
+	}
+}
+
+func TestBulkValidation(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+
+	tests := []struct {
+		name string
+		body gin.H
+	}{
+		{"unknown action", gin.H{"action": "archive", "quote_ids": []int{1}}},
+		{"no ids", gin.H{"action": "delete", "quote_ids": []int{}}},
+		{"tags missing", gin.H{"action": "add_tags", "quote_ids": []int{1}, "tags": []string{" "}}},
+		{"collection missing", gin.H{"action": "move_to_collection", "quote_ids": []int{1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := alice.do(http.MethodPost, "/api/quotes/bulk", tt.body)
+			expectStatus(t, tt.name, status, http.StatusBadRequest)
+		})
+	}
+}
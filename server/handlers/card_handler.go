@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// QuoteCardHandler renders a quote as a PNG image card.
+// Query params: ?theme= (dark, light, sepia), ?aspect= (square, story, og) and
+// ?share= to grant access through an active share link for private quotes.
+func (a *App) QuoteCardHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	// Validate before the conditional check so a bad query never gets a 304
+	theme := c.DefaultQuery("theme", services.DefaultCardTheme)
+	aspect := c.DefaultQuery("aspect", services.DefaultCardAspect)
+	if err := services.ValidateCardOptions(theme, aspect); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	quote, err := a.Quotes.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		}
+		return
+	}
+
+	public := quote.IsLinkable()
+	if !public && !a.canViewQuote(c, quote) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+
+	card := services.QuoteCard{Quote: quote.Quote, Author: quote.Author, Book: quote.Book}
+
+	etag := `"` + services.CardKey(card, theme, aspect) + `"`
+	cacheControl := fmt.Sprintf("private, max-age=%d", config.QuoteCardMaxAge)
+	if public {
+		cacheControl = fmt.Sprintf("public, max-age=%d", config.QuoteCardMaxAge)
+	}
+	c.Header("Cache-Control", cacheControl)
+	c.Header("ETag", etag)
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	data, _, err := a.Cards.Render(card, theme, aspect)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render card"})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+// canViewQuote reports whether the request may see a private quote, either
+// because the viewer owns it or because ?share= holds a link that covers it
+func (a *App) canViewQuote(c *gin.Context, quote *models.Quote) bool {
+	if viewerID, _ := c.Get("user_id"); viewerID == quote.UserID {
+		return true
+	}
+
+	token := c.Query("share")
+	if token == "" {
+		return false
+	}
+
+	ctx := c.Request.Context()
+	link, err := database.GetActiveShareLink(ctx, a.DB, token)
+	if err != nil {
+		return false
+	}
+	if link.QuoteID != nil {
+		return *link.QuoteID == quote.QuoteID
+	}
+
+	contains, err := database.CollectionContainsQuote(ctx, a.DB, *link.CollectionID, quote.QuoteID)
+	return err == nil && contains
+}
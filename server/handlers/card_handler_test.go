@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+func TestQuoteCardValidatesBeforeConditionalRequest(t *testing.T) {
+	env := newTestEnv(t)
+	cards, err := services.NewQuoteCardRenderer(config.QuoteCardCacheSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.app.Cards = cards
+
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("You have power over your mind")
+	path := "/quote/" + strconv.Itoa(quoteID) + "/card.png"
+
+	// A matching ETag for a valid card is answered without rendering
+	resp, _ := alice.fetch(path+"?theme=light", nil)
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatalf("card response has no ETag (status %d)", resp.StatusCode)
+	}
+	resp, _ = alice.fetch(path+"?theme=light", http.Header{"If-None-Match": {etag}})
+	expectStatus(t, "conditional card", resp.StatusCode, http.StatusNotModified)
+
+	// Invalid options are rejected even when the client sends their ETag
+	card := services.QuoteCard{Quote: "You have power over your mind", Author: "Marcus Aurelius", Book: "Meditations"}
+	tests := []struct {
+		theme, aspect string
+	}{
+		{"neon", services.DefaultCardAspect},
+		{services.DefaultCardTheme, "banner"},
+	}
+	for _, tt := range tests {
+		query := "?theme=" + tt.theme + "&aspect=" + tt.aspect
+		etag := `"` + services.CardKey(card, tt.theme, tt.aspect) + `"`
+		resp, _ := alice.fetch(path+query, http.Header{"If-None-Match": {etag}})
+		expectStatus(t, query, resp.StatusCode, http.StatusBadRequest)
+	}
+}
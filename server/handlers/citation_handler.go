@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// GetCitationHandler renders a quote's citation in MLA, APA and Chicago styles.
+// Pass ?style= to render a single style. Private quotes are only visible to their owner.
+func (a *App) GetCitationHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	quote, err := a.Quotes.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		}
+		return
+	}
+
+	ownerID := quote.UserID
+	if viewerID, _ := c.Get("user_id"); viewerID != ownerID && !quote.IsLinkable() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+
+	src := services.CitationSource{
+		Author:   quote.Author,
+		Title:    quote.Book,
+		Citation: quote.Citation,
+	}
+	if book, err := database.GetBookByTitle(ctx, a.DB, ownerID, src.Title); err == nil && book.PublishYear != nil {
+		src.PublishYear = *book.PublishYear
+	}
+
+	styles := services.CitationStyles
+	if style := strings.ToLower(c.Query("style")); style != "" {
+		styles = []string{style}
+	}
+
+	citations := make([]*services.FormattedCitation, 0, len(styles))
+	for _, style := range styles {
+		formatted, err := services.FormatCitation(style, src)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		citations = append(citations, formatted)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quote_id": quoteID, "citations": citations})
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// GetCollectionsHandler returns the current user's collections
+func (a *App) GetCollectionsHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	collections, err := database.GetCollectionsByUserID(c.Request.Context(), a.DB, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collections": collections})
+}
+
+// CreateCollectionHandler handles creating a new collection
+func (a *App) CreateCollectionHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var req models.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	col := &models.Collection{
+		UserID:      userID.(int),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Visibility:  req.Visibility,
+	}
+	if col.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if col.Visibility == "" {
+		col.Visibility = models.VisibilityInherit
+	}
+
+	if err := database.CreateCollection(c.Request.Context(), a.DB, col); err != nil {
+		if errors.Is(err, database.ErrCollectionExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A collection with that name already exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Collection created successfully",
+		"collection": col,
+	})
+}
+
+// GetCollectionHandler returns a collection with its quotes in order
+func (a *App) GetCollectionHandler(c *gin.Context) {
+	// Get collection_id from context (set by CollectionOwnershipRequired middleware)
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	col, err := database.GetCollectionByID(ctx, a.DB, collectionID.(int))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	col.Quotes, err = database.FetchCollectionQuotes(ctx, a.DB, col.CollectionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collection": col})
+}
+
+// UpdateCollectionHandler handles renaming a collection or changing its visibility
+func (a *App) UpdateCollectionHandler(c *gin.Context) {
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	var req models.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if req.Visibility == "" {
+		req.Visibility = models.VisibilityInherit
+	}
+
+	err := database.UpdateCollection(c.Request.Context(), a.DB, collectionID.(int), name, req.Description, req.Visibility)
+	if err != nil {
+		if errors.Is(err, database.ErrCollectionExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": "A collection with that name already exists"})
+		} else if errors.Is(err, database.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collection"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection updated successfully"})
+}
+
+// DeleteCollectionHandler handles deleting a collection
+func (a *App) DeleteCollectionHandler(c *gin.Context) {
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	if err := database.DeleteCollection(c.Request.Context(), a.DB, collectionID.(int)); err != nil {
+		if errors.Is(err, database.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete collection"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// AddCollectionQuoteHandler adds one of the user's quotes to a collection
+func (a *App) AddCollectionQuoteHandler(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	var req models.AddCollectionQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	isOwner, err := a.Quotes.VerifyQuoteOwnership(ctx, req.QuoteID, userID.(int))
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ownership"})
+		}
+		return
+	}
+	if !isOwner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only add your own quotes to a collection"})
+		return
+	}
+
+	if err := database.AddQuoteToCollection(ctx, a.DB, collectionID.(int), req.QuoteID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add quote to collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote added to collection"})
+}
+
+// RemoveCollectionQuoteHandler removes a quote from a collection
+func (a *App) RemoveCollectionQuoteHandler(c *gin.Context) {
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	quoteID, err := strconv.Atoi(c.Param("quote_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	if err := database.RemoveQuoteFromCollection(c.Request.Context(), a.DB, collectionID.(int), quoteID); err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote is not in this collection"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove quote from collection"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote removed from collection"})
+}
+
+// ReorderCollectionHandler sets the manual order of a collection's quotes
+func (a *App) ReorderCollectionHandler(c *gin.Context) {
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	var req models.ReorderCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.ReorderCollectionQuotes(c.Request.Context(), a.DB, collectionID.(int), req.QuoteIDs); err != nil {
+		if errors.Is(err, database.ErrInvalidOrder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder collection"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection reordered successfully"})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+const (
+	// defaultDigestHour is when digests go out if the user doesn't pick an hour
+	defaultDigestHour = 8
+	// defaultDigestWeekday is when weekly digests go out if the user doesn't pick a day
+	defaultDigestWeekday = time.Monday
+)
+
+// GetDigestHandler returns the current user's digest subscription, or null
+// when they aren't subscribed
+func (a *App) GetDigestHandler(c *gin.Context) {
+	sub, err := a.Digests.GetDigestSubscription(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil && !errors.Is(err, database.ErrDigestNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch digest settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": sub})
+}
+
+// UpdateDigestHandler subscribes the current user to the digest or changes its schedule
+func (a *App) UpdateDigestHandler(c *gin.Context) {
+	var req models.UpdateDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+		return
+	}
+
+	sub := &models.DigestSubscription{
+		UserID:    c.GetInt("user_id"),
+		Frequency: req.Frequency,
+		Selection: req.Selection,
+		Timezone:  req.Timezone,
+		SendHour:  defaultDigestHour,
+		Weekday:   defaultDigestWeekday,
+	}
+	if sub.Selection == "" {
+		sub.Selection = models.DigestRandom
+	}
+	if req.SendHour != nil {
+		sub.SendHour = *req.SendHour
+	}
+	if req.Weekday != nil {
+		sub.Weekday = time.Weekday(*req.Weekday)
+	}
+	sub.NextSendAt = sub.NextSend(time.Now())
+
+	if err := a.Digests.SaveDigestSubscription(c.Request.Context(), sub); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update digest settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Digest settings updated", "subscription": sub})
+}
+
+// DeleteDigestHandler turns off the current user's digest
+func (a *App) DeleteDigestHandler(c *gin.Context) {
+	if err := a.Digests.DeleteDigestSubscription(c.Request.Context(), c.GetInt("user_id")); err != nil {
+		if errors.Is(err, database.ErrDigestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You aren't subscribed to the digest"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update digest settings"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Digest turned off"})
+}
+
+// PreviewDigestHandler renders the digest the current user would receive
+// now, as HTML or, with format=text, as plain text
+func (a *App) PreviewDigestHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	user, err := a.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+
+	sub, err := a.Digests.GetDigestSubscription(ctx, userID)
+	if errors.Is(err, database.ErrDigestNotFound) {
+		sub, err = &models.DigestSubscription{UserID: userID, Frequency: models.DigestDaily, Selection: models.DigestRandom, Timezone: "UTC"}, nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch digest settings"})
+		return
+	}
+	sub.Username = user.Username
+
+	digest, err := services.ComposeDigest(ctx, a.Digests, sub, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build digest"})
+		return
+	}
+
+	text, html, err := a.Email.RenderDigest(digest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render digest"})
+		return
+	}
+
+	if c.Query("format") == "text" {
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(text))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+}
+
+// UnsubscribeDigestHandler turns off a digest from its email's unsubscribe
+// token. It needs no session so mail clients can use it for one-click
+// unsubscribe.
+func (a *App) UnsubscribeDigestHandler(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	if err := a.Digests.UnsubscribeDigest(c.Request.Context(), token); err != nil {
+		if errors.Is(err, database.ErrDigestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This unsubscribe link is no longer valid"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "You've been unsubscribed from the digest"})
+}
+
+// UnsubscribeDigestPageHandler renders the page linked from digest emails,
+// which confirms before unsubscribing so link scanners can't unsubscribe
+func (a *App) UnsubscribeDigestPageHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "digest-unsubscribe.html", gin.H{
+		"title": "Unsubscribe",
+		"token": c.Query("token"),
+	})
+}
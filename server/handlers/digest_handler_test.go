@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDigestSubscription(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+
+	_, body := alice.do(http.MethodGet, "/api/digest", nil)
+	if body["subscription"] != nil {
+		t.Fatalf("subscription before opting in = %v, want null", body["subscription"])
+	}
+
+	status, _ := alice.do(http.MethodPut, "/api/digest", gin.H{"frequency": "daily", "timezone": "Mars/Olympus"})
+	expectStatus(t, "unknown timezone", status, http.StatusBadRequest)
+	status, _ = alice.do(http.MethodPut, "/api/digest", gin.H{"frequency": "hourly", "timezone": "UTC"})
+	expectStatus(t, "invalid frequency", status, http.StatusBadRequest)
+
+	status, _ = alice.do(http.MethodPut, "/api/digest", gin.H{
+		"frequency": "weekly", "timezone": "America/New_York", "send_hour": 7, "weekday": 5, "selection": "spaced",
+	})
+	expectStatus(t, "subscribe", status, http.StatusOK)
+
+	sub, err := env.store.GetDigestSubscription(context.Background(), aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := sub.NextSendAt.In(sub.Location())
+	if next.Weekday() != time.Friday || next.Hour() != 7 || !next.After(time.Now()) {
+		t.Fatalf("next send = %v, want a future Friday at 07:00 New York time", next)
+	}
+	if sub.UnsubscribeToken == "" {
+		t.Fatal("subscription has no unsubscribe token")
+	}
+
+	// Changing the schedule keeps the unsubscribe token
+	status, _ = alice.do(http.MethodPut, "/api/digest", gin.H{"frequency": "daily", "timezone": "UTC"})
+	expectStatus(t, "update schedule", status, http.StatusOK)
+	updated, err := env.store.GetDigestSubscription(context.Background(), aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.UnsubscribeToken != sub.UnsubscribeToken || updated.SendHour != defaultDigestHour {
+		t.Fatalf("updated subscription = %+v", updated)
+	}
+
+	status, _ = alice.do(http.MethodDelete, "/api/digest", nil)
+	expectStatus(t, "turn off", status, http.StatusOK)
+	status, _ = alice.do(http.MethodDelete, "/api/digest", nil)
+	expectStatus(t, "turn off again", status, http.StatusNotFound)
+}
+
+func TestDigestUnsubscribeToken(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+
+	status, _ := alice.do(http.MethodPut, "/api/digest", gin.H{"frequency": "daily", "timezone": "UTC"})
+	expectStatus(t, "subscribe", status, http.StatusOK)
+	sub, err := env.store.GetDigestSubscription(context.Background(), aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One-click unsubscribe works without a session
+	anonymous := env.client()
+	status, _ = anonymous.do(http.MethodPost, "/digest/unsubscribe?token=wrong", nil)
+	expectStatus(t, "unknown token", status, http.StatusNotFound)
+	status, _ = anonymous.do(http.MethodPost, "/digest/unsubscribe?token="+sub.UnsubscribeToken, nil)
+	expectStatus(t, "unsubscribe", status, http.StatusOK)
+	status, _ = anonymous.do(http.MethodPost, "/digest/unsubscribe?token="+sub.UnsubscribeToken, nil)
+	expectStatus(t, "unsubscribe again", status, http.StatusNotFound)
+
+	_, body := alice.do(http.MethodGet, "/api/digest", nil)
+	if body["subscription"] != nil {
+		t.Fatalf("subscription after unsubscribing = %v, want null", body["subscription"])
+	}
+}
+
+func TestDigestPreview(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("The obstacle is the way")
+
+	// The preview is plain text, so it's fetched directly rather than via do
+	resp, err := alice.http.Get(env.server.URL + "/api/digest/preview?format=text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectStatus(t, "preview", resp.StatusCode, http.StatusOK)
+	if !strings.Contains(string(text), "Hello alice") || !strings.Contains(string(text), "The obstacle is the way") {
+		t.Fatalf("preview = %q, want a greeting and alice's quote", text)
+	}
+}
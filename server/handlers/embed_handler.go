@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// Embed widget modes
+const (
+	embedModeRandom = "random"
+	embedModeLatest = "latest"
+	embedModeQOTD   = "qotd"
+)
+
+// embedCSP only lets the widget load its own stylesheet and script, and
+// lets any site frame it
+const embedCSP = "default-src 'none'; style-src 'self'; script-src 'self'; " +
+	"base-uri 'none'; form-action 'none'; frame-ancestors *"
+
+// embedOptions are the query parameters shared by every embed
+type embedOptions struct {
+	Mode  string
+	Theme string
+	Font  string
+	Tag   string
+	// Refresh reloads the widget every Refresh seconds; 0 never reloads
+	Refresh int
+}
+
+// parseEmbedOptions reads the mode, theme, font, tag and refresh query
+// parameters, falling back to a random quote on the light theme
+func parseEmbedOptions(c *gin.Context) (embedOptions, error) {
+	opts := embedOptions{
+		Mode:  c.DefaultQuery("mode", embedModeRandom),
+		Theme: c.DefaultQuery("theme", "light"),
+		Font:  c.DefaultQuery("font", "serif"),
+		Tag:   strings.TrimSpace(c.Query("tag")),
+	}
+
+	switch opts.Mode {
+	case embedModeRandom, embedModeLatest, embedModeQOTD:
+	default:
+		return opts, errors.New("mode must be random, latest or qotd")
+	}
+	switch opts.Theme {
+	case "light", "dark", "auto":
+	default:
+		return opts, errors.New("theme must be light, dark or auto")
+	}
+	switch opts.Font {
+	case "serif", "sans":
+	default:
+		return opts, errors.New("font must be serif or sans")
+	}
+
+	if refresh := c.Query("refresh"); refresh != "" {
+		seconds, err := strconv.Atoi(refresh)
+		if err != nil || seconds < config.EmbedMinRefresh || seconds > config.EmbedMaxRefresh {
+			return opts, fmt.Errorf("refresh must be between %d and %d seconds", config.EmbedMinRefresh, config.EmbedMaxRefresh)
+		}
+		opts.Refresh = seconds
+	}
+
+	return opts, nil
+}
+
+// embedIndex picks one of count quotes, ordered oldest first, for the
+// latest and random modes. The quote of the day is picked by rank instead.
+func embedIndex(mode string, count int) int {
+	if mode == embedModeLatest {
+		return count - 1
+	}
+	return rand.IntN(count)
+}
+
+// EmbedUserHandler renders a widget showing one of a user's public quotes,
+// for use in an iframe. Users who have made their profile private can't be
+// embedded.
+func (a *App) EmbedUserHandler(c *gin.Context) {
+	opts, err := parseEmbedOptions(c)
+	if err != nil {
+		a.renderEmbed(c, http.StatusBadRequest, opts, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := a.publicUser(ctx, c.Param("username"))
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			a.renderEmbed(c, http.StatusNotFound, opts, gin.H{"message": "User not found"})
+		} else {
+			a.renderEmbed(c, http.StatusInternalServerError, opts, gin.H{"message": "Failed to fetch quote"})
+		}
+		return
+	}
+
+	// A zero ViewerID only matches public quotes
+	filter := models.QuoteFilter{UserID: user.ID, Tag: opts.Tag}
+	data := gin.H{"source": "@" + user.Username, "source_url": a.absoluteURL(c, "/u/"+url.PathEscape(user.Username))}
+
+	count, err := a.Quotes.CountFilteredQuotes(ctx, filter)
+	if err != nil {
+		a.renderEmbed(c, http.StatusInternalServerError, opts, gin.H{"message": "Failed to fetch quote"})
+		return
+	}
+	if count == 0 {
+		data["message"] = "No public quotes yet"
+		a.renderEmbed(c, http.StatusOK, opts, data)
+		return
+	}
+
+	var quote *models.Quote
+	if opts.Mode == embedModeQOTD {
+		quote, err = a.Quotes.GetDailyFilteredQuote(ctx, filter, qotdKey(today(), qotdSeed(filter)))
+	} else {
+		quote, err = a.Quotes.GetFilteredQuoteAt(ctx, filter, embedIndex(opts.Mode, count))
+	}
+	if err != nil {
+		a.renderEmbed(c, http.StatusInternalServerError, opts, gin.H{"message": "Failed to fetch quote"})
+		return
+	}
+
+	data["quote"] = quote
+	a.renderEmbed(c, http.StatusOK, opts, data)
+}
+
+// EmbedCollectionHandler renders a widget showing one of the public quotes
+// in a public collection, for use in an iframe
+func (a *App) EmbedCollectionHandler(c *gin.Context) {
+	opts, err := parseEmbedOptions(c)
+	if err != nil {
+		a.renderEmbed(c, http.StatusBadRequest, opts, gin.H{"message": err.Error()})
+		return
+	}
+
+	collectionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		a.renderEmbed(c, http.StatusNotFound, opts, gin.H{"message": "Collection not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	col, err := database.GetCollectionByID(ctx, a.DB, collectionID)
+	if err != nil {
+		a.renderEmbed(c, http.StatusNotFound, opts, gin.H{"message": "Collection not found"})
+		return
+	}
+	owner, err := a.Users.GetUserByID(ctx, col.UserID)
+	if err != nil || !owner.IsActive || !col.IsPublic(owner.PrivacySettings) {
+		a.renderEmbed(c, http.StatusNotFound, opts, gin.H{"message": "Collection not found"})
+		return
+	}
+
+	quotes, err := database.FetchCollectionQuotes(ctx, a.DB, col.CollectionID)
+	if err != nil {
+		a.renderEmbed(c, http.StatusInternalServerError, opts, gin.H{"message": "Failed to fetch quote"})
+		return
+	}
+
+	// A public collection can still hold private and unlisted quotes
+	filter := models.QuoteFilter{Tag: opts.Tag}
+	public := make(models.Quotes, 0, len(quotes))
+	for _, q := range quotes {
+		if filter.Matches(q) {
+			public = append(public, q)
+		}
+	}
+	sort.Slice(public, func(i, j int) bool { return public[i].QuoteID < public[j].QuoteID })
+
+	data := gin.H{"source": col.Name, "source_url": a.absoluteURL(c, "/collection/"+strconv.Itoa(col.CollectionID))}
+	if len(public) == 0 {
+		data["message"] = "No public quotes yet"
+		a.renderEmbed(c, http.StatusOK, opts, data)
+		return
+	}
+
+	if opts.Mode == embedModeQOTD {
+		data["quote"] = dailyQuote(qotdKey(today(), "collection|"+strconv.Itoa(col.CollectionID)+"|"+opts.Tag), public)
+	} else {
+		data["quote"] = public[embedIndex(opts.Mode, len(public))]
+	}
+	a.renderEmbed(c, http.StatusOK, opts, data)
+}
+
+// renderEmbed writes the widget page with its CSP and caching headers.
+// Random quotes and errors aren't cached so every load can differ.
+func (a *App) renderEmbed(c *gin.Context, status int, opts embedOptions, data gin.H) {
+	c.Header("Content-Security-Policy", embedCSP)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Referrer-Policy", "no-referrer")
+	if status == http.StatusOK && opts.Mode != embedModeRandom {
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", config.EmbedMaxAge))
+	} else {
+		c.Header("Cache-Control", "no-store")
+	}
+
+	data["theme"] = opts.Theme
+	data["font"] = opts.Font
+	data["refresh"] = opts.Refresh
+	data["home_url"] = a.absoluteURL(c, "/")
+	c.HTML(status, "embed.html", data)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEmbedUser(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("You have power over your mind")
+	status, _ := alice.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "A private thought", "author": "Alice", "book": "Journal", "visibility": "private",
+	})
+	expectStatus(t, "create private quote", status, http.StatusCreated)
+	alice.createQuote("The best revenge is not to be like your enemy")
+
+	// Only public quotes appear, even to their owner
+	for i := 0; i < 5; i++ {
+		resp, body := alice.fetch("/embed/alice", nil)
+		expectStatus(t, "random embed", resp.StatusCode, http.StatusOK)
+		if strings.Contains(body, "A private thought") {
+			t.Fatalf("embed shows a private quote:\n%s", body)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != "no-store" {
+			t.Fatalf("random embed Cache-Control = %q, want no-store", got)
+		}
+	}
+
+	reader := env.client()
+	resp, body := reader.fetch("/embed/alice?mode=latest&theme=dark&refresh=60", nil)
+	expectStatus(t, "latest embed", resp.StatusCode, http.StatusOK)
+	if !strings.Contains(body, "The best revenge is not to be like your enemy") ||
+		!strings.Contains(body, `class="theme-dark font-serif"`) || !strings.Contains(body, `data-refresh="60"`) {
+		t.Fatalf("latest embed =\n%s", body)
+	}
+	if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") || !strings.Contains(csp, "frame-ancestors *") {
+		t.Fatalf("embed CSP = %q", csp)
+	}
+	if got := resp.Header.Get("Cache-Control"); !strings.HasPrefix(got, "public, max-age=") {
+		t.Fatalf("latest embed Cache-Control = %q", got)
+	}
+
+	// The quote of the day is stable
+	_, first := reader.fetch("/embed/alice?mode=qotd", nil)
+	_, second := reader.fetch("/embed/alice?mode=qotd", nil)
+	if first != second {
+		t.Fatalf("qotd embed changed between loads")
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/embed/alice?mode=oldest", http.StatusBadRequest},
+		{"/embed/alice?theme=neon", http.StatusBadRequest},
+		{"/embed/alice?refresh=1", http.StatusBadRequest},
+		{"/embed/nobody", http.StatusNotFound},
+		{"/embed/collection/abc", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, _ := reader.fetch(tt.path, nil)
+		expectStatus(t, tt.path, resp.StatusCode, tt.want)
+	}
+
+	status, _ = alice.do(http.MethodPut, "/api/user/privacy", gin.H{"profile_public": false, "quotes_public": true})
+	expectStatus(t, "make profile private", status, http.StatusOK)
+	resp, _ = reader.fetch("/embed/alice", nil)
+	expectStatus(t, "private profile", resp.StatusCode, http.StatusNotFound)
+}
+
+func TestEmbedLinksUseAppURL(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("You have power over your mind")
+
+	forged := http.Header{"Host": {"evil.example"}, "X-Forwarded-Proto": {"https"}}
+
+	// Without an AppURL, links fall back to the request's host
+	_, body := env.client().fetch("/embed/alice?mode=latest", forged)
+	if !strings.Contains(body, `href="https://evil.example/"`) {
+		t.Fatalf("embed without AppURL =\n%s", body)
+	}
+
+	env.app.AppURL = "https://zetl.example/"
+	_, body = env.client().fetch("/embed/alice?mode=latest", forged)
+	if strings.Contains(body, "evil.example") || !strings.Contains(body, `href="https://zetl.example/"`) {
+		t.Fatalf("embed with AppURL =\n%s", body)
+	}
+}
+
+func TestEmbedUserLinksToProfile(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("You have power over your mind")
+
+	reader := env.client()
+	_, body := reader.fetch("/embed/alice?mode=latest", nil)
+	if !strings.Contains(body, `href="`+env.server.URL+`/u/alice"`) {
+		t.Fatalf("embed doesn't link to the user's page:\n%s", body)
+	}
+
+	resp, body := reader.fetch("/u/alice", nil)
+	expectStatus(t, "user page", resp.StatusCode, http.StatusOK)
+	if !strings.Contains(body, "You have power over your mind") {
+		t.Fatalf("user page =\n%s", body)
+	}
+
+	status, _ := alice.do(http.MethodPut, "/api/user/privacy", gin.H{"profile_public": false, "quotes_public": true})
+	expectStatus(t, "make profile private", status, http.StatusOK)
+	resp, _ = reader.fetch("/u/alice", nil)
+	expectStatus(t, "private user page", resp.StatusCode, http.StatusNotFound)
+}
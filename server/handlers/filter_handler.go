@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// HideQuoteHandler hides someone else's quote from the current user's feed
+func (a *App) HideQuoteHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	quote, err := a.Quotes.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		}
+		return
+	}
+	if quote.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't hide your own quote"})
+		return
+	}
+	if !quote.IsLinkable() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return
+	}
+
+	if err := a.Filters.HideQuote(ctx, userID, quoteID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hide quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote hidden"})
+}
+
+// UnhideQuoteHandler shows a hidden quote in the current user's feed again
+func (a *App) UnhideQuoteHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	if err := a.Filters.UnhideQuote(c.Request.Context(), c.GetInt("user_id"), quoteID); err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote is not hidden"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unhide quote"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote unhidden"})
+}
+
+// MuteUserHandler hides all of another user's quotes from the current user's feed
+func (a *App) MuteUserHandler(c *gin.Context) {
+	mutedUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	if mutedUserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't mute yourself"})
+		return
+	}
+
+	if _, err := a.Users.GetUserByID(ctx, mutedUserID); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	if err := a.Filters.MuteUser(ctx, userID, mutedUserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mute user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User muted"})
+}
+
+// UnmuteUserHandler shows a muted user's quotes in the current user's feed again
+func (a *App) UnmuteUserHandler(c *gin.Context) {
+	mutedUserID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := a.Filters.UnmuteUser(c.Request.Context(), c.GetInt("user_id"), mutedUserID); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not muted"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmute user"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unmuted"})
+}
+
+// hiddenContent loads the quotes and users the current user has hidden
+func (a *App) hiddenContent(c *gin.Context) (models.Quotes, []models.MutedUser, error) {
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	quotes, err := a.Filters.GetHiddenQuotes(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, err := a.Filters.GetMutedUsers(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quotes, users, nil
+}
+
+// GetHiddenHandler lists the quotes and users the current user has hidden
+func (a *App) GetHiddenHandler(c *gin.Context) {
+	quotes, users, err := a.hiddenContent(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hidden content"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes, "users": users})
+}
+
+// HiddenPageHandler renders the settings page for hidden quotes and muted users
+func (a *App) HiddenPageHandler(c *gin.Context) {
+	user, err := a.Users.GetUserByID(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	quotes, users, err := a.hiddenContent(c)
+	if err != nil {
+		quotes, users = nil, nil
+	}
+
+	c.HTML(http.StatusOK, "hidden.html", gin.H{
+		"title":  "Hidden Quotes",
+		"user":   user.ToResponse(),
+		"quotes": quotes,
+		"users":  users,
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// feedTexts returns the text of each quote in the viewer's feed
+func feedTexts(t *testing.T, env *testEnv, viewerID int) []string {
+	t.Helper()
+	quotes, err := env.store.GetFeedQuotes(context.Background(), viewerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	texts := make([]string, 0, len(quotes))
+	for _, q := range quotes {
+		texts = append(texts, q.Quote)
+	}
+	return texts
+}
+
+func TestHideQuote(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	own := alice.createQuote("alice's")
+
+	bob := env.client()
+	bob.signup("bob")
+	hidden := bob.createQuote("hidden")
+	bob.createQuote("kept")
+
+	path := fmt.Sprintf("/api/quote/%d/hide", hidden)
+
+	status, _ := env.client().do(http.MethodPost, path, nil)
+	expectStatus(t, "anonymous hide", status, http.StatusUnauthorized)
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/hide", own), nil)
+	expectStatus(t, "hide own quote", status, http.StatusBadRequest)
+
+	status, _ = alice.do(http.MethodPost, "/api/quote/9999/hide", nil)
+	expectStatus(t, "hide missing quote", status, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "hide", status, http.StatusOK)
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "hide again", status, http.StatusOK)
+
+	if got := feedTexts(t, env, aliceID); len(got) != 2 || got[0] != "kept" {
+		t.Fatalf("alice's feed = %v, want the hidden quote left out", got)
+	}
+	if got := feedTexts(t, env, 0); len(got) != 3 {
+		t.Fatalf("anonymous feed = %v, hiding should only affect alice", got)
+	}
+
+	_, body := alice.do(http.MethodGet, "/api/hidden", nil)
+	if quotes := body["quotes"].([]interface{}); len(quotes) != 1 {
+		t.Fatalf("hidden quotes = %v, want 1", quotes)
+	}
+
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unhide", status, http.StatusOK)
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unhide quote that isn't hidden", status, http.StatusNotFound)
+
+	if got := feedTexts(t, env, aliceID); len(got) != 3 {
+		t.Fatalf("alice's feed after unhide = %v", got)
+	}
+}
+
+func TestMuteUser(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	alice.createQuote("alice's")
+
+	bob := env.client()
+	bobID := bob.signup("bob")
+	bob.createQuote("bob's first")
+	bob.createQuote("bob's second")
+
+	path := fmt.Sprintf("/api/user/%d/mute", bobID)
+
+	status, _ := alice.do(http.MethodPost, fmt.Sprintf("/api/user/%d/mute", aliceID), nil)
+	expectStatus(t, "mute self", status, http.StatusBadRequest)
+
+	status, _ = alice.do(http.MethodPost, "/api/user/9999/mute", nil)
+	expectStatus(t, "mute missing user", status, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "mute", status, http.StatusOK)
+
+	if got := feedTexts(t, env, aliceID); len(got) != 1 || got[0] != "alice's" {
+		t.Fatalf("alice's feed = %v, want bob's quotes left out", got)
+	}
+
+	_, body := alice.do(http.MethodGet, "/api/hidden", nil)
+	users := body["users"].([]interface{})
+	if len(users) != 1 || users[0].(map[string]interface{})["username"] != "bob" {
+		t.Fatalf("muted users = %v, want bob", users)
+	}
+
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unmute", status, http.StatusOK)
+
+	if got := feedTexts(t, env, aliceID); len(got) != 3 {
+		t.Fatalf("alice's feed after unmute = %v", got)
+	}
+}
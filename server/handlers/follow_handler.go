@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+const (
+	// feedPageSize is how many quotes a feed page holds by default
+	feedPageSize = 20
+	// maxFeedPageSize caps the limit query parameter
+	maxFeedPageSize = 100
+)
+
+var errInvalidLimit = errors.New("limit must be between 1 and 100")
+
+// FollowUserHandler adds another user's public quotes to the current user's feed
+func (a *App) FollowUserHandler(c *gin.Context) {
+	followedID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	if followedID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't follow yourself"})
+		return
+	}
+
+	if _, err := a.Users.GetUserByID(ctx, followedID); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	if err := a.Follows.FollowUser(ctx, userID, followedID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User followed"})
+}
+
+// UnfollowUserHandler removes a followed user's quotes from the current user's feed
+func (a *App) UnfollowUserHandler(c *gin.Context) {
+	followedID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := a.Follows.UnfollowUser(c.Request.Context(), c.GetInt("user_id"), followedID); err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You don't follow this user"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow user"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed"})
+}
+
+// GetFollowingHandler lists the users the current user follows
+func (a *App) GetFollowingHandler(c *gin.Context) {
+	users, err := a.Follows.GetFollowing(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch followed users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// followFeedPage loads one page of the current user's follow feed from the
+// cursor and limit query parameters. The returned cursor is empty on the
+// last page.
+func (a *App) followFeedPage(c *gin.Context) (models.Quotes, string, error) {
+	cursor, err := models.ParseFeedCursor(c.Query("cursor"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	limit := feedPageSize
+	if s := c.Query("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > maxFeedPageSize {
+			return nil, "", errInvalidLimit
+		}
+	}
+
+	// Fetch one extra quote to learn whether there is another page
+	userID := c.GetInt("user_id")
+	quotes, err := a.Follows.GetFollowFeed(c.Request.Context(), userID, cursor, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(quotes) > limit {
+		quotes = quotes[:limit]
+		next = models.CursorAfter(quotes[limit-1]).Encode()
+	}
+	a.markLiked(c, userID, quotes)
+
+	return quotes, next, nil
+}
+
+// GetFeedHandler returns a page of public quotes from the users the current
+// user follows, newest first
+func (a *App) GetFeedHandler(c *gin.Context) {
+	quotes, next, err := a.followFeedPage(c)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		case errors.Is(err, errInvalidLimit):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes, "next_cursor": next})
+}
+
+// FeedPageHandler renders the current user's follow feed
+func (a *App) FeedPageHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	user, err := a.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	quotes, next, err := a.followFeedPage(c)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) || errors.Is(err, errInvalidLimit) {
+			c.Redirect(http.StatusFound, "/feed")
+			return
+		}
+		quotes, next = nil, ""
+	}
+
+	following, err := a.Follows.GetFollowing(ctx, userID)
+	if err != nil {
+		following = nil
+	}
+
+	c.HTML(http.StatusOK, "feed.html", gin.H{
+		"title":       "Feed",
+		"user":        user.ToResponse(),
+		"items":       quotes,
+		"following":   following,
+		"next_cursor": next,
+		"paged":       c.Query("cursor") != "",
+	})
+}
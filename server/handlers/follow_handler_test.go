@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedPage fetches one page of the client's follow feed and returns the
+// quote texts and the next cursor
+func (c *testClient) feedPage(query string) ([]string, string) {
+	t := c.env.t
+	t.Helper()
+
+	status, body := c.do(http.MethodGet, "/api/feed?"+query, nil)
+	if status != http.StatusOK {
+		t.Fatalf("feed: status %d, body %v", status, body)
+	}
+	texts := make([]string, 0)
+	for _, q := range body["quotes"].([]interface{}) {
+		texts = append(texts, q.(map[string]interface{})["quote"].(string))
+	}
+	return texts, body["next_cursor"].(string)
+}
+
+func TestFollowUser(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+
+	bob := env.client()
+	bobID := bob.signup("bob")
+
+	path := fmt.Sprintf("/api/user/%d/follow", bobID)
+
+	status, _ := env.client().do(http.MethodPost, path, nil)
+	expectStatus(t, "anonymous follow", status, http.StatusUnauthorized)
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/user/%d/follow", aliceID), nil)
+	expectStatus(t, "follow self", status, http.StatusBadRequest)
+
+	status, _ = alice.do(http.MethodPost, "/api/user/9999/follow", nil)
+	expectStatus(t, "follow missing user", status, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "follow", status, http.StatusOK)
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "follow again", status, http.StatusOK)
+
+	_, body := alice.do(http.MethodGet, "/api/following", nil)
+	users := body["users"].([]interface{})
+	if len(users) != 1 || users[0].(map[string]interface{})["username"] != "bob" {
+		t.Fatalf("following = %v, want bob", users)
+	}
+
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unfollow", status, http.StatusOK)
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unfollow user who isn't followed", status, http.StatusNotFound)
+}
+
+func TestFollowFeed(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("alice's")
+
+	bob := env.client()
+	bobID := bob.signup("bob")
+	for i := 1; i <= 5; i++ {
+		bob.createQuote(fmt.Sprintf("bob %d", i))
+	}
+	status, _ := bob.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "bob unlisted", "author": "a", "book": "b", "visibility": "unlisted",
+	})
+	expectStatus(t, "create unlisted quote", status, http.StatusCreated)
+
+	carol := env.client()
+	carol.signup("carol")
+	carol.createQuote("carol's")
+
+	if got, _ := alice.feedPage(""); len(got) != 0 {
+		t.Fatalf("feed before following = %v, want empty", got)
+	}
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/user/%d/follow", bobID), nil)
+	expectStatus(t, "follow", status, http.StatusOK)
+
+	var all []string
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		texts, next := alice.feedPage("limit=2&cursor=" + url.QueryEscape(cursor))
+		all = append(all, texts...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	want := []string{"bob 5", "bob 4", "bob 3", "bob 2", "bob 1"}
+	if fmt.Sprint(all) != fmt.Sprint(want) {
+		t.Fatalf("paged feed = %v, want %v", all, want)
+	}
+
+	status, _ = alice.do(http.MethodGet, "/api/feed?cursor=not-a-cursor", nil)
+	expectStatus(t, "invalid cursor", status, http.StatusBadRequest)
+	status, _ = alice.do(http.MethodGet, "/api/feed?limit=0", nil)
+	expectStatus(t, "invalid limit", status, http.StatusBadRequest)
+}
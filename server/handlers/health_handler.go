@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness tracks whether the server should receive new traffic
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// StartDraining makes /readyz fail so the load balancer stops sending requests
+func (r *Readiness) StartDraining() {
+	r.draining.Store(true)
+}
+
+// IsDraining reports whether shutdown has begun
+func (r *Readiness) IsDraining() bool {
+	return r.draining.Load()
+}
+
+// HealthzHandler reports that the process is alive (used by the liveness probe)
+func (a *App) HealthzHandler(c *gin.Context) {
+	c.String(http.StatusOK, "ok")
+}
+
+// ReadyzHandler reports whether the server can take traffic: it fails while
+// draining for shutdown or when the database is unreachable
+func (a *App) ReadyzHandler(c *gin.Context) {
+	if a.Readiness.IsDraining() {
+		c.String(http.StatusServiceUnavailable, "draining")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+	if err := a.DB.PingContext(ctx); err != nil {
+		c.String(http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+
+	c.String(http.StatusOK, "ok")
+}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
-	"database/sql"
-	"log"
+	"context"
+	"fmt"
+	"strings"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/models"
 )
 
 // GetUserFromSession retrieves the user from session if logged in.
 // Returns nil if the user is not logged in or cannot be retrieved.
-func GetUserFromSession(c *gin.Context, db *sql.DB) map[string]interface{} {
+func (a *App) GetUserFromSession(c *gin.Context) map[string]interface{} {
 	session := sessions.Default(c)
 	userID := session.Get("user_id")
 
@@ -29,19 +32,47 @@ func GetUserFromSession(c *gin.Context, db *sql.DB) map[string]interface{} {
 	case float64:
 		userIDInt = int(v)
 	default:
-		log.Printf("[Session] Unexpected user_id type: %T", userID)
+		logging.FromContext(c.Request.Context()).Warn("unexpected session user_id type", "type", fmt.Sprintf("%T", userID))
 		return nil
 	}
 
-	user, err := database.GetUserByID(c.Request.Context(), db, userIDInt)
+	user, err := a.Users.GetUserByID(c.Request.Context(), userIDInt)
 	if err != nil {
-		log.Printf("[Session] Failed to get user by ID %d: %v", userIDInt, err)
+		logging.FromContext(c.Request.Context()).Warn("failed to load session user", "user_id", userIDInt, "error", err)
 		return nil
 	}
 
 	return user.ToResponse()
 }
 
+// absoluteURL builds a full URL for path from the configured AppURL. The
+// request's Host and X-Forwarded-Proto are client controlled, so they are only
+// used when no AppURL is configured, as in local development.
+func (a *App) absoluteURL(c *gin.Context, path string) string {
+	if a.AppURL != "" {
+		return strings.TrimRight(a.AppURL, "/") + path
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + path
+}
+
+// publicUser looks up an active user with a public profile by username.
+// Anyone else is reported as ErrUserNotFound.
+func (a *App) publicUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := a.Users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive || (user.PrivacySettings != nil && !user.PrivacySettings.ProfilePublic) {
+		return nil, database.ErrUserNotFound
+	}
+	return user, nil
+}
+
 // CreateUserSession creates a new session for the given user ID.
 func CreateUserSession(c *gin.Context, userID int) error {
 	session := sessions.Default(c)
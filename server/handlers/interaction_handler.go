@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/metrics"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// otherUsersQuote loads the quote in the :id param for a like or save,
+// writing an error response and returning nil unless it is someone else's
+// public quote. Unlisted quotes can't be liked or saved, so a shared link
+// can't be used to republish them.
+func (a *App) otherUsersQuote(c *gin.Context, ownQuoteError string) *models.Quote {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return nil
+	}
+
+	quote, err := a.Quotes.GetQuoteByID(c.Request.Context(), quoteID)
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		}
+		return nil
+	}
+	if quote.UserID == c.GetInt("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ownQuoteError})
+		return nil
+	}
+	if quote.Visibility != models.VisibilityPublic {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		return nil
+	}
+
+	return quote
+}
+
+// likeCount returns a quote's like count after the current user's like changed
+func (a *App) likeCount(c *gin.Context, quoteID int) (int, error) {
+	quote, err := a.Quotes.GetQuoteByID(c.Request.Context(), quoteID)
+	if err != nil {
+		return 0, err
+	}
+	return quote.LikeCount, nil
+}
+
+// LikeQuoteHandler likes someone else's quote
+func (a *App) LikeQuoteHandler(c *gin.Context) {
+	quote := a.otherUsersQuote(c, "You can't like your own quote")
+	if quote == nil {
+		return
+	}
+
+	if err := a.Interactions.LikeQuote(c.Request.Context(), c.GetInt("user_id"), quote.QuoteID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to like quote"})
+		return
+	}
+
+	count, err := a.likeCount(c, quote.QuoteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"liked": true, "like_count": count})
+}
+
+// UnlikeQuoteHandler removes the current user's like from a quote
+func (a *App) UnlikeQuoteHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	if err := a.Interactions.UnlikeQuote(c.Request.Context(), c.GetInt("user_id"), quoteID); err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote is not liked"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlike quote"})
+		}
+		return
+	}
+
+	count, err := a.likeCount(c, quoteID)
+	if err != nil && !errors.Is(err, database.ErrQuoteNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"liked": false, "like_count": count})
+}
+
+// SaveQuoteHandler copies someone else's quote into the current user's
+// quotes with attribution to the original
+func (a *App) SaveQuoteHandler(c *gin.Context) {
+	quote := a.otherUsersQuote(c, "You can't save your own quote")
+	if quote == nil {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	user, err := a.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save quote"})
+		return
+	}
+
+	savedID, err := a.Interactions.SaveQuote(ctx, userID, quote.QuoteID, user.PrivacySettings.QuoteVisibility())
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrQuoteNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		case errors.Is(err, database.ErrAlreadySaved):
+			c.JSON(http.StatusConflict, gin.H{"error": "You've already saved this quote"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save quote"})
+		}
+		return
+	}
+
+	metrics.QuotesSavedTotal.Inc()
+	a.Enricher.TrackBook(ctx, userID, quote.Book, quote.Author)
+	a.emitQuoteEventByID(c, models.WebhookQuoteCreated, savedID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Quote saved to your collection",
+		"quote_id": savedID,
+	})
+}
+
+// GetQuoteSaversHandler lists the users who saved a copy of one of the
+// current user's quotes
+func (a *App) GetQuoteSaversHandler(c *gin.Context) {
+	// Get quote_id from context (set by QuoteOwnershipRequired middleware)
+	quoteID, exists := c.Get("quote_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	savers, err := a.Interactions.GetQuoteSavers(c.Request.Context(), quoteID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch savers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"savers": savers})
+}
+
+// markLiked sets Liked on the quotes the viewer has liked. Anonymous viewers
+// and lookup failures leave every quote unliked.
+func (a *App) markLiked(c *gin.Context, viewerID int, quotes models.Quotes) {
+	if viewerID == 0 || len(quotes) == 0 {
+		return
+	}
+
+	ids := make([]int, len(quotes))
+	for i, q := range quotes {
+		ids[i] = q.QuoteID
+	}
+
+	liked, err := a.Interactions.GetLikedQuoteIDs(c.Request.Context(), viewerID, ids)
+	if err != nil {
+		return
+	}
+	for i := range quotes {
+		quotes[i].Liked = liked[quotes[i].QuoteID]
+	}
+}
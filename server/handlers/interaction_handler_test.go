@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLikeQuote(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	own := alice.createQuote("alice's")
+
+	bob := env.client()
+	bob.signup("bob")
+	quoteID := bob.createQuote("bob's")
+
+	path := fmt.Sprintf("/api/quote/%d/like", quoteID)
+
+	status, _ := env.client().do(http.MethodPost, path, nil)
+	expectStatus(t, "anonymous like", status, http.StatusUnauthorized)
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/like", own), nil)
+	expectStatus(t, "like own quote", status, http.StatusBadRequest)
+
+	status, body := alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "like", status, http.StatusOK)
+	if body["like_count"].(float64) != 1 || body["liked"] != true {
+		t.Fatalf("like response = %v, want liked with 1 like", body)
+	}
+	status, body = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "like again", status, http.StatusOK)
+	if body["like_count"].(float64) != 1 {
+		t.Fatalf("like count after liking twice = %v, want 1", body["like_count"])
+	}
+
+	quotes, err := env.store.GetFeedQuotes(context.Background(), aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.app.markLiked(&gin.Context{Request: httptest.NewRequest(http.MethodGet, "/", nil)}, aliceID, quotes)
+	for _, q := range quotes {
+		if q.Liked != (q.QuoteID == quoteID) {
+			t.Fatalf("quote %d liked = %v", q.QuoteID, q.Liked)
+		}
+	}
+
+	status, body = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unlike", status, http.StatusOK)
+	if body["like_count"].(float64) != 0 {
+		t.Fatalf("like count after unlike = %v, want 0", body["like_count"])
+	}
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "unlike quote that isn't liked", status, http.StatusNotFound)
+}
+
+func TestLikePrivateQuote(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+
+	bob := env.client()
+	bob.signup("bob")
+	status, body := bob.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "private", "author": "a", "book": "b", "visibility": "private",
+	})
+	expectStatus(t, "create private quote", status, http.StatusCreated)
+	quoteID := int(body["quote_id"].(float64))
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/like", quoteID), nil)
+	expectStatus(t, "like private quote", status, http.StatusNotFound)
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/save", quoteID), nil)
+	expectStatus(t, "save private quote", status, http.StatusNotFound)
+
+	// Unlisted quotes are viewable by link but can't be liked or saved
+	status, body = bob.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "unlisted", "author": "a", "book": "b", "visibility": "unlisted",
+	})
+	expectStatus(t, "create unlisted quote", status, http.StatusCreated)
+	quoteID = int(body["quote_id"].(float64))
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/like", quoteID), nil)
+	expectStatus(t, "like unlisted quote", status, http.StatusNotFound)
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/save", quoteID), nil)
+	expectStatus(t, "save unlisted quote", status, http.StatusNotFound)
+}
+
+func TestSaveQuote(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	own := alice.createQuote("alice's")
+
+	bob := env.client()
+	bobID := bob.signup("bob")
+	quoteID := bob.createQuote("bob's")
+
+	path := fmt.Sprintf("/api/quote/%d/save", quoteID)
+
+	status, _ := alice.do(http.MethodPost, fmt.Sprintf("/api/quote/%d/save", own), nil)
+	expectStatus(t, "save own quote", status, http.StatusBadRequest)
+
+	status, body := alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "save", status, http.StatusCreated)
+	savedID := int(body["quote_id"].(float64))
+
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "save again", status, http.StatusConflict)
+
+	saved, err := env.store.GetQuoteByID(context.Background(), savedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.UserID != aliceID || saved.Quote != "bob's" {
+		t.Fatalf("saved copy = %+v, want alice's copy of bob's quote", saved)
+	}
+	if saved.SavedFrom == nil || *saved.SavedFrom.QuoteID != quoteID || saved.SavedFrom.UserID != bobID || saved.SavedFrom.Username != "bob" {
+		t.Fatalf("saved from = %+v, want bob's quote %d", saved.SavedFrom, quoteID)
+	}
+
+	status, _ = alice.do(http.MethodGet, fmt.Sprintf("/api/quote/%d/saves", quoteID), nil)
+	expectStatus(t, "savers of someone else's quote", status, http.StatusForbidden)
+
+	status, body = bob.do(http.MethodGet, fmt.Sprintf("/api/quote/%d/saves", quoteID), nil)
+	expectStatus(t, "savers", status, http.StatusOK)
+	savers := body["savers"].([]interface{})
+	if len(savers) != 1 || savers[0].(map[string]interface{})["username"] != "alice" {
+		t.Fatalf("savers = %v, want alice", savers)
+	}
+
+	// Trashing the copy lets the quote be saved again
+	status, _ = alice.do(http.MethodDelete, fmt.Sprintf("/api/quote/%d", savedID), nil)
+	expectStatus(t, "delete saved copy", status, http.StatusOK)
+	_, body = bob.do(http.MethodGet, fmt.Sprintf("/api/quote/%d/saves", quoteID), nil)
+	if savers := body["savers"].([]interface{}); len(savers) != 0 {
+		t.Fatalf("savers after trashing the copy = %v, want none", savers)
+	}
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "save after trashing the copy", status, http.StatusCreated)
+}
@@ -1,131 +1,278 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zach-monroe/zetl/server/database"
 )
 
+// HomePageHandler renders the global feed, served at / and /explore: public
+// quotes plus the viewer's own
+func (a *App) HomePageHandler(c *gin.Context) {
+	user := a.GetUserFromSession(c)
+	viewerID := 0
+	if user != nil {
+		viewerID = user["id"].(int)
+	}
+
+	quotes, err := a.Quotes.GetFeedQuotes(c.Request.Context(), viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load quotes"})
+		return
+	}
+	a.markLiked(c, viewerID, quotes)
+	c.HTML(http.StatusOK, "index.html", gin.H{"items": quotes, "user": user})
+}
+
 // LoginPageHandler renders the login page
-func LoginPageHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Redirect if already logged in
-		if GetUserFromSession(c, db) != nil {
-			c.Redirect(http.StatusFound, "/")
-			return
-		}
-		c.HTML(http.StatusOK, "login.html", gin.H{
-			"title": "Login",
-		})
+func (a *App) LoginPageHandler(c *gin.Context) {
+	// Redirect if already logged in
+	if a.GetUserFromSession(c) != nil {
+		c.Redirect(http.StatusFound, "/")
+		return
 	}
+	c.HTML(http.StatusOK, "login.html", gin.H{
+		"title": "Login",
+	})
 }
 
 // SignupPageHandler renders the signup page
-func SignupPageHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Redirect if already logged in
-		if GetUserFromSession(c, db) != nil {
-			c.Redirect(http.StatusFound, "/")
-			return
-		}
-		c.HTML(http.StatusOK, "signup.html", gin.H{
-			"title": "Sign Up",
-		})
+func (a *App) SignupPageHandler(c *gin.Context) {
+	// Redirect if already logged in
+	if a.GetUserFromSession(c) != nil {
+		c.Redirect(http.StatusFound, "/")
+		return
 	}
+	c.HTML(http.StatusOK, "signup.html", gin.H{
+		"title": "Sign Up",
+	})
 }
 
 // ForgotPasswordPageHandler renders the forgot password page
-func ForgotPasswordPageHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.HTML(http.StatusOK, "forgot-password.html", gin.H{
-			"title": "Forgot Password",
-		})
-	}
+func (a *App) ForgotPasswordPageHandler(c *gin.Context) {
+	c.HTML(http.StatusOK, "forgot-password.html", gin.H{
+		"title": "Forgot Password",
+	})
 }
 
 // ResetPasswordPageHandler renders the reset password page
-func ResetPasswordPageHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		token := c.Query("token")
-
-		// Validate token exists and is valid
-		if token == "" {
-			c.HTML(http.StatusOK, "reset-password.html", gin.H{
-				"title":         "Reset Password",
-				"invalid_token": true,
-			})
-			return
-		}
+func (a *App) ResetPasswordPageHandler(c *gin.Context) {
+	token := c.Query("token")
 
-		// Check if token is valid
-		_, err := database.GetPasswordResetToken(c.Request.Context(), db, token)
-		if err != nil {
-			c.HTML(http.StatusOK, "reset-password.html", gin.H{
-				"title":         "Reset Password",
-				"invalid_token": true,
-			})
-			return
-		}
+	// Validate token exists and is valid
+	if token == "" {
+		c.HTML(http.StatusOK, "reset-password.html", gin.H{
+			"title":         "Reset Password",
+			"invalid_token": true,
+		})
+		return
+	}
 
+	// Check if token is valid
+	_, err := a.Tokens.GetPasswordResetToken(c.Request.Context(), token)
+	if err != nil {
 		c.HTML(http.StatusOK, "reset-password.html", gin.H{
-			"title": "Reset Password",
-			"token": token,
+			"title":         "Reset Password",
+			"invalid_token": true,
 		})
+		return
 	}
+
+	c.HTML(http.StatusOK, "reset-password.html", gin.H{
+		"title": "Reset Password",
+		"token": token,
+	})
 }
 
 // SettingsPageHandler renders the settings page
-func SettingsPageHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.Redirect(http.StatusFound, "/login")
-			return
-		}
-
-		user, err := database.GetUserByID(c.Request.Context(), db, userID.(int))
-		if err != nil {
-			c.Redirect(http.StatusFound, "/login")
-			return
-		}
+func (a *App) SettingsPageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
 
-		c.HTML(http.StatusOK, "settings.html", gin.H{
-			"title": "Settings",
-			"user":  user,
-		})
+	user, err := a.Users.GetUserByID(c.Request.Context(), userID.(int))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
 	}
+
+	c.HTML(http.StatusOK, "settings.html", gin.H{
+		"title": "Settings",
+		"user":  user,
+	})
 }
 
 // ProfilePageHandler renders the profile page
-func ProfilePageHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.Redirect(http.StatusFound, "/login")
-			return
-		}
+func (a *App) ProfilePageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
 
-		ctx := c.Request.Context()
-		user, err := database.GetUserByID(ctx, db, userID.(int))
-		if err != nil {
-			c.Redirect(http.StatusFound, "/login")
-			return
-		}
+	ctx := c.Request.Context()
+	user, err := a.Users.GetUserByID(ctx, userID.(int))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	// Fetch user's quotes
+	quotes, err := a.Quotes.GetQuotesByUserID(ctx, userID.(int))
+	if err != nil {
+		quotes = nil
+	}
 
-		// Fetch user's quotes
-		quotes, err := database.FetchQuotesByUserID(ctx, db, userID.(int))
-		if err != nil {
-			quotes = nil
+	c.HTML(http.StatusOK, "profile.html", gin.H{
+		"title":          "My Profile",
+		"user":           user.ToResponse(),
+		"profile_user":   user,
+		"items":          quotes,
+		"is_own_profile": true,
+	})
+}
+
+// UserPageHandler renders a user's public profile with their public quotes.
+// Users who have made their profile private have no page.
+func (a *App) UserPageHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	profileUser, err := a.publicUser(ctx, c.Param("username"))
+	if err != nil {
+		if errors.Is(err, database.ErrUserNotFound) {
+			c.String(http.StatusNotFound, "User not found")
+		} else {
+			c.String(http.StatusInternalServerError, "Failed to load user")
 		}
+		return
+	}
 
-		c.HTML(http.StatusOK, "profile.html", gin.H{
-			"title":          "My Profile",
-			"user":           user.ToResponse(),
-			"profile_user":   user,
-			"items":          quotes,
-			"is_own_profile": true,
-		})
+	quotes, err := a.Quotes.GetPublicQuotesByUserID(ctx, profileUser.ID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load quotes")
+		return
 	}
+
+	user := a.GetUserFromSession(c)
+	viewerID := 0
+	if user != nil {
+		viewerID = user["id"].(int)
+	}
+	a.markLiked(c, viewerID, quotes)
+
+	c.HTML(http.StatusOK, "profile.html", gin.H{
+		"title":          profileUser.Username,
+		"user":           user,
+		"profile_user":   profileUser,
+		"items":          quotes,
+		"is_own_profile": false,
+	})
+}
+
+// BooksPageHandler renders the user's books with catalog matches to review
+func (a *App) BooksPageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := a.Users.GetUserByID(ctx, userID.(int))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	books, err := database.GetBooksByUserID(ctx, a.DB, userID.(int))
+	if err != nil {
+		books = nil
+	}
+
+	c.HTML(http.StatusOK, "books.html", gin.H{
+		"title": "My Books",
+		"user":  user.ToResponse(),
+		"books": books,
+	})
+}
+
+// CollectionsPageHandler renders the user's collections
+func (a *App) CollectionsPageHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := a.Users.GetUserByID(ctx, userID.(int))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	collections, err := database.GetCollectionsByUserID(ctx, a.DB, userID.(int))
+	if err != nil {
+		collections = nil
+	}
+
+	c.HTML(http.StatusOK, "collections.html", gin.H{
+		"title":       "My Collections",
+		"user":        user.ToResponse(),
+		"collections": collections,
+	})
+}
+
+// CollectionPageHandler renders a single collection. Collection visibility
+// overrides the owner's account-level quote privacy, but private quotes are
+// only shown to the owner.
+func (a *App) CollectionPageHandler(c *gin.Context) {
+	collectionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Collection not found")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	col, err := database.GetCollectionByID(ctx, a.DB, collectionID)
+	if err != nil {
+		c.String(http.StatusNotFound, "Collection not found")
+		return
+	}
+
+	owner, err := a.Users.GetUserByID(ctx, col.UserID)
+	if err != nil {
+		c.String(http.StatusNotFound, "Collection not found")
+		return
+	}
+
+	user := a.GetUserFromSession(c)
+	isOwner := user != nil && user["id"] == col.UserID
+	if !isOwner && !col.IsPublic(owner.PrivacySettings) {
+		c.String(http.StatusNotFound, "Collection not found")
+		return
+	}
+
+	quotes, err := database.FetchCollectionQuotes(ctx, a.DB, col.CollectionID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to load collection")
+		return
+	}
+	if !isOwner {
+		quotes = quotes.Linkable()
+	}
+
+	c.HTML(http.StatusOK, "collection.html", gin.H{
+		"title":      col.Name,
+		"user":       user,
+		"collection": col,
+		"owner":      owner,
+		"items":      quotes,
+		"is_owner":   isOwner,
+	})
 }
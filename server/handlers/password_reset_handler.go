@@ -1,116 +1,111 @@
 package handlers
 
 import (
-	"database/sql"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
 	"github.com/zach-monroe/zetl/server/models"
 	"github.com/zach-monroe/zetl/server/services"
 )
 
 // ForgotPasswordHandler handles password reset requests
-func ForgotPasswordHandler(db *sql.DB, emailService *services.EmailService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req models.ForgotPasswordRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+func (a *App) ForgotPasswordHandler(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Always return success to prevent email enumeration
-		successMessage := "If an account exists with this email, you will receive a password reset link."
+	// Always return success to prevent email enumeration
+	successMessage := "If an account exists with this email, you will receive a password reset link."
 
-		ctx := c.Request.Context()
+	ctx := c.Request.Context()
+	logger := logging.FromContext(ctx)
 
-		// Look up user by email
-		user, err := database.GetUserByEmail(ctx, db, req.Email)
-		if err != nil {
-			// User not found - still return success message
-			c.JSON(http.StatusOK, gin.H{"message": successMessage})
-			return
-		}
+	// Look up user by email
+	user, err := a.Users.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		// User not found - still return success message
+		c.JSON(http.StatusOK, gin.H{"message": successMessage})
+		return
+	}
 
-		// Invalidate any existing tokens for this user
-		database.InvalidateUserTokens(ctx, db, user.ID)
+	// Invalidate any existing tokens for this user
+	a.Tokens.InvalidateUserTokens(ctx, user.ID)
 
-		// Create new reset token
-		token, err := database.CreatePasswordResetToken(ctx, db, user.ID)
-		if err != nil {
-			log.Printf("Failed to create password reset token: %v", err)
-			c.JSON(http.StatusOK, gin.H{"message": successMessage})
-			return
-		}
+	// Create new reset token
+	token, err := a.Tokens.CreatePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		logger.Error("failed to create password reset token", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusOK, gin.H{"message": successMessage})
+		return
+	}
 
-		// Send reset email
-		if emailService.IsConfigured() {
-			err = emailService.SendPasswordResetEmail(user.Email, token.Token)
-			if err != nil {
-				log.Printf("Failed to send password reset email: %v", err)
-			}
-		} else {
-			// For development: log the reset link
-			log.Printf("Password reset token for %s: %s", user.Email, token.Token)
+	// Send reset email
+	if a.Email.IsConfigured() {
+		err = a.Email.SendPasswordResetEmail(user.Email, token.Token)
+		if err != nil {
+			logger.Error("failed to send password reset email", "user_id", user.ID, "email", user.Email, "error", err)
 		}
-
-		c.JSON(http.StatusOK, gin.H{"message": successMessage})
+	} else {
+		// The token itself is never logged; read it from password_reset_tokens in development
+		logger.Warn("email service not configured, password reset email not sent", "user_id", user.ID, "email", user.Email)
 	}
+
+	c.JSON(http.StatusOK, gin.H{"message": successMessage})
 }
 
 // ResetPasswordHandler handles setting a new password
-func ResetPasswordHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var req models.ResetPasswordRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		ctx := c.Request.Context()
+func (a *App) ResetPasswordHandler(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Verify token
-		token, err := database.GetPasswordResetToken(ctx, db, req.Token)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
-			return
-		}
+	ctx := c.Request.Context()
 
-		// Validate new password
-		if err := services.ValidatePassword(req.NewPassword); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	// Verify token
+	token, err := a.Tokens.GetPasswordResetToken(ctx, req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
 
-		// Hash new password
-		hashedPassword, err := services.HashPassword(req.NewPassword)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
-			return
-		}
+	// Validate new password
+	if err := services.ValidatePassword(req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Update password
-		err = database.UpdateUserPassword(ctx, db, token.UserID, hashedPassword)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
-			return
-		}
+	// Hash new password
+	hashedPassword, err := services.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process password"})
+		return
+	}
 
-		// Mark token as used
-		database.MarkTokenAsUsed(ctx, db, token.ID)
+	// Update password
+	err = a.Users.UpdateUserPassword(ctx, token.UserID, hashedPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
 
-		// Invalidate all other tokens for this user
-		database.InvalidateUserTokens(ctx, db, token.UserID)
+	// Mark token as used
+	a.Tokens.MarkTokenAsUsed(ctx, token.ID)
 
-		// Auto-login: create session for the user
-		if err := CreateUserSession(c, token.UserID); err != nil {
-			log.Printf("[PasswordReset] Failed to create session: %v", err)
-			// Still return success - password was reset, just login failed
-			c.JSON(http.StatusOK, gin.H{"message": "Password reset successful"})
-			return
-		}
+	// Invalidate all other tokens for this user
+	a.Tokens.InvalidateUserTokens(ctx, token.UserID)
 
-		c.JSON(http.StatusOK, gin.H{"message": "Password reset successful", "redirect": "/"})
+	// Auto-login: create session for the user
+	if err := CreateUserSession(c, token.UserID); err != nil {
+		logging.FromContext(ctx).Error("failed to create session after password reset", "user_id", token.UserID, "error", err)
+		// Still return success - password was reset, just login failed
+		c.JSON(http.StatusOK, gin.H{"message": "Password reset successful"})
+		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successful", "redirect": "/"})
 }
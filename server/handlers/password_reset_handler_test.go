@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestForgotPasswordDoesNotRevealAccounts(t *testing.T) {
+	env := newTestEnv(t)
+	env.client().signup("alice")
+
+	for _, email := range []string{"alice@example.com", "nobody@example.com"} {
+		status, body := env.client().do(http.MethodPost, "/auth/forgot-password", gin.H{"email": email})
+		expectStatus(t, "forgot password for "+email, status, http.StatusOK)
+		if body["message"] == nil {
+			t.Fatalf("forgot password for %s: missing message", email)
+		}
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	env := newTestEnv(t)
+	userID := env.client().signup("alice")
+
+	token, err := env.store.CreatePasswordResetToken(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reset := gin.H{"token": token.Token, "new_password": "NewPassword456"}
+
+	client := env.client()
+	status, _ := client.do(http.MethodPost, "/auth/reset-password", reset)
+	expectStatus(t, "reset", status, http.StatusOK)
+
+	status, _ = client.do(http.MethodGet, "/api/user", nil)
+	expectStatus(t, "logged in after reset", status, http.StatusOK)
+
+	status, _ = env.client().do(http.MethodPost, "/auth/reset-password", reset)
+	expectStatus(t, "reuse token", status, http.StatusBadRequest)
+
+	status, _ = env.client().do(http.MethodPost, "/auth/login", gin.H{"username_or_email": "alice", "password": testPassword})
+	expectStatus(t, "login with old password", status, http.StatusUnauthorized)
+
+	status, _ = env.client().do(http.MethodPost, "/auth/login", gin.H{"username_or_email": "alice", "password": "NewPassword456"})
+	expectStatus(t, "login with new password", status, http.StatusOK)
+}
@@ -1,12 +1,13 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zach-monroe/zetl/server/config"
 	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/metrics"
 	"github.com/zach-monroe/zetl/server/services"
 )
 
@@ -16,59 +17,62 @@ type GeneratePromptRequest struct {
 }
 
 // GeneratePromptHandler handles the POST /api/generate-prompt endpoint
-func GeneratePromptHandler(db *sql.DB, gemini *services.GeminiService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if !gemini.IsConfigured() {
-			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Writing prompt generation is not configured"})
-			return
-		}
-
-		var req GeneratePromptRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
-			return
-		}
-
-		if len(req.QuoteIDs) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "No quotes selected"})
-			return
-		}
+func (a *App) GeneratePromptHandler(c *gin.Context) {
+	if !a.Gemini.IsConfigured() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Writing prompt generation is not configured"})
+		return
+	}
 
-		if len(req.QuoteIDs) > config.MaxQuotesPerPrompt {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum 10 quotes allowed"})
-			return
-		}
+	var req GeneratePromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
 
-		ctx := c.Request.Context()
+	if len(req.QuoteIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No quotes selected"})
+		return
+	}
 
-		// Fetch quotes from database
-		var quotes []services.QuoteInput
-		for _, quoteID := range req.QuoteIDs {
-			quote, err := database.GetQuoteByID(ctx, db, quoteID)
-			if err != nil {
-				// Skip quotes that don't exist
-				continue
-			}
+	if len(req.QuoteIDs) > config.MaxQuotesPerPrompt {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum 10 quotes allowed"})
+		return
+	}
 
-			quotes = append(quotes, services.QuoteInput{
-				Quote:  quote["quote"].(string),
-				Author: quote["author"].(string),
-				Book:   quote["book"].(string),
-			})
-		}
+	ctx := c.Request.Context()
 
-		if len(quotes) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "No valid quotes found"})
-			return
+	// Fetch quotes from database
+	var quotes []services.QuoteInput
+	for _, quoteID := range req.QuoteIDs {
+		quote, err := a.Quotes.GetQuoteByID(ctx, quoteID)
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			// Skip quotes that don't exist
+			continue
 		}
-
-		// Generate writing prompt
-		prompt, err := gemini.GenerateWritingPrompt(quotes)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate prompt: " + err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"prompt": prompt})
+		quotes = append(quotes, services.QuoteInput{
+			Quote:  quote.Quote,
+			Author: quote.Author,
+			Book:   quote.Book,
+		})
 	}
+
+	if len(quotes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No valid quotes found"})
+		return
+	}
+
+	// Generate writing prompt
+	prompt, err := a.Gemini.GenerateWritingPrompt(quotes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate prompt: " + err.Error()})
+		return
+	}
+
+	metrics.PromptsGeneratedTotal.Inc()
+	c.JSON(http.StatusOK, gin.H{"prompt": prompt})
 }
@@ -1,13 +1,16 @@
 package handlers
 
 import (
-	"database/sql"
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/metrics"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
 )
 
 type QuoteRequest struct {
@@ -16,129 +19,169 @@ type QuoteRequest struct {
 	Book   string   `json:"book" binding:"required"`
 	Tags   []string `json:"tags"`
 	Notes  string   `json:"notes"`
+	models.Citation
+
+	// Visibility defaults to the user's privacy settings on create and is
+	// left unchanged on update when empty
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
 }
 
 // CreateQuoteHandler handles creating a new quote
-func CreateQuoteHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Get user_id from context (set by AuthRequired middleware)
-		userID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
-			return
-		}
+func (a *App) CreateQuoteHandler(c *gin.Context) {
+	// Get user_id from context (set by AuthRequired middleware)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
 
-		var req QuoteRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	var req QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Ensure tags is not nil
-		if req.Tags == nil {
-			req.Tags = []string{}
-		}
+	if err := services.ValidateCitation(req.Citation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Create quote
-		quoteID, err := database.CreateQuote(c.Request.Context(), db, userID.(int), req.Quote, req.Author, req.Book, req.Tags, req.Notes)
+	// Ensure tags is not nil
+	if req.Tags == nil {
+		req.Tags = []string{}
+	}
+
+	if req.Visibility == "" {
+		user, err := a.Users.GetUserByID(c.Request.Context(), userID.(int))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quote", "details": err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quote"})
 			return
 		}
+		req.Visibility = user.PrivacySettings.QuoteVisibility()
+	}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"message":  "Quote created successfully",
-			"quote_id": quoteID,
-		})
+	// Create quote
+	quoteID, err := a.Quotes.CreateQuote(c.Request.Context(), userID.(int), req.Quote, req.Author, req.Book, req.Tags, req.Notes, req.Citation, req.Visibility)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create quote", "details": err.Error()})
+		return
 	}
+
+	metrics.QuotesCreatedTotal.Inc()
+	a.Enricher.TrackBook(c.Request.Context(), userID.(int), req.Book, req.Author)
+	a.emitQuoteEventByID(c, models.WebhookQuoteCreated, quoteID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Quote created successfully",
+		"quote_id": quoteID,
+	})
 }
 
 // UpdateQuoteHandler handles updating an existing quote
-func UpdateQuoteHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Get quote_id from context (set by QuoteOwnershipRequired middleware)
-		quoteID, exists := c.Get("quote_id")
-		if !exists {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
-			return
-		}
+func (a *App) UpdateQuoteHandler(c *gin.Context) {
+	// Get quote_id from context (set by QuoteOwnershipRequired middleware)
+	quoteID, exists := c.Get("quote_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
 
-		var req QuoteRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+	var req QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Ensure tags is not nil
-		if req.Tags == nil {
-			req.Tags = []string{}
-		}
+	if err := services.ValidateCitation(req.Citation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Update quote
-		err := database.UpdateQuote(c.Request.Context(), db, quoteID.(int), req.Quote, req.Author, req.Book, req.Tags, req.Notes)
-		if err != nil {
-			if errors.Is(err, database.ErrQuoteNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quote"})
-			}
-			return
-		}
+	// Ensure tags is not nil
+	if req.Tags == nil {
+		req.Tags = []string{}
+	}
 
-		c.JSON(http.StatusOK, gin.H{"message": "Quote updated successfully"})
+	// Update quote
+	err := a.Quotes.UpdateQuote(c.Request.Context(), quoteID.(int), req.Quote, req.Author, req.Book, req.Tags, req.Notes, req.Citation, req.Visibility)
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update quote"})
+		}
+		return
 	}
+
+	a.Enricher.TrackBook(c.Request.Context(), c.GetInt("user_id"), req.Book, req.Author)
+	a.emitQuoteEventByID(c, models.WebhookQuoteUpdated, quoteID.(int))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote updated successfully"})
 }
 
 // DeleteQuoteHandler handles deleting a quote
-func DeleteQuoteHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Get quote_id from context (set by QuoteOwnershipRequired middleware)
-		quoteID, exists := c.Get("quote_id")
-		if !exists {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
-			return
-		}
+func (a *App) DeleteQuoteHandler(c *gin.Context) {
+	// Get quote_id from context (set by QuoteOwnershipRequired middleware)
+	quoteID, exists := c.Get("quote_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
 
-		// Delete quote
-		err := database.DeleteQuote(c.Request.Context(), db, quoteID.(int))
-		if err != nil {
-			if errors.Is(err, database.ErrQuoteNotFound) {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quote"})
-			}
-			return
+	// Keep the quote as it was for the quote.deleted webhook payload
+	quote, err := a.Quotes.GetQuoteByID(c.Request.Context(), quoteID.(int))
+	if err == nil {
+		err = a.Quotes.DeleteQuote(c.Request.Context(), quoteID.(int))
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quote"})
 		}
-
-		c.JSON(http.StatusOK, gin.H{"message": "Quote deleted successfully"})
+		return
 	}
+
+	metrics.QuotesDeletedTotal.Inc()
+	deletedAt := time.Now()
+	quote.DeletedAt = &deletedAt
+	a.emitQuoteEvent(c, models.WebhookQuoteDeleted, quote)
+	c.JSON(http.StatusOK, gin.H{"message": "Quote moved to trash"})
 }
 
-// GetAllQuotesHandler returns all quotes (public)
-func GetAllQuotesHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		quotesJSON := database.FetchQuotesAsJson(db)
-		c.Header("Content-Type", "application/json")
-		c.String(http.StatusOK, quotesJSON)
+// GetAllQuotesHandler returns every public quote plus the viewer's own
+func (a *App) GetAllQuotesHandler(c *gin.Context) {
+	viewerID := c.GetInt("user_id")
+	quotes, err := a.Quotes.GetFeedQuotes(c.Request.Context(), viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
 	}
+	a.markLiked(c, viewerID, quotes)
+
+	c.JSON(http.StatusOK, quotes)
 }
 
 // GetUserQuotesHandler returns all quotes for a specific user (public)
-func GetUserQuotesHandler(db *sql.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userIDStr := c.Param("id")
-		userID, err := strconv.Atoi(userIDStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-			return
-		}
+func (a *App) GetUserQuotesHandler(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
 
-		quotes, err := database.GetQuotesByUserID(c.Request.Context(), db, userID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
-			return
-		}
+	// Owners see all of their quotes; everyone else sees the public ones
+	getQuotes := a.Quotes.GetPublicQuotesByUserID
+	if c.GetInt("user_id") == userID {
+		getQuotes = a.Quotes.GetQuotesByUserID
+	}
 
-		c.JSON(http.StatusOK, gin.H{"quotes": quotes})
+	quotes, err := getQuotes(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+		return
 	}
+
+	c.JSON(http.StatusOK, gin.H{"quotes": quotes})
 }
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateQuote(t *testing.T) {
+	env := newTestEnv(t)
+
+	status, _ := env.client().do(http.MethodPost, "/api/quote", gin.H{"quote": "q", "author": "a", "book": "b"})
+	expectStatus(t, "anonymous create", status, http.StatusUnauthorized)
+
+	alice := env.client()
+	userID := alice.signup("alice")
+
+	status, _ = alice.do(http.MethodPost, "/api/quote", gin.H{"quote": "missing author and book"})
+	expectStatus(t, "invalid create", status, http.StatusBadRequest)
+
+	quoteID := alice.createQuote("You have power over your mind.")
+
+	quote, err := env.store.GetQuoteByID(context.Background(), quoteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.UserID != userID || quote.Book != "Meditations" {
+		t.Fatalf("stored quote = %+v", quote)
+	}
+	if len(env.books.titles) != 1 || env.books.titles[0] != "Meditations" {
+		t.Fatalf("tracked books = %v", env.books.titles)
+	}
+}
+
+func TestQuoteModificationRequiresOwnership(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("original")
+
+	bob := env.client()
+	bob.signup("bob")
+
+	update := gin.H{"quote": "edited", "author": "Seneca", "book": "Letters"}
+	path := fmt.Sprintf("/api/quote/%d", quoteID)
+
+	status, _ := bob.do(http.MethodPut, path, update)
+	expectStatus(t, "update by non-owner", status, http.StatusForbidden)
+
+	status, _ = bob.do(http.MethodDelete, path, nil)
+	expectStatus(t, "delete by non-owner", status, http.StatusForbidden)
+
+	status, _ = alice.do(http.MethodPut, "/api/quote/9999", update)
+	expectStatus(t, "update missing quote", status, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodPut, "/api/quote/abc", update)
+	expectStatus(t, "update invalid id", status, http.StatusBadRequest)
+
+	status, _ = alice.do(http.MethodPut, path, update)
+	expectStatus(t, "update by owner", status, http.StatusOK)
+
+	quote, err := env.store.GetQuoteByID(context.Background(), quoteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Quote != "edited" || quote.Author != "Seneca" {
+		t.Fatalf("quote after update = %+v", quote)
+	}
+}
+
+func TestDeleteQuote(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("short-lived")
+	path := fmt.Sprintf("/api/quote/%d", quoteID)
+
+	status, _ := alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "delete", status, http.StatusOK)
+
+	status, _ = alice.do(http.MethodDelete, path, nil)
+	expectStatus(t, "delete again", status, http.StatusNotFound)
+}
+
+func TestGetUserQuotes(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	alice.createQuote("first")
+	alice.createQuote("second")
+
+	bob := env.client()
+	bob.signup("bob")
+	bob.createQuote("not alice's")
+
+	status, body := env.client().do(http.MethodGet, fmt.Sprintf("/user/%d/quotes", aliceID), nil)
+	expectStatus(t, "list", status, http.StatusOK)
+
+	quotes := body["quotes"].([]interface{})
+	if len(quotes) != 2 {
+		t.Fatalf("got %d quotes, want 2", len(quotes))
+	}
+	first := quotes[0].(map[string]interface{})
+	if first["quote"] != "second" {
+		t.Fatalf("first quote = %v, want newest first", first["quote"])
+	}
+	if first["created_at"] == nil || first["updated_at"] == nil {
+		t.Fatalf("quote is missing timestamps: %v", first)
+	}
+}
+
+func TestDeviceCreateQuote(t *testing.T) {
+	env := newTestEnv(t)
+	if id := env.client().signup("pi_device"); id != testDeviceUserID {
+		t.Fatalf("device user id = %d, want %d", id, testDeviceUserID)
+	}
+
+	quote := gin.H{"quote": "captured", "author": "Unknown", "book": "Unknown"}
+
+	device := env.client()
+	status, _ := device.do(http.MethodPost, "/api/device/quote", quote)
+	expectStatus(t, "missing token", status, http.StatusUnauthorized)
+
+	device.header.Set("Authorization", "Bearer wrong")
+	status, _ = device.do(http.MethodPost, "/api/device/quote", quote)
+	expectStatus(t, "wrong token", status, http.StatusUnauthorized)
+
+	device.header.Set("Authorization", "Bearer "+testDeviceToken)
+	status, body := device.do(http.MethodPost, "/api/device/quote", quote)
+	expectStatus(t, "device create", status, http.StatusCreated)
+
+	stored, err := env.store.GetQuoteByID(context.Background(), int(body["quote_id"].(float64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.UserID != testDeviceUserID {
+		t.Fatalf("device quote owner = %d, want %d", stored.UserID, testDeviceUserID)
+	}
+}
+
+func TestQuoteVisibility(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	alice.createQuote("public")
+
+	for _, visibility := range []string{"unlisted", "private"} {
+		status, _ := alice.do(http.MethodPost, "/api/quote", gin.H{
+			"quote": visibility, "author": "a", "book": "b", "visibility": visibility,
+		})
+		expectStatus(t, "create "+visibility, status, http.StatusCreated)
+	}
+
+	status, _ := alice.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "q", "author": "a", "book": "b", "visibility": "secret",
+	})
+	expectStatus(t, "invalid visibility", status, http.StatusBadRequest)
+
+	path := fmt.Sprintf("/user/%d/quotes", aliceID)
+	listed := func(c *testClient) []string {
+		_, body := c.do(http.MethodGet, path, nil)
+		var texts []string
+		for _, q := range body["quotes"].([]interface{}) {
+			texts = append(texts, q.(map[string]interface{})["quote"].(string))
+		}
+		return texts
+	}
+
+	bob := env.client()
+	bob.signup("bob")
+	if got := listed(bob); len(got) != 1 || got[0] != "public" {
+		t.Fatalf("bob sees %v, want only the public quote", got)
+	}
+	if got := listed(env.client()); len(got) != 1 {
+		t.Fatalf("anonymous visitor sees %v, want only the public quote", got)
+	}
+	if got := listed(alice); len(got) != 3 {
+		t.Fatalf("alice sees %v, want all three quotes", got)
+	}
+
+	feed, err := env.store.GetFeedQuotes(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed) != 1 || feed[0].Visibility != "public" {
+		t.Fatalf("anonymous feed = %+v, want only the public quote", feed)
+	}
+}
+
+func TestQuoteVisibilityDefault(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	ctx := context.Background()
+
+	visibilityOf := func(quoteID int) string {
+		q, err := env.store.GetQuoteByID(ctx, quoteID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q.Visibility
+	}
+
+	if v := visibilityOf(alice.createQuote("default")); v != "public" {
+		t.Fatalf("new account default = %q, want public", v)
+	}
+
+	alice.do(http.MethodPut, "/api/user/privacy", gin.H{"quotes_public": false})
+	if v := visibilityOf(alice.createQuote("quotes hidden")); v != "private" {
+		t.Fatalf("default with quotes_public off = %q, want private", v)
+	}
+
+	status, _ := alice.do(http.MethodPut, "/api/user/privacy", gin.H{"default_visibility": "unlisted"})
+	expectStatus(t, "set default visibility", status, http.StatusOK)
+	quoteID := alice.createQuote("unlisted by default")
+	if v := visibilityOf(quoteID); v != "unlisted" {
+		t.Fatalf("chosen default = %q, want unlisted", v)
+	}
+
+	status, _ = alice.do(http.MethodPut, fmt.Sprintf("/api/quote/%d", quoteID), gin.H{"quote": "edited", "author": "a", "book": "b"})
+	expectStatus(t, "update without visibility", status, http.StatusOK)
+	if v := visibilityOf(quoteID); v != "unlisted" {
+		t.Fatalf("visibility after update = %q, want it unchanged", v)
+	}
+}
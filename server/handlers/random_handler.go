@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// Response formats for a single picked quote
+const (
+	quoteFormatJSON = "json"
+	quoteFormatText = "text"
+	quoteFormatHTML = "html"
+)
+
+// fortuneWidth is where plain-text quotes are wrapped
+const fortuneWidth = 72
+
+// quoteFilter reads the tag, author and user query parameters for the
+// current viewer. user is a username; an unknown one returns ErrUserNotFound.
+func (a *App) quoteFilter(c *gin.Context) (models.QuoteFilter, error) {
+	filter := models.QuoteFilter{
+		ViewerID: c.GetInt("user_id"),
+		Tag:      strings.TrimSpace(c.Query("tag")),
+		Author:   strings.TrimSpace(c.Query("author")),
+	}
+
+	if username := strings.TrimSpace(c.Query("user")); username != "" {
+		user, err := a.Users.GetUserByUsername(c.Request.Context(), username)
+		if err != nil {
+			return filter, err
+		}
+		filter.UserID = user.ID
+	}
+
+	return filter, nil
+}
+
+// RandomQuoteHandler returns a random quote the viewer may see, optionally
+// filtered by tag, author and user
+func (a *App) RandomQuoteHandler(c *gin.Context) {
+	filter, err := a.quoteFilter(c)
+	if err != nil {
+		pickedQuoteError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := a.Quotes.CountFilteredQuotes(ctx, filter)
+	if err != nil {
+		pickedQuoteError(c, err)
+		return
+	}
+	if count == 0 {
+		pickedQuoteError(c, database.ErrQuoteNotFound)
+		return
+	}
+
+	quote, err := a.Quotes.GetFilteredQuoteAt(ctx, filter, rand.IntN(count))
+	if err != nil {
+		pickedQuoteError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	renderPickedQuote(c, quote, nil)
+}
+
+// QuoteOfTheDayHandler returns the quote of the day for the current UTC
+// date. Everyone gets the same global quote, picked from public quotes;
+// with ?user= the quote comes from that user's collection, which includes
+// their private quotes when they ask for it themselves. Tag and author
+// filters pick a separate quote of the day.
+func (a *App) QuoteOfTheDayHandler(c *gin.Context) {
+	filter, err := a.quoteFilter(c)
+	if err != nil {
+		pickedQuoteError(c, err)
+		return
+	}
+	if filter.UserID == 0 {
+		filter.ViewerID = 0
+	}
+
+	day := today()
+
+	quote, err := a.Quotes.GetDailyFilteredQuote(c.Request.Context(), filter, qotdKey(day, qotdSeed(filter)))
+	if err != nil {
+		pickedQuoteError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	renderPickedQuote(c, quote, gin.H{"date": day.Format("2006-01-02")})
+}
+
+// today is the start of the current UTC day
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// qotdSeed identifies the set of quotes filter picks from, so each filter
+// gets its own quote of the day
+func qotdSeed(filter models.QuoteFilter) string {
+	return strings.Join([]string{strconv.Itoa(filter.UserID), filter.Tag, strings.ToLower(filter.Author)}, "|")
+}
+
+// qotdKey identifies the quote of the day for day and seed. The pick ranks
+// each candidate by its own ID under this key, so adding, deleting or hiding
+// other quotes doesn't change it.
+func qotdKey(day time.Time, seed string) string {
+	return day.Format("2006-01-02") + "|" + seed + "|"
+}
+
+// dailyQuote picks the quote of the day for key from quotes, ranking them
+// the same way as the store's GetDailyFilteredQuote
+func dailyQuote(key string, quotes models.Quotes) models.Quote {
+	best := quotes[0]
+	for _, q := range quotes[1:] {
+		if database.QuoteRank(key, q.QuoteID) < database.QuoteRank(key, best.QuoteID) {
+			best = q
+		}
+	}
+	return best
+}
+
+// pickedQuoteFormat picks the response format for a single quote: the
+// format query parameter when given, an HTML fragment for HTMX requests,
+// and otherwise the Accept header. A missing or */* Accept header, as sent
+// by curl, gets plain text.
+func pickedQuoteFormat(c *gin.Context) string {
+	switch format := c.Query("format"); format {
+	case quoteFormatJSON, quoteFormatText, quoteFormatHTML:
+		return format
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		return quoteFormatHTML
+	}
+
+	if accept := c.GetHeader("Accept"); accept == "" || accept == "*/*" {
+		return quoteFormatText
+	}
+	switch c.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain, gin.MIMEHTML) {
+	case gin.MIMEPlain:
+		return quoteFormatText
+	case gin.MIMEHTML:
+		return quoteFormatHTML
+	default:
+		return quoteFormatJSON
+	}
+}
+
+// renderPickedQuote writes quote in the negotiated format. extra is merged
+// into the JSON response.
+func renderPickedQuote(c *gin.Context, quote *models.Quote, extra gin.H) {
+	c.Header("Vary", "Accept, HX-Request")
+
+	switch pickedQuoteFormat(c) {
+	case quoteFormatText:
+		c.String(http.StatusOK, fortune(quote))
+	case quoteFormatHTML:
+		c.HTML(http.StatusOK, "quote-fragment.html", gin.H{"quote": quote})
+	default:
+		body := gin.H{"quote": quote}
+		for k, v := range extra {
+			body[k] = v
+		}
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+// pickedQuoteError reports a failed pick as plain text to plain-text
+// clients and as JSON to everyone else
+func pickedQuoteError(c *gin.Context, err error) {
+	status, message := http.StatusInternalServerError, "Failed to fetch quote"
+	switch {
+	case errors.Is(err, database.ErrUserNotFound):
+		status, message = http.StatusNotFound, "User not found"
+	case errors.Is(err, database.ErrQuoteNotFound):
+		status, message = http.StatusNotFound, "No quotes found"
+	}
+
+	c.Header("Vary", "Accept, HX-Request")
+	if pickedQuoteFormat(c) == quoteFormatText {
+		c.String(status, message+"\n")
+		return
+	}
+	c.JSON(status, gin.H{"error": message})
+}
+
+// fortune formats a quote like fortune(6): the wrapped quote followed by an
+// indented attribution line
+func fortune(q *models.Quote) string {
+	var b strings.Builder
+	for _, paragraph := range strings.Split(strings.TrimSpace(q.Quote), "\n") {
+		b.WriteString(wrapText(paragraph, fortuneWidth))
+		b.WriteByte('\n')
+	}
+
+	if q.Author != "" {
+		b.WriteString("\t\t-- ")
+		b.WriteString(q.Author)
+		if q.Book != "" {
+			b.WriteString(", ")
+			b.WriteString(q.Book)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// wrapText breaks s into lines of at most width characters at spaces.
+// Words longer than width get a line of their own.
+func wrapText(s string, width int) string {
+	var b strings.Builder
+	lineLen := 0
+	for _, word := range strings.Fields(s) {
+		n := len([]rune(word))
+		if lineLen > 0 && lineLen+1+n > width {
+			b.WriteByte('\n')
+			lineLen = 0
+		} else if lineLen > 0 {
+			b.WriteByte(' ')
+			lineLen++
+		}
+		b.WriteString(word)
+		lineLen += n
+	}
+	return b.String()
+}
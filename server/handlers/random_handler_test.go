@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getRaw fetches path with the given Accept header and returns the response
+// content type and body without decoding them
+func (c *testClient) getRaw(path, accept string) (int, string, string) {
+	t := c.env.t
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, c.env.server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+}
+
+// pickedQuote fetches a random or daily quote as JSON and returns its text,
+// or "" when none matched
+func (c *testClient) pickedQuote(path string) string {
+	t := c.env.t
+	t.Helper()
+
+	status, body := c.do(http.MethodGet, path, nil)
+	if status == http.StatusNotFound {
+		return ""
+	}
+	expectStatus(t, path, status, http.StatusOK)
+	return body["quote"].(map[string]interface{})["quote"].(string)
+}
+
+func setupPickedQuotes(t *testing.T) (*testEnv, *testClient) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("Waste no more time arguing what a good man should be")
+	status, _ := alice.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "A private thought", "author": "Alice", "book": "Journal",
+		"tags": []string{"secret"}, "visibility": "private",
+	})
+	expectStatus(t, "create private quote", status, http.StatusCreated)
+
+	bob := env.client()
+	bob.signup("bob")
+	status, _ = bob.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "Hope is the thing with feathers", "author": "Emily Dickinson", "book": "Poems", "tags": []string{"poetry"},
+	})
+	expectStatus(t, "create quote", status, http.StatusCreated)
+
+	alice.header.Set("Accept", "application/json")
+	return env, alice
+}
+
+func TestRandomQuoteFilters(t *testing.T) {
+	env, alice := setupPickedQuotes(t)
+	anonymous := env.client()
+	anonymous.header.Set("Accept", "application/json")
+
+	tests := []struct {
+		client *testClient
+		query  string
+		want   string
+	}{
+		{anonymous, "?user=alice", "Waste no more time arguing what a good man should be"},
+		{anonymous, "?author=dickinson", "Hope is the thing with feathers"},
+		{anonymous, "?tag=poetry", "Hope is the thing with feathers"},
+		{anonymous, "?tag=secret", ""},
+		{alice, "?tag=secret", "A private thought"},
+		{alice, "?user=bob&tag=secret", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.client.pickedQuote("/api/random" + tt.query); got != tt.want {
+			t.Errorf("random%s = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	status, _ := anonymous.do(http.MethodGet, "/api/random?user=nobody", nil)
+	expectStatus(t, "unknown user", status, http.StatusNotFound)
+}
+
+func TestQuoteOfTheDay(t *testing.T) {
+	env, alice := setupPickedQuotes(t)
+	anonymous := env.client()
+	anonymous.header.Set("Accept", "application/json")
+
+	// Everyone gets the same global quote of the day, and never a private one
+	global := anonymous.pickedQuote("/api/qotd")
+	if global == "" || global == "A private thought" {
+		t.Fatalf("global qotd = %q", global)
+	}
+	for i := 0; i < 5; i++ {
+		if got := alice.pickedQuote("/api/qotd"); got != global {
+			t.Fatalf("qotd for alice = %q, want the global %q", got, global)
+		}
+	}
+
+	_, body := anonymous.do(http.MethodGet, "/api/qotd?user=bob", nil)
+	if body["date"] == nil || body["quote"].(map[string]interface{})["author"] != "Emily Dickinson" {
+		t.Fatalf("qotd for bob = %v", body)
+	}
+	if got := anonymous.pickedQuote("/api/qotd?tag=secret"); got != "" {
+		t.Fatalf("qotd with a private tag = %q, want none", got)
+	}
+}
+
+func TestQuoteOfTheDayIsStable(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	ids := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		text := "Quote number " + strconv.Itoa(i)
+		ids[text] = alice.createQuote(text)
+	}
+
+	bob := env.client()
+	bob.signup("bob")
+	bob.header.Set("Accept", "application/json")
+	pick := bob.pickedQuote("/api/qotd?user=alice")
+
+	// Hiding, adding and deleting other quotes leaves the pick alone
+	others := make([]int, 0, len(ids))
+	for text, id := range ids {
+		if text != pick {
+			others = append(others, id)
+		}
+	}
+	status, _ := bob.do(http.MethodPost, "/api/quote/"+strconv.Itoa(others[0])+"/hide", nil)
+	expectStatus(t, "hide quote", status, http.StatusOK)
+	if got := bob.pickedQuote("/api/qotd?user=alice"); got != pick {
+		t.Fatalf("qotd after hiding another quote = %q, want %q", got, pick)
+	}
+
+	for _, id := range others[1:] {
+		status, _ := alice.do(http.MethodDelete, "/api/quote/"+strconv.Itoa(id), nil)
+		expectStatus(t, "delete quote", status, http.StatusOK)
+		if got := bob.pickedQuote("/api/qotd?user=alice"); got != pick {
+			t.Fatalf("qotd after deleting quote %d = %q, want %q", id, got, pick)
+		}
+	}
+}
+
+func TestPickedQuoteFormats(t *testing.T) {
+	env, _ := setupPickedQuotes(t)
+	anonymous := env.client()
+
+	// curl sends Accept: */* and gets a fortune-style quote
+	status, contentType, body := anonymous.getRaw("/api/random?user=bob", "*/*")
+	expectStatus(t, "text quote", status, http.StatusOK)
+	if !strings.HasPrefix(contentType, "text/plain") || body != "Hope is the thing with feathers\n\t\t-- Emily Dickinson, Poems\n" {
+		t.Fatalf("text quote = %s %q", contentType, body)
+	}
+
+	status, _, body = anonymous.getRaw("/api/random?user=nobody", "")
+	if status != http.StatusNotFound || body != "User not found\n" {
+		t.Fatalf("text error = %d %q", status, body)
+	}
+
+	_, contentType, _ = anonymous.getRaw("/api/qotd?format=json", "text/plain")
+	if !strings.HasPrefix(contentType, "application/json") {
+		t.Fatalf("format=json content type = %s", contentType)
+	}
+	_, contentType, _ = anonymous.getRaw("/api/qotd", "application/json, text/plain;q=0.5")
+	if !strings.HasPrefix(contentType, "application/json") {
+		t.Fatalf("negotiated content type = %s", contentType)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("one two three four", 9)
+	if want := "one two\nthree\nfour"; got != want {
+		t.Fatalf("wrapText = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// reviewFilter reads the tag and exclude_tag query parameters, each of
+// which may be repeated
+func reviewFilter(c *gin.Context) models.ReviewFilter {
+	clean := func(tags []string) []string {
+		out := make([]string, 0, len(tags))
+		for _, t := range tags {
+			if t = strings.TrimSpace(t); t != "" {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	return models.ReviewFilter{Tags: clean(c.QueryArray("tag")), ExcludeTags: clean(c.QueryArray("exclude_tag"))}
+}
+
+// GetNextReviewHandler returns the current user's next quote due for review
+// as a cloze for the chosen mode, and how many quotes are due in all. The
+// full quote is only sent once the review is graded. The quote ID is null
+// when nothing is due.
+func (a *App) GetNextReviewHandler(c *gin.Context) {
+	mode := c.DefaultQuery("cloze", models.ClozeWords)
+	if !services.ValidClozeMode(mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cloze must be words or author"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+	filter := reviewFilter(c)
+	now := time.Now()
+
+	card, err := a.Reviews.GetNextReview(ctx, userID, now, filter)
+	if errors.Is(err, database.ErrNoReviewDue) {
+		c.JSON(http.StatusOK, gin.H{"quote_id": nil, "due": 0})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch next review"})
+		return
+	}
+
+	due, err := a.Reviews.CountDueReviews(ctx, userID, now, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch next review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quote_id": card.Quote.QuoteID,
+		"review":   card.Review,
+		"cloze":    services.BuildCloze(card.Quote, mode),
+		"due":      due,
+	})
+}
+
+// GradeReviewHandler records how well the current user recalled one of
+// their quotes, schedules its next review and returns the full quote as
+// the answer
+func (a *App) GradeReviewHandler(c *gin.Context) {
+	var req models.GradeReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "grade must be between 0 and 5"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	quote, err := a.Quotes.GetQuoteByID(ctx, c.GetInt("quote_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		return
+	}
+
+	review, err := a.Reviews.GetQuoteReview(ctx, userID, quote.QuoteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch review"})
+		return
+	}
+
+	review.Grade(*req.Grade, time.Now())
+	if err := a.Reviews.SaveQuoteReview(ctx, userID, review); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Review saved", "review": review, "quote": quote})
+}
+
+// ReviewPageHandler renders the spaced-repetition review page
+func (a *App) ReviewPageHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	user, err := a.Users.GetUserByID(ctx, c.GetInt("user_id"))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	// Offer the user's own tags for the include/exclude filter
+	quotes, err := a.Quotes.GetQuotesByUserID(ctx, user.ID)
+	if err != nil {
+		quotes = nil
+	}
+	seen := make(map[string]bool)
+	tags := make([]string, 0)
+	for _, q := range quotes {
+		for _, t := range q.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+
+	c.HTML(http.StatusOK, "review.html", gin.H{
+		"title": "Review",
+		"user":  user.ToResponse(),
+		"tags":  tags,
+	})
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// nextReviewID returns the ID of the next quote due for review, or 0 when
+// nothing is due
+func (c *testClient) nextReviewID(query string) int {
+	t := c.env.t
+	t.Helper()
+
+	status, body := c.do(http.MethodGet, "/api/review/next"+query, nil)
+	expectStatus(t, "next review", status, http.StatusOK)
+	if body["quote_id"] == nil {
+		return 0
+	}
+	return int(body["quote_id"].(float64))
+}
+
+func TestReviewScheduling(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	first := alice.createQuote("You have power over your mind, not outside events")
+	second := alice.createQuote("The best revenge is not to be like that")
+
+	// Unreviewed quotes come up oldest first
+	status, body := alice.do(http.MethodGet, "/api/review/next", nil)
+	expectStatus(t, "next review", status, http.StatusOK)
+	if body["due"] != float64(2) || body["review"] != nil {
+		t.Fatalf("first review = %v, want 2 due and no review state", body)
+	}
+	if got := alice.nextReviewID(""); got != first {
+		t.Fatalf("next review = %d, want %d", got, first)
+	}
+
+	status, _ = alice.do(http.MethodPost, "/api/review/"+strconv.Itoa(first), gin.H{"grade": 6})
+	expectStatus(t, "grade out of range", status, http.StatusBadRequest)
+	status, _ = alice.do(http.MethodPost, "/api/review/"+strconv.Itoa(first), gin.H{})
+	expectStatus(t, "missing grade", status, http.StatusBadRequest)
+
+	status, body = alice.do(http.MethodPost, "/api/review/"+strconv.Itoa(first), gin.H{"grade": 4})
+	expectStatus(t, "grade", status, http.StatusOK)
+	review := body["review"].(map[string]interface{})
+	if review["interval_days"] != float64(1) || review["repetitions"] != float64(1) {
+		t.Fatalf("review after first pass = %v, want a 1 day interval", review)
+	}
+	if answer := body["quote"].(map[string]interface{}); answer["quote"] != "You have power over your mind, not outside events" {
+		t.Fatalf("graded answer = %v, want the full quote", answer)
+	}
+
+	if got := alice.nextReviewID(""); got != second {
+		t.Fatalf("next review = %d, want %d", got, second)
+	}
+	// A grade of 0 is a valid blackout, not a missing grade
+	status, _ = alice.do(http.MethodPost, "/api/review/"+strconv.Itoa(second), gin.H{"grade": 0})
+	expectStatus(t, "blackout grade", status, http.StatusOK)
+
+	if got := alice.nextReviewID(""); got != 0 {
+		t.Fatalf("next review after grading everything = %d, want none", got)
+	}
+
+	// Only the owner can review a quote
+	bob := env.client()
+	bob.signup("bob")
+	status, _ = bob.do(http.MethodPost, "/api/review/"+strconv.Itoa(first), gin.H{"grade": 5})
+	expectStatus(t, "grade another user's quote", status, http.StatusForbidden)
+	if got := bob.nextReviewID(""); got != 0 {
+		t.Fatalf("bob's next review = %d, want none", got)
+	}
+}
+
+func TestReviewTagFilter(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	stoic := alice.createQuote("Waste no more time arguing what a good man should be")
+	status, body := alice.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "Hope is the thing with feathers", "author": "Emily Dickinson", "book": "Poems", "tags": []string{"poetry"},
+	})
+	expectStatus(t, "create quote", status, http.StatusCreated)
+	poem := int(body["quote_id"].(float64))
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", stoic},
+		{"?tag=poetry", poem},
+		{"?exclude_tag=stoicism", poem},
+		{"?tag=poetry&tag=stoicism", stoic},
+		{"?tag=stoicism&exclude_tag=stoicism", 0},
+	}
+	for _, tt := range tests {
+		if got := alice.nextReviewID(tt.query); got != tt.want {
+			t.Errorf("next review%s = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestReviewCloze(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	text := "The impediment to action advances action"
+	alice.createQuote(text)
+
+	status, _ := alice.do(http.MethodGet, "/api/review/next?cloze=everything", nil)
+	expectStatus(t, "unknown cloze mode", status, http.StatusBadRequest)
+
+	// The prompt never carries the answer
+	_, body := alice.do(http.MethodGet, "/api/review/next", nil)
+	if _, ok := body["quote"]; ok || body["quote_id"] == nil {
+		t.Fatalf("next review = %v, want only the quote ID and cloze", body)
+	}
+	cloze := body["cloze"].(map[string]interface{})
+	var shown strings.Builder
+	blanks := 0
+	for _, p := range cloze["parts"].([]interface{}) {
+		part := p.(map[string]interface{})
+		if part["hidden"] == true {
+			if _, ok := part["text"]; ok || part["length"] != float64(len("impediment")) {
+				t.Fatalf("blank = %v, want only its length", part)
+			}
+			shown.WriteString("___")
+			blanks++
+			continue
+		}
+		shown.WriteString(part["text"].(string))
+	}
+	if shown.String() != "The ___ to action advances action" || blanks != 1 ||
+		cloze["author_hidden"] != false || cloze["author"] != "Marcus Aurelius" {
+		t.Fatalf("word cloze = %v, want %q with only \"impediment\" hidden", cloze, text)
+	}
+
+	_, body = alice.do(http.MethodGet, "/api/review/next?cloze=author", nil)
+	cloze = body["cloze"].(map[string]interface{})
+	if cloze["author_hidden"] != true || len(cloze["parts"].([]interface{})) != 1 || cloze["author"] != nil || cloze["book"] != nil {
+		t.Fatalf("author cloze = %v, want the whole quote shown and the author hidden", cloze)
+	}
+}
+
+func TestQuoteReviewGrade(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	r := models.NewQuoteReview(1)
+
+	// Perfect recall: intervals of 1, 6, then 6 × ease
+	for _, want := range []int{1, 6, 16} {
+		r.Grade(models.MaxGrade, now)
+		if r.IntervalDays != want {
+			t.Fatalf("interval = %d, want %d", r.IntervalDays, want)
+		}
+	}
+	if !r.DueAt.Equal(now.AddDate(0, 0, 16)) {
+		t.Fatalf("due = %v, want 16 days after %v", r.DueAt, now)
+	}
+
+	// A failed recall starts over with a lower ease
+	ease := r.EaseFactor
+	r.Grade(1, now)
+	if r.IntervalDays != 1 || r.Repetitions != 0 || r.EaseFactor >= ease {
+		t.Fatalf("review after a fail = %+v", r)
+	}
+	for i := 0; i < 10; i++ {
+		r.Grade(models.MinGrade, now)
+	}
+	if r.EaseFactor != models.MinEaseFactor {
+		t.Fatalf("ease after repeated blackouts = %v, want %v", r.EaseFactor, models.MinEaseFactor)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// GetQuoteRevisionsHandler lists a quote's earlier versions, newest first.
+// Each revision carries the field changes made by the edit that replaced it.
+func (a *App) GetQuoteRevisionsHandler(c *gin.Context) {
+	quoteID := c.GetInt("quote_id")
+	ctx := c.Request.Context()
+
+	quote, err := a.Quotes.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		}
+		return
+	}
+
+	revisions, err := a.Quotes.GetQuoteRevisions(ctx, quoteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	next := *quote
+	for i := range revisions {
+		revisions[i].Changes = models.DiffQuotes(revisions[i].AsQuote(), next)
+		next = revisions[i].AsQuote()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quote": quote, "revisions": revisions})
+}
+
+// RestoreQuoteRevisionHandler replaces a quote's content with an earlier
+// revision. The replaced content becomes a new revision.
+func (a *App) RestoreQuoteRevisionHandler(c *gin.Context) {
+	quoteID := c.GetInt("quote_id")
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := a.Quotes.RestoreQuoteRevision(ctx, quoteID, revision); err != nil {
+		switch {
+		case errors.Is(err, database.ErrRevisionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		case errors.Is(err, database.ErrQuoteNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		}
+		return
+	}
+
+	quote, err := a.Quotes.GetQuoteByID(ctx, quoteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quote"})
+		return
+	}
+
+	a.Enricher.TrackBook(ctx, c.GetInt("user_id"), quote.Book, quote.Author)
+	a.emitQuoteEvent(c, models.WebhookQuoteUpdated, quote)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Quote restored successfully", "quote": quote})
+}
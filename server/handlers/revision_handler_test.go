@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestQuoteRevisions(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("The obstacle is the way.")
+	path := fmt.Sprintf("/api/quote/%d", quoteID)
+
+	edits := []gin.H{
+		{"quote": "The impediment to action advances action.", "author": "Marcus Aurelius", "book": "Meditations", "tags": []string{"stoicism"}},
+		// Saving unchanged content must not add a revision
+		{"quote": "The impediment to action advances action.", "author": "Marcus Aurelius", "book": "Meditations", "tags": []string{"stoicism"}},
+		{"quote": "The impediment to action advances action.", "author": "Marcus Aurelius", "book": "Meditations", "tags": []string{"action"}, "page": "5.20"},
+	}
+	for i, edit := range edits {
+		status, _ := alice.do(http.MethodPut, path, edit)
+		expectStatus(t, fmt.Sprintf("edit %d", i), status, http.StatusOK)
+	}
+
+	status, body := alice.do(http.MethodGet, path+"/revisions", nil)
+	expectStatus(t, "list revisions", status, http.StatusOK)
+
+	revisions := body["revisions"].([]interface{})
+	if len(revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(revisions))
+	}
+
+	latest := revisions[0].(map[string]interface{})
+	if latest["revision"].(float64) != 2 {
+		t.Fatalf("newest revision = %v, want 2", latest["revision"])
+	}
+	changes := latest["changes"].([]interface{})
+	if len(changes) != 2 {
+		t.Fatalf("revision 2 changes = %v, want tags and page", changes)
+	}
+	tags := changes[0].(map[string]interface{})
+	if tags["field"] != "tags" || tags["added"].([]interface{})[0] != "action" || tags["removed"].([]interface{})[0] != "stoicism" {
+		t.Fatalf("tag change = %v", tags)
+	}
+
+	first := revisions[1].(map[string]interface{})
+	change := first["changes"].([]interface{})[0].(map[string]interface{})
+	if change["field"] != "quote" || change["old"] != "The obstacle is the way." {
+		t.Fatalf("revision 1 change = %v", change)
+	}
+
+	status, body = alice.do(http.MethodPost, path+"/revisions/1/restore", nil)
+	expectStatus(t, "restore", status, http.StatusOK)
+	restored := body["quote"].(map[string]interface{})
+	if restored["quote"] != "The obstacle is the way." || restored["page"] != "" {
+		t.Fatalf("restored quote = %v", restored)
+	}
+
+	_, body = alice.do(http.MethodGet, path+"/revisions", nil)
+	if got := len(body["revisions"].([]interface{})); got != 3 {
+		t.Fatalf("got %d revisions after restore, want 3", got)
+	}
+}
+
+func TestRestoreQuoteRevisionErrors(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("original")
+	path := fmt.Sprintf("/api/quote/%d", quoteID)
+
+	status, _ := alice.do(http.MethodPut, path, gin.H{"quote": "edited", "author": "a", "book": "b"})
+	expectStatus(t, "edit", status, http.StatusOK)
+
+	status, _ = alice.do(http.MethodPost, path+"/revisions/7/restore", nil)
+	expectStatus(t, "missing revision", status, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodPost, path+"/revisions/latest/restore", nil)
+	expectStatus(t, "invalid revision", status, http.StatusBadRequest)
+
+	bob := env.client()
+	bob.signup("bob")
+
+	status, _ = bob.do(http.MethodGet, path+"/revisions", nil)
+	expectStatus(t, "list by non-owner", status, http.StatusForbidden)
+
+	status, _ = bob.do(http.MethodPost, path+"/revisions/1/restore", nil)
+	expectStatus(t, "restore by non-owner", status, http.StatusForbidden)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/middleware"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// Routes registers every page and API route on r. Sessions, templates and
+// static files are configured by the caller.
+func (a *App) Routes(r *gin.Engine) {
+	// Liveness and readiness endpoints (used by k8s probes)
+	r.GET("/healthz", a.HealthzHandler)
+	r.GET("/readyz", a.ReadyzHandler)
+
+	// Public page routes
+	r.GET("/", a.HomePageHandler)
+	r.GET("/explore", a.HomePageHandler)
+	r.GET("/login", a.LoginPageHandler)
+	r.GET("/signup", a.SignupPageHandler)
+	r.GET("/forgot-password", a.ForgotPasswordPageHandler)
+	r.GET("/reset-password", a.ResetPasswordPageHandler)
+
+	// Public API routes
+	r.GET("/user/:id/quotes", middleware.OptionalAuth(), a.GetUserQuotesHandler)
+	r.GET("/quote/:id/citation", middleware.OptionalAuth(), a.GetCitationHandler)
+	r.GET("/quote/:id/card.png", middleware.OptionalAuth(), a.QuoteCardHandler)
+	r.GET("/u/:username", a.UserPageHandler)
+	r.GET("/collection/:id", a.CollectionPageHandler)
+	r.GET("/s/:token", a.SharePageHandler)
+
+	// Single quotes for terminals, dashboards and HTMX, as JSON, text or HTML
+	r.GET("/api/random", middleware.OptionalAuth(), a.RandomQuoteHandler)
+	r.GET("/api/qotd", middleware.OptionalAuth(), a.QuoteOfTheDayHandler)
+
+	// Atom, RSS and JSON feeds of public quotes
+	r.GET("/u/:username/feed.xml", a.UserFeedHandler(services.FeedFormatAtom))
+	r.GET("/u/:username/rss.xml", a.UserFeedHandler(services.FeedFormatRSS))
+	r.GET("/u/:username/feed.json", a.UserFeedHandler(services.FeedFormatJSON))
+	r.GET("/tag/:tag/feed.xml", a.TagFeedHandler(services.FeedFormatAtom))
+	r.GET("/tag/:tag/rss.xml", a.TagFeedHandler(services.FeedFormatRSS))
+	r.GET("/tag/:tag/feed.json", a.TagFeedHandler(services.FeedFormatJSON))
+
+	// Quote widgets for other sites to show in an iframe
+	r.GET("/embed/:username", a.EmbedUserHandler)
+	r.GET("/embed/collection/:id", a.EmbedCollectionHandler)
+
+	// Digest unsubscribe links carry their own token instead of a session
+	r.GET("/digest/unsubscribe", a.UnsubscribeDigestPageHandler)
+	r.POST("/digest/unsubscribe", a.UnsubscribeDigestHandler)
+
+	// Authentication routes
+	authGroup := r.Group("/auth")
+	{
+		authGroup.POST("/signup", a.SignupHandler)
+		authGroup.POST("/login", a.LoginHandler)
+		authGroup.POST("/logout", a.LogoutHandler)
+		authGroup.POST("/forgot-password", a.ForgotPasswordHandler)
+		authGroup.POST("/reset-password", a.ResetPasswordHandler)
+	}
+
+	// Protected page routes - require authentication
+	r.GET("/settings", middleware.AuthRequired(), a.SettingsPageHandler)
+	r.GET("/settings/hidden", middleware.AuthRequired(), a.HiddenPageHandler)
+	r.GET("/profile", middleware.AuthRequired(), a.ProfilePageHandler)
+	r.GET("/books", middleware.AuthRequired(), a.BooksPageHandler)
+	r.GET("/collections", middleware.AuthRequired(), a.CollectionsPageHandler)
+	r.GET("/trash", middleware.AuthRequired(), a.TrashPageHandler)
+	r.GET("/feed", middleware.AuthRequired(), a.FeedPageHandler)
+	r.GET("/review", middleware.AuthRequired(), a.ReviewPageHandler)
+
+	// Protected API routes - require authentication
+	apiGroup := r.Group("/api")
+	apiGroup.Use(middleware.AuthRequired())
+	{
+		// Get current user
+		apiGroup.GET("/user", a.GetCurrentUserHandler)
+
+		// User settings
+		apiGroup.PUT("/user/profile", a.UpdateProfileHandler)
+		apiGroup.PUT("/user/password", a.UpdatePasswordHandler)
+		apiGroup.PUT("/user/privacy", a.UpdatePrivacyHandler)
+
+		// Quote creation
+		apiGroup.POST("/quote", a.CreateQuoteHandler)
+
+		// Bulk quote actions (ownership is checked per quote)
+		apiGroup.POST("/quotes/bulk", a.BulkQuotesHandler)
+
+		// Quote modification (requires ownership)
+		quoteOwner := middleware.QuoteOwnershipRequired(a.Quotes)
+		apiGroup.PUT("/quote/:id", quoteOwner, a.UpdateQuoteHandler)
+		apiGroup.DELETE("/quote/:id", quoteOwner, a.DeleteQuoteHandler)
+		apiGroup.POST("/quote/:id/share", quoteOwner, a.CreateQuoteShareHandler)
+		apiGroup.GET("/quote/:id/revisions", quoteOwner, a.GetQuoteRevisionsHandler)
+		apiGroup.POST("/quote/:id/revisions/:rev/restore", quoteOwner, a.RestoreQuoteRevisionHandler)
+		apiGroup.GET("/quote/:id/saves", quoteOwner, a.GetQuoteSaversHandler)
+
+		// Spaced-repetition review of the current user's quotes
+		apiGroup.GET("/review/next", a.GetNextReviewHandler)
+		apiGroup.POST("/review/:id", quoteOwner, a.GradeReviewHandler)
+
+		// Likes and saved copies of other users' quotes
+		apiGroup.POST("/quote/:id/like", a.LikeQuoteHandler)
+		apiGroup.DELETE("/quote/:id/like", a.UnlikeQuoteHandler)
+		apiGroup.POST("/quote/:id/save", a.SaveQuoteHandler)
+
+		// Feed filters (hidden quotes and muted users)
+		apiGroup.GET("/hidden", a.GetHiddenHandler)
+		apiGroup.POST("/quote/:id/hide", a.HideQuoteHandler)
+		apiGroup.DELETE("/quote/:id/hide", a.UnhideQuoteHandler)
+		apiGroup.POST("/user/:id/mute", a.MuteUserHandler)
+		apiGroup.DELETE("/user/:id/mute", a.UnmuteUserHandler)
+
+		// Quote digest email
+		apiGroup.GET("/digest", a.GetDigestHandler)
+		apiGroup.PUT("/digest", a.UpdateDigestHandler)
+		apiGroup.DELETE("/digest", a.DeleteDigestHandler)
+		apiGroup.GET("/digest/preview", a.PreviewDigestHandler)
+
+		// Outgoing webhooks for quote events (modification requires ownership)
+		apiGroup.GET("/webhooks", a.GetWebhooksHandler)
+		apiGroup.POST("/webhooks", a.CreateWebhookHandler)
+		webhookGroup := apiGroup.Group("/webhooks/:id", middleware.WebhookOwnershipRequired(a.Webhooks))
+		{
+			webhookGroup.PUT("", a.UpdateWebhookHandler)
+			webhookGroup.DELETE("", a.DeleteWebhookHandler)
+			webhookGroup.GET("/deliveries", a.GetWebhookDeliveriesHandler)
+			webhookGroup.POST("/deliveries/:delivery_id/redeliver", a.RedeliverWebhookHandler)
+		}
+
+		// Follows and the feed of followed users' public quotes
+		apiGroup.GET("/feed", a.GetFeedHandler)
+		apiGroup.GET("/following", a.GetFollowingHandler)
+		apiGroup.POST("/user/:id/follow", a.FollowUserHandler)
+		apiGroup.DELETE("/user/:id/follow", a.UnfollowUserHandler)
+
+		// Trash (scoped to the current user's deleted quotes)
+		apiGroup.GET("/trash", a.GetTrashHandler)
+		apiGroup.POST("/trash/:id/restore", a.RestoreTrashedQuoteHandler)
+		apiGroup.DELETE("/trash/:id", a.PurgeTrashedQuoteHandler)
+
+		// Share links
+		apiGroup.GET("/shares", a.GetShareLinksHandler)
+		apiGroup.DELETE("/shares/:id", a.RevokeShareLinkHandler)
+
+		// Book metadata (requires ownership)
+		bookOwner := middleware.BookOwnershipRequired(a.DB)
+		apiGroup.GET("/books", a.GetBooksHandler)
+		apiGroup.GET("/books/:id", bookOwner, a.GetBookHandler)
+		apiGroup.POST("/books/:id/confirm", bookOwner, a.ConfirmBookHandler)
+		apiGroup.POST("/books/:id/refresh", bookOwner, a.RefreshBookHandler)
+
+		// Collections (modification requires ownership)
+		apiGroup.GET("/collections", a.GetCollectionsHandler)
+		apiGroup.POST("/collections", a.CreateCollectionHandler)
+		collectionGroup := apiGroup.Group("/collections/:id", middleware.CollectionOwnershipRequired(a.DB))
+		{
+			collectionGroup.GET("", a.GetCollectionHandler)
+			collectionGroup.PUT("", a.UpdateCollectionHandler)
+			collectionGroup.DELETE("", a.DeleteCollectionHandler)
+			collectionGroup.POST("/quotes", a.AddCollectionQuoteHandler)
+			collectionGroup.DELETE("/quotes/:quote_id", a.RemoveCollectionQuoteHandler)
+			collectionGroup.PUT("/order", a.ReorderCollectionHandler)
+			collectionGroup.POST("/share", a.CreateCollectionShareHandler)
+		}
+
+		// Writing prompt generation
+		apiGroup.POST("/generate-prompt", a.GeneratePromptHandler)
+	}
+
+	// Device API routes - token-based auth for Pi client and other devices
+	deviceGroup := r.Group("/api/device")
+	deviceGroup.Use(middleware.APITokenRequired(a.Device))
+	{
+		deviceGroup.POST("/quote", a.CreateQuoteHandler)
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpdateProfile(t *testing.T) {
+	env := newTestEnv(t)
+	env.client().signup("bob")
+
+	alice := env.client()
+	aliceID := alice.signup("alice")
+
+	status, _ := alice.do(http.MethodPut, "/api/user/profile", gin.H{"username": "bob"})
+	expectStatus(t, "taken username", status, http.StatusConflict)
+
+	status, _ = alice.do(http.MethodPut, "/api/user/profile", gin.H{"bio": "Reader of old books"})
+	expectStatus(t, "update bio", status, http.StatusOK)
+
+	user, err := env.store.GetUserByID(context.Background(), aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice" || user.Bio != "Reader of old books" {
+		t.Fatalf("user after update = %+v", user)
+	}
+}
+
+func TestUpdatePrivacy(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+
+	status, _ := alice.do(http.MethodPut, "/api/user/privacy", gin.H{"profile_public": false, "quotes_public": false})
+	expectStatus(t, "update privacy", status, http.StatusOK)
+
+	user, err := env.store.GetUserByID(context.Background(), aliceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.PrivacySettings.ProfilePublic || user.PrivacySettings.QuotesPublic {
+		t.Fatalf("privacy after update = %+v", user.PrivacySettings)
+	}
+}
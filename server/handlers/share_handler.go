@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// createShareLink stores a link for the quote or collection and writes the response
+func (a *App) createShareLink(c *gin.Context, link *models.ShareLink) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	link.UserID = userID.(int)
+
+	var req models.CreateShareLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if err := database.CreateShareLink(c.Request.Context(), a.DB, link); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create share link"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Share link created successfully",
+		"share":   link,
+		"url":     link.Path(),
+	})
+}
+
+// CreateQuoteShareHandler creates a share link for a single quote
+func (a *App) CreateQuoteShareHandler(c *gin.Context) {
+	// Get quote_id from context (set by QuoteOwnershipRequired middleware)
+	quoteID, exists := c.Get("quote_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	id := quoteID.(int)
+	a.createShareLink(c, &models.ShareLink{QuoteID: &id})
+}
+
+// CreateCollectionShareHandler creates a share link for a collection
+func (a *App) CreateCollectionShareHandler(c *gin.Context) {
+	// Get collection_id from context (set by CollectionOwnershipRequired middleware)
+	collectionID, exists := c.Get("collection_id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	id := collectionID.(int)
+	a.createShareLink(c, &models.ShareLink{CollectionID: &id})
+}
+
+// GetShareLinksHandler returns the current user's share links
+func (a *App) GetShareLinksHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	links, err := database.GetShareLinksByUserID(c.Request.Context(), a.DB, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": links})
+}
+
+// RevokeShareLinkHandler revokes one of the current user's share links
+func (a *App) RevokeShareLinkHandler(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	shareID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share ID"})
+		return
+	}
+
+	if err := database.RevokeShareLink(c.Request.Context(), a.DB, shareID, userID.(int)); err != nil {
+		if errors.Is(err, database.ErrShareLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
+
+// SharePageHandler renders a read-only quote or collection for a valid share token.
+// No login is required; the token itself grants access.
+func (a *App) SharePageHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	link, err := database.GetActiveShareLink(ctx, a.DB, c.Param("token"))
+	if err != nil {
+		c.String(http.StatusNotFound, "This link is invalid or has expired")
+		return
+	}
+
+	owner, err := a.Users.GetUserByID(ctx, link.UserID)
+	if err != nil {
+		c.String(http.StatusNotFound, "This link is invalid or has expired")
+		return
+	}
+
+	data := gin.H{
+		"owner": owner,
+		"link":  link,
+	}
+
+	if link.QuoteID != nil {
+		quote, err := a.Quotes.GetQuoteByID(ctx, *link.QuoteID)
+		if err != nil {
+			c.String(http.StatusNotFound, "This link is invalid or has expired")
+			return
+		}
+		data["title"] = quote.Author
+		data["description"] = quote.Quote
+		data["items"] = models.Quotes{*quote}
+	} else {
+		col, err := database.GetCollectionByID(ctx, a.DB, *link.CollectionID)
+		if err != nil {
+			c.String(http.StatusNotFound, "This link is invalid or has expired")
+			return
+		}
+		quotes, err := database.FetchCollectionQuotes(ctx, a.DB, col.CollectionID)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to load collection")
+			return
+		}
+		data["title"] = col.Name
+		data["description"] = col.Description
+		data["collection"] = col
+		data["items"] = quotes
+	}
+
+	// OpenGraph preview uses the first quote's image card
+	if items := data["items"].(models.Quotes); len(items) > 0 {
+		data["og_image"] = a.absoluteURL(c, fmt.Sprintf("/quote/%d/card.png?aspect=og&share=%s", items[0].QuoteID, link.Token))
+	}
+	data["og_url"] = a.absoluteURL(c, link.Path())
+
+	// Share pages should not be indexed or leak the token via Referer
+	c.Header("X-Robots-Tag", "noindex, nofollow")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.HTML(http.StatusOK, "share.html", data)
+}
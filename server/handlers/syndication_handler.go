@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// UserFeedHandler serves a user's newest public quotes as a feed in format.
+// Users who have made their profile private have no feed.
+func (a *App) UserFeedHandler(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		username := c.Param("username")
+
+		user, err := a.publicUser(ctx, username)
+		if err != nil {
+			if errors.Is(err, database.ErrUserNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			}
+			return
+		}
+
+		quotes, err := a.Quotes.GetPublicQuotesByUserID(ctx, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+			return
+		}
+		if len(quotes) > config.FeedEntryLimit {
+			quotes = quotes[:config.FeedEntryLimit]
+		}
+
+		feed := services.NewQuoteFeed(user.Username+"'s quotes on zetl", "Public quotes saved by "+user.Username,
+			user.Username, a.absoluteURL(c, ""), c.Request.URL.EscapedPath(), quotes, user.CreatedAt)
+		serveFeed(c, feed, format)
+	}
+}
+
+// TagFeedHandler serves the newest public quotes with a tag as a feed in format
+func (a *App) TagFeedHandler(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tag := c.Param("tag")
+
+		quotes, err := a.Quotes.GetPublicQuotesByTag(c.Request.Context(), tag, config.FeedEntryLimit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quotes"})
+			return
+		}
+		if len(quotes) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No public quotes have this tag"})
+			return
+		}
+
+		feed := services.NewQuoteFeed("Quotes tagged "+tag+" on zetl", "Public quotes tagged "+tag,
+			"zetl", a.absoluteURL(c, ""), c.Request.URL.EscapedPath(), quotes, time.Time{})
+		serveFeed(c, feed, format)
+	}
+}
+
+// serveFeed writes feed in format with caching headers, answering
+// conditional requests that already have this version with 304. There is no
+// Last-Modified: the feed's Updated time goes backwards when its newest quote
+// is deleted or made private, so If-Modified-Since clients would keep a
+// stale feed. The ETag covers every entry and changes either way.
+func serveFeed(c *gin.Context, feed *services.QuoteFeed, format string) {
+	etag := feed.ETag(format)
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", config.FeedMaxAge))
+	c.Header("ETag", etag)
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := feed.Render(format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render feed"})
+		return
+	}
+
+	c.Data(http.StatusOK, services.FeedContentTypes[format], body)
+}
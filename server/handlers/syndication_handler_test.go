@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUserFeed(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("Very little is needed to make a happy life")
+	status, _ := alice.do(http.MethodPost, "/api/quote", gin.H{
+		"quote": "A private thought", "author": "Alice", "book": "Journal", "visibility": "private",
+	})
+	expectStatus(t, "create private quote", status, http.StatusCreated)
+
+	reader := env.client()
+	resp, body := reader.fetch("/u/alice/feed.xml", nil)
+	expectStatus(t, "atom feed", resp.StatusCode, http.StatusOK)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/atom+xml") ||
+		!strings.Contains(body, "Very little is needed to make a happy life") || strings.Contains(body, "A private thought") {
+		t.Fatalf("atom feed = %s\n%s", resp.Header.Get("Content-Type"), body)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Last-Modified") != "" {
+		t.Fatalf("feed headers = %v, want an ETag and no Last-Modified", resp.Header)
+	}
+
+	resp, _ = reader.fetch("/u/alice/feed.xml", http.Header{"If-None-Match": {etag}})
+	expectStatus(t, "matching etag", resp.StatusCode, http.StatusNotModified)
+
+	// Each format has its own ETag, and editing a quote changes them
+	resp, _ = reader.fetch("/u/alice/rss.xml", http.Header{"If-None-Match": {etag}})
+	expectStatus(t, "rss with the atom etag", resp.StatusCode, http.StatusOK)
+	status, _ = alice.do(http.MethodPut, "/api/quote/"+strconv.Itoa(quoteID), gin.H{
+		"quote": "Very little is needed to make a happy life.", "author": "Marcus Aurelius", "book": "Meditations", "tags": []string{},
+	})
+	expectStatus(t, "edit quote", status, http.StatusOK)
+	resp, body = reader.fetch("/u/alice/feed.xml", http.Header{"If-None-Match": {etag}})
+	expectStatus(t, "feed after edit", resp.StatusCode, http.StatusOK)
+	if !strings.Contains(body, "happy life.") {
+		t.Fatalf("feed after edit = %s", body)
+	}
+
+	resp, _ = reader.fetch("/u/nobody/feed.xml", nil)
+	expectStatus(t, "unknown user", resp.StatusCode, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodPut, "/api/user/privacy", gin.H{"profile_public": false, "quotes_public": true})
+	expectStatus(t, "make profile private", status, http.StatusOK)
+	resp, _ = reader.fetch("/u/alice/feed.json", nil)
+	expectStatus(t, "private profile", resp.StatusCode, http.StatusNotFound)
+}
+
+func TestTagFeed(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("It is not death that a man should fear")
+
+	reader := env.client()
+	resp, body := reader.fetch("/tag/stoicism/feed.json", nil)
+	expectStatus(t, "json feed", resp.StatusCode, http.StatusOK)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/feed+json") {
+		t.Fatalf("json feed content type = %s", resp.Header.Get("Content-Type"))
+	}
+
+	var feed struct {
+		Version string `json:"version"`
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			ContentText  string   `json:"content_text"`
+			DateModified string   `json:"date_modified"`
+			Tags         []string `json:"tags"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Version != "https://jsonfeed.org/version/1.1" || !strings.HasSuffix(feed.FeedURL, "/tag/stoicism/feed.json") ||
+		len(feed.Items) != 1 || feed.Items[0].DateModified == "" || !strings.Contains(feed.Items[0].ContentText, "Marcus Aurelius") {
+		t.Fatalf("json feed = %+v", feed)
+	}
+
+	resp, body = reader.fetch("/tag/stoicism/rss.xml", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "<rss version=\"2.0\"") {
+		t.Fatalf("rss feed = %d %s", resp.StatusCode, body)
+	}
+
+	resp, _ = reader.fetch("/tag/unused/feed.xml", nil)
+	expectStatus(t, "tag without public quotes", resp.StatusCode, http.StatusNotFound)
+}
+
+func TestUserFeedConditionalRequests(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("You have power over your mind")
+	newest := alice.createQuote("The best revenge is not to be like your enemy")
+
+	reader := env.client()
+	resp, _ := reader.fetch("/u/alice/feed.xml", nil)
+	expectStatus(t, "feed", resp.StatusCode, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+	if resp.Header.Get("Last-Modified") != "" {
+		t.Fatalf("feed sent Last-Modified %q", resp.Header.Get("Last-Modified"))
+	}
+
+	resp, _ = reader.fetch("/u/alice/feed.xml", http.Header{"If-None-Match": {etag}})
+	expectStatus(t, "unchanged feed", resp.StatusCode, http.StatusNotModified)
+
+	// Deleting the newest quote changes the feed even though nothing in it
+	// is newer than before
+	status, _ := alice.do(http.MethodDelete, "/api/quote/"+strconv.Itoa(newest), nil)
+	expectStatus(t, "delete quote", status, http.StatusOK)
+
+	since := time.Now().UTC().Add(time.Hour).Format(http.TimeFormat)
+	resp, body := reader.fetch("/u/alice/feed.xml", http.Header{"If-None-Match": {etag}, "If-Modified-Since": {since}})
+	expectStatus(t, "feed after delete", resp.StatusCode, http.StatusOK)
+	if strings.Contains(body, "The best revenge") {
+		t.Fatalf("feed still lists the deleted quote:\n%s", body)
+	}
+	resp, _ = reader.fetch("/u/alice/feed.xml", http.Header{"If-Modified-Since": {since}})
+	expectStatus(t, "If-Modified-Since alone", resp.StatusCode, http.StatusOK)
+}
+
+func TestFeedLinksUseAppURL(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	alice.createQuote("Very little is needed to make a happy life")
+
+	forged := http.Header{"Host": {"evil.example"}, "X-Forwarded-Proto": {"https"}}
+
+	// Without an AppURL, links fall back to the request's host
+	_, body := env.client().fetch("/u/alice/rss.xml", forged)
+	if !strings.Contains(body, `href="https://evil.example/u/alice/rss.xml"`) {
+		t.Fatalf("feed without AppURL =\n%s", body)
+	}
+
+	env.app.AppURL = "https://zetl.example/"
+	_, body = env.client().fetch("/u/alice/rss.xml", forged)
+	if strings.Contains(body, "evil.example") || !strings.Contains(body, `href="https://zetl.example/u/alice/rss.xml"`) {
+		t.Fatalf("feed with AppURL =\n%s", body)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/metrics"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// trashedQuote is a quote in the trash along with when it will be purged
+type trashedQuote struct {
+	models.Quote
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// trashedQuotes adds purge dates to the current user's trashed quotes
+func (a *App) trashedQuotes(c *gin.Context) ([]trashedQuote, error) {
+	quotes, err := a.Quotes.GetTrashedQuotesByUserID(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]trashedQuote, 0, len(quotes))
+	for _, q := range quotes {
+		trashed = append(trashed, trashedQuote{Quote: q, PurgeAt: q.DeletedAt.Add(a.TrashRetention)})
+	}
+	return trashed, nil
+}
+
+// GetTrashHandler lists the current user's trashed quotes, most recently
+// deleted first
+func (a *App) GetTrashHandler(c *gin.Context) {
+	quotes, err := a.trashedQuotes(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotes":         quotes,
+		"retention_days": int(a.TrashRetention.Hours() / 24),
+	})
+}
+
+// RestoreTrashedQuoteHandler moves a quote out of the trash
+func (a *App) RestoreTrashedQuoteHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	if err := a.Quotes.RestoreQuote(c.Request.Context(), quoteID, c.GetInt("user_id")); err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found in trash"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore quote"})
+		}
+		return
+	}
+
+	a.emitQuoteEventByID(c, models.WebhookQuoteRestored, quoteID)
+	c.JSON(http.StatusOK, gin.H{"message": "Quote restored successfully"})
+}
+
+// PurgeTrashedQuoteHandler permanently deletes a quote from the trash
+func (a *App) PurgeTrashedQuoteHandler(c *gin.Context) {
+	quoteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quote ID"})
+		return
+	}
+
+	// The quote is read before purging so its webhook payload is complete. A
+	// failed read only loses the event, as with emitQuoteEvent.
+	trashed, err := a.trashedQuotes(c)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to load trashed quote for webhook event", "quote_id", quoteID, "error", err)
+	}
+
+	if err := a.Quotes.PurgeQuote(c.Request.Context(), quoteID, c.GetInt("user_id")); err != nil {
+		if errors.Is(err, database.ErrQuoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found in trash"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete quote"})
+		}
+		return
+	}
+
+	metrics.QuotesPurgedTotal.Inc()
+	for i := range trashed {
+		if trashed[i].QuoteID == quoteID {
+			a.emitQuoteEvent(c, models.WebhookQuoteDeleted, &trashed[i].Quote)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Quote permanently deleted"})
+}
+
+// TrashPageHandler renders the trash page
+func (a *App) TrashPageHandler(c *gin.Context) {
+	user, err := a.Users.GetUserByID(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/login")
+		return
+	}
+
+	quotes, err := a.trashedQuotes(c)
+	if err != nil {
+		quotes = nil
+	}
+
+	c.HTML(http.StatusOK, "trash.html", gin.H{
+		"title":          "Trash",
+		"user":           user.ToResponse(),
+		"quotes":         quotes,
+		"retention_days": int(a.TrashRetention.Hours() / 24),
+	})
+}
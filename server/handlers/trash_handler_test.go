@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDeleteMovesQuoteToTrash(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	aliceID := alice.signup("alice")
+	quoteID := alice.createQuote("not gone yet")
+
+	status, _ := alice.do(http.MethodDelete, fmt.Sprintf("/api/quote/%d", quoteID), nil)
+	expectStatus(t, "delete", status, http.StatusOK)
+
+	_, body := env.client().do(http.MethodGet, fmt.Sprintf("/user/%d/quotes", aliceID), nil)
+	if quotes := body["quotes"].([]interface{}); len(quotes) != 0 {
+		t.Fatalf("trashed quote still listed: %v", quotes)
+	}
+
+	status, body = alice.do(http.MethodGet, "/api/trash", nil)
+	expectStatus(t, "list trash", status, http.StatusOK)
+	trashed := body["quotes"].([]interface{})
+	if len(trashed) != 1 {
+		t.Fatalf("got %d trashed quotes, want 1", len(trashed))
+	}
+	if q := trashed[0].(map[string]interface{}); q["deleted_at"] == nil || q["purge_at"] == nil {
+		t.Fatalf("trashed quote is missing dates: %v", q)
+	}
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/trash/%d/restore", quoteID), nil)
+	expectStatus(t, "restore", status, http.StatusOK)
+
+	if _, err := env.store.GetQuoteByID(context.Background(), quoteID); err != nil {
+		t.Fatalf("restored quote: %v", err)
+	}
+
+	status, _ = alice.do(http.MethodPost, fmt.Sprintf("/api/trash/%d/restore", quoteID), nil)
+	expectStatus(t, "restore quote not in trash", status, http.StatusNotFound)
+}
+
+func TestTrashIsScopedToOwner(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("alice's")
+	alice.do(http.MethodDelete, fmt.Sprintf("/api/quote/%d", quoteID), nil)
+
+	bob := env.client()
+	bob.signup("bob")
+
+	_, body := bob.do(http.MethodGet, "/api/trash", nil)
+	if trashed := body["quotes"].([]interface{}); len(trashed) != 0 {
+		t.Fatalf("bob sees alice's trash: %v", trashed)
+	}
+
+	status, _ := bob.do(http.MethodPost, fmt.Sprintf("/api/trash/%d/restore", quoteID), nil)
+	expectStatus(t, "restore by non-owner", status, http.StatusNotFound)
+
+	status, _ = bob.do(http.MethodDelete, fmt.Sprintf("/api/trash/%d", quoteID), nil)
+	expectStatus(t, "purge by non-owner", status, http.StatusNotFound)
+
+	status, _ = alice.do(http.MethodDelete, fmt.Sprintf("/api/trash/%d", quoteID), nil)
+	expectStatus(t, "purge", status, http.StatusOK)
+
+	_, body = alice.do(http.MethodGet, "/api/trash", nil)
+	if trashed := body["quotes"].([]interface{}); len(trashed) != 0 {
+		t.Fatalf("purged quote still in trash: %v", trashed)
+	}
+}
+
+func TestPurgeDeletedQuotesRespectsCutoff(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	quoteID := alice.createQuote("expiring")
+	alice.do(http.MethodDelete, fmt.Sprintf("/api/quote/%d", quoteID), nil)
+
+	ctx := context.Background()
+	purged, err := env.store.PurgeDeletedQuotes(ctx, time.Now().Add(-time.Hour))
+	if err != nil || len(purged) != 0 {
+		t.Fatalf("purge before retention = %d, %v; want 0", len(purged), err)
+	}
+
+	purged, err = env.store.PurgeDeletedQuotes(ctx, time.Now().Add(time.Second))
+	if err != nil || len(purged) != 1 {
+		t.Fatalf("purge after retention = %d, %v; want 1", len(purged), err)
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// GetWebhooksHandler lists the current user's webhooks and the events they
+// can subscribe to
+func (a *App) GetWebhooksHandler(c *gin.Context) {
+	hooks, err := a.Webhooks.GetWebhooksByUserID(c.Request.Context(), c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": hooks, "events": models.WebhookEvents})
+}
+
+// CreateWebhookHandler adds a webhook for the current user. Its signing
+// secret is returned once, in this response.
+func (a *App) CreateWebhookHandler(c *gin.Context) {
+	req, ok := a.bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := c.GetInt("user_id")
+
+	hooks, err := a.Webhooks.GetWebhooksByUserID(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	if len(hooks) >= config.MaxWebhooksPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can have at most " + strconv.Itoa(config.MaxWebhooksPerUser) + " webhooks"})
+		return
+	}
+
+	secret, err := database.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	hook := &models.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+		Active: req.Active == nil || *req.Active,
+	}
+	if err := a.Webhooks.CreateWebhook(ctx, hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Webhook created", "webhook": hook, "secret": hook.Secret})
+}
+
+// UpdateWebhookHandler changes a webhook's URL, events or active flag
+func (a *App) UpdateWebhookHandler(c *gin.Context) {
+	req, ok := a.bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	hook := &models.Webhook{
+		WebhookID: c.GetInt("webhook_id"),
+		UserID:    c.GetInt("user_id"),
+		URL:       req.URL,
+		Events:    req.Events,
+		Active:    req.Active == nil || *req.Active,
+	}
+	if err := a.Webhooks.UpdateWebhook(c.Request.Context(), hook); err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook updated"})
+}
+
+// DeleteWebhookHandler removes a webhook and its delivery log
+func (a *App) DeleteWebhookHandler(c *gin.Context) {
+	if err := a.Webhooks.DeleteWebhook(c.Request.Context(), c.GetInt("user_id"), c.GetInt("webhook_id")); err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// GetWebhookDeliveriesHandler returns a webhook's most recent deliveries
+func (a *App) GetWebhookDeliveriesHandler(c *gin.Context) {
+	deliveries, err := a.Webhooks.GetWebhookDeliveries(c.Request.Context(), c.GetInt("webhook_id"), config.WebhookDeliveryLogMax)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// RedeliverWebhookHandler queues an earlier delivery's payload to be sent again
+func (a *App) RedeliverWebhookHandler(c *gin.Context) {
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	delivery, err := a.Webhooks.RedeliverWebhook(c.Request.Context(), c.GetInt("webhook_id"), deliveryID, time.Now())
+	if err != nil {
+		if errors.Is(err, database.ErrDeliveryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeliver"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Delivery queued", "delivery": delivery})
+}
+
+// bindWebhookRequest reads a webhook request, writing a 400 response and
+// returning false when it is invalid. Only http and https URLs whose host
+// resolves to public addresses are accepted.
+func (a *App) bindWebhookRequest(c *gin.Context) (*models.WebhookRequest, bool) {
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if err := a.WebhookGuard.CheckURL(c.Request.Context(), req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// emitQuoteEvent queues event on quote for its owner's webhooks. A failure
+// is logged rather than failing the request that changed the quote.
+func (a *App) emitQuoteEvent(c *gin.Context, event string, quote *models.Quote) {
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	payload, err := services.NewWebhookPayload(event, quote, now)
+	if err == nil {
+		_, err = a.Webhooks.EnqueueWebhookEvent(ctx, quote.UserID, event, payload, now)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to queue webhook event", "event", event, "quote_id", quote.QuoteID, "error", err)
+	}
+}
+
+// emitQuoteEventByID loads a quote and queues event on it for its owner's webhooks
+func (a *App) emitQuoteEventByID(c *gin.Context, event string, quoteID int) {
+	quote, err := a.Quotes.GetQuoteByID(c.Request.Context(), quoteID)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Error("failed to load quote for webhook event", "event", event, "quote_id", quoteID, "error", err)
+		return
+	}
+	a.emitQuoteEvent(c, event, quote)
+}
+
+// emitQuoteEvents queues event on each of the current user's quotes in ids.
+// Deleted quotes are read from the trash so their payload is complete.
+func (a *App) emitQuoteEvents(c *gin.Context, event string, ids []int) {
+	if event != models.WebhookQuoteDeleted {
+		for _, id := range ids {
+			a.emitQuoteEventByID(c, event, id)
+		}
+		return
+	}
+
+	ctx := c.Request.Context()
+	trash, err := a.Quotes.GetTrashedQuotesByUserID(ctx, c.GetInt("user_id"))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to load trashed quotes for webhook event", "event", event, "error", err)
+		return
+	}
+	for i := range trash {
+		if slices.Contains(ids, trash[i].QuoteID) {
+			a.emitQuoteEvent(c, event, &trash[i])
+		}
+	}
+}
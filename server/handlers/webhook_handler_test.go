@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/models"
+	"github.com/zach-monroe/zetl/server/services"
+)
+
+// webhookReceiver records the webhook requests it is sent and answers
+// with status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedWebhook
+	server   *httptest.Server
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, receivedWebhook{header: req.Header, body: body})
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *webhookReceiver) respondWith(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook{}, r.requests...)
+}
+
+// deliveries lists a webhook's delivery log, newest first
+func (c *testClient) deliveries(webhookID int) []models.WebhookDelivery {
+	t := c.env.t
+	t.Helper()
+
+	resp, body := c.fetch("/api/webhooks/"+strconv.Itoa(webhookID)+"/deliveries", nil)
+	expectStatus(t, "list deliveries", resp.StatusCode, http.StatusOK)
+	var data struct {
+		Deliveries []models.WebhookDelivery `json:"deliveries"`
+	}
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data.Deliveries
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := newWebhookReceiver(t)
+	alice := env.client()
+	alice.signup("alice")
+
+	status, body := alice.do(http.MethodPost, "/api/webhooks", gin.H{
+		"url": receiver.server.URL, "events": []string{"quote.created", "quote.updated", "quote.deleted"},
+	})
+	expectStatus(t, "create webhook", status, http.StatusCreated)
+	secret := body["secret"].(string)
+	webhookID := int(body["webhook"].(map[string]interface{})["webhook_id"].(float64))
+
+	quoteID := alice.createQuote("We suffer more often in imagination than in reality")
+	status, _ = alice.do(http.MethodPut, "/api/quote/"+strconv.Itoa(quoteID), gin.H{
+		"quote": "We suffer more often in imagination than in reality.", "author": "Seneca", "book": "Letters",
+	})
+	expectStatus(t, "update quote", status, http.StatusOK)
+	status, _ = alice.do(http.MethodDelete, "/api/quote/"+strconv.Itoa(quoteID), nil)
+	expectStatus(t, "delete quote", status, http.StatusOK)
+
+	if got := alice.deliveries(webhookID); len(got) != 3 || got[0].Status != models.DeliveryPending {
+		t.Fatalf("queued deliveries = %+v, want 3 pending", got)
+	}
+
+	// The first delivery fails and is retried later; the rest go through
+	receiver.respondWith(http.StatusInternalServerError)
+	sender := services.NewWebhookSender(env.store, env.app.WebhookGuard)
+	ctx := context.Background()
+	now := time.Now()
+	if err := sender.DeliverDue(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+	receiver.respondWith(http.StatusOK)
+	if err := sender.DeliverDue(ctx, now.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := receiver.received()
+	if len(requests) != 6 {
+		t.Fatalf("receiver got %d requests, want 3 failed and 3 retried", len(requests))
+	}
+	events := map[string]bool{}
+	for _, req := range requests[3:] {
+		if got, want := req.header.Get(services.WebhookSignatureHeader), services.SignWebhookPayload(secret, req.body); got != want {
+			t.Fatalf("signature = %q, want %q", got, want)
+		}
+		var payload services.WebhookPayload
+		if err := json.Unmarshal(req.body, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Event != req.header.Get(services.WebhookEventHeader) || payload.Quote.QuoteID != quoteID {
+			t.Fatalf("payload = %+v for event %s", payload, req.header.Get(services.WebhookEventHeader))
+		}
+		events[payload.Event] = true
+	}
+	if len(events) != 3 {
+		t.Fatalf("delivered events = %v", events)
+	}
+
+	deliveries := alice.deliveries(webhookID)
+	for _, d := range deliveries {
+		if d.Status != models.DeliverySucceeded || d.Attempts != 2 || d.ResponseStatus == nil || *d.ResponseStatus != http.StatusOK {
+			t.Fatalf("delivery = %+v, want succeeded on the second attempt", d)
+		}
+	}
+
+	// Redelivering queues a copy of the payload
+	path := "/api/webhooks/" + strconv.Itoa(webhookID) + "/deliveries/" + strconv.Itoa(deliveries[0].DeliveryID) + "/redeliver"
+	status, _ = alice.do(http.MethodPost, path, nil)
+	expectStatus(t, "redeliver", status, http.StatusAccepted)
+	if err := sender.DeliverDue(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	requests = receiver.received()
+	if len(requests) != 7 || string(requests[6].body) != string(deliveries[0].Payload) {
+		t.Fatalf("redelivered %d requests, last = %s", len(requests), requests[len(requests)-1].body)
+	}
+
+	// Other users can't see or redeliver alice's webhooks
+	bob := env.client()
+	bob.signup("bob")
+	status, _ = bob.do(http.MethodPost, path, nil)
+	expectStatus(t, "redeliver someone else's webhook", status, http.StatusNotFound)
+}
+
+func TestWebhookRetriesGiveUp(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := newWebhookReceiver(t)
+	receiver.respondWith(http.StatusServiceUnavailable)
+	alice := env.client()
+	alice.signup("alice")
+
+	status, body := alice.do(http.MethodPost, "/api/webhooks", gin.H{"url": receiver.server.URL, "events": []string{"quote.created"}})
+	expectStatus(t, "create webhook", status, http.StatusCreated)
+	webhookID := int(body["webhook"].(map[string]interface{})["webhook_id"].(float64))
+	alice.createQuote("No man is free who is not master of himself")
+
+	sender := services.NewWebhookSender(env.store, env.app.WebhookGuard)
+	now := time.Now()
+	for i := 0; i < models.MaxDeliveryAttempts+2; i++ {
+		// Backoff doubles from a minute, so a day apart is always due
+		if err := sender.DeliverDue(context.Background(), now.AddDate(0, 0, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := len(receiver.received()); got != models.MaxDeliveryAttempts {
+		t.Fatalf("receiver got %d attempts, want %d", got, models.MaxDeliveryAttempts)
+	}
+	d := alice.deliveries(webhookID)[0]
+	if d.Status != models.DeliveryFailed || d.NextAttemptAt != nil || d.Error == "" {
+		t.Fatalf("delivery = %+v, want failed", d)
+	}
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+
+	tests := []struct {
+		name string
+		body gin.H
+	}{
+		{"non-http URL", gin.H{"url": "ftp://example.com/hook", "events": []string{"quote.created"}}},
+		{"unknown event", gin.H{"url": "https://example.com/hook", "events": []string{"quote.liked"}}},
+		{"no events", gin.H{"url": "https://example.com/hook", "events": []string{}}},
+	}
+	for _, tt := range tests {
+		status, _ := alice.do(http.MethodPost, "/api/webhooks", tt.body)
+		expectStatus(t, tt.name, status, http.StatusBadRequest)
+	}
+
+	status, body := alice.do(http.MethodPost, "/api/webhooks", gin.H{"url": "https://example.com/hook", "events": []string{"quote.deleted"}})
+	expectStatus(t, "create webhook", status, http.StatusCreated)
+	webhookID := int(body["webhook"].(map[string]interface{})["webhook_id"].(float64))
+
+	// Only subscribed events of active webhooks are queued
+	alice.createQuote("Luck is what happens when preparation meets opportunity")
+	if got := alice.deliveries(webhookID); len(got) != 0 {
+		t.Fatalf("deliveries for an unsubscribed event = %+v", got)
+	}
+
+	status, _ = alice.do(http.MethodPut, "/api/webhooks/"+strconv.Itoa(webhookID), gin.H{
+		"url": "https://example.com/hook", "events": []string{"quote.created"}, "active": false,
+	})
+	expectStatus(t, "pause webhook", status, http.StatusOK)
+	alice.createQuote("Difficulties strengthen the mind")
+	if got := alice.deliveries(webhookID); len(got) != 0 {
+		t.Fatalf("deliveries for a paused webhook = %+v", got)
+	}
+
+	status, body = alice.do(http.MethodGet, "/api/webhooks", nil)
+	expectStatus(t, "list webhooks", status, http.StatusOK)
+	hooks := body["webhooks"].([]interface{})
+	if len(hooks) != 1 || hooks[0].(map[string]interface{})["secret"] != nil {
+		t.Fatalf("webhooks = %v, want one without its secret", hooks)
+	}
+
+	status, _ = alice.do(http.MethodDelete, "/api/webhooks/"+strconv.Itoa(webhookID), nil)
+	expectStatus(t, "delete webhook", status, http.StatusOK)
+	status, _ = alice.do(http.MethodDelete, "/api/webhooks/"+strconv.Itoa(webhookID), nil)
+	expectStatus(t, "delete webhook again", status, http.StatusNotFound)
+}
+
+func TestWebhookRejectsInternalAddresses(t *testing.T) {
+	env := newTestEnv(t)
+	env.app.WebhookGuard = services.WebhookGuard{}
+	alice := env.client()
+	alice.signup("alice")
+
+	for _, target := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:10.0.0.1]/hook",
+	} {
+		status, body := alice.do(http.MethodPost, "/api/webhooks", gin.H{"url": target, "events": []string{"quote.created"}})
+		if status != http.StatusBadRequest {
+			t.Errorf("%s: status %d, body %v, want 400", target, status, body)
+		}
+	}
+}
+
+func TestWebhookSenderRefusesInternalAddresses(t *testing.T) {
+	env := newTestEnv(t)
+	receiver := newWebhookReceiver(t)
+	alice := env.client()
+	alice.signup("alice")
+
+	// Registered while allowed, as a hostname that later resolves to an
+	// internal address would be
+	status, body := alice.do(http.MethodPost, "/api/webhooks", gin.H{"url": receiver.server.URL, "events": []string{"quote.created"}})
+	expectStatus(t, "create webhook", status, http.StatusCreated)
+	webhookID := int(body["webhook"].(map[string]interface{})["webhook_id"].(float64))
+	alice.createQuote("We suffer more often in imagination than in reality")
+
+	sender := services.NewWebhookSender(env.store, services.WebhookGuard{})
+	if err := sender.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := receiver.received(); len(got) != 0 {
+		t.Fatalf("receiver got %d requests, want none", len(got))
+	}
+	deliveries := alice.deliveries(webhookID)
+	if len(deliveries) != 1 || deliveries[0].ResponseStatus != nil || deliveries[0].Error == "" {
+		t.Fatalf("delivery = %+v, want a failed attempt without a response", deliveries)
+	}
+}
+
+func TestWebhookEventsFromEveryQuoteChange(t *testing.T) {
+	env := newTestEnv(t)
+	alice := env.client()
+	alice.signup("alice")
+	status, body := alice.do(http.MethodPost, "/api/webhooks", gin.H{"url": "https://example.com/hook", "events": models.WebhookEvents})
+	expectStatus(t, "create webhook", status, http.StatusCreated)
+	webhookID := int(body["webhook"].(map[string]interface{})["webhook_id"].(float64))
+
+	bob := env.client()
+	bob.signup("bob")
+	bobQuote := bob.createQuote("Hope is the thing with feathers")
+
+	quoteID := alice.createQuote("The obstacle is the way")
+	path := "/api/quote/" + strconv.Itoa(quoteID)
+	bulk := func(action string, extra gin.H) {
+		req := gin.H{"action": action, "quote_ids": []int{quoteID}}
+		for k, v := range extra {
+			req[k] = v
+		}
+		status, body := alice.do(http.MethodPost, "/api/quotes/bulk", req)
+		if status != http.StatusOK || body["updated"] != float64(1) {
+			t.Fatalf("bulk %s: status %d, body %v", action, status, body)
+		}
+	}
+
+	steps := []struct {
+		name  string
+		run   func() int
+		event string
+	}{
+		{"update", func() int {
+			status, _ := alice.do(http.MethodPut, path, gin.H{"quote": "The obstacle is the way.", "author": "Marcus Aurelius", "book": "Meditations"})
+			expectStatus(t, "update", status, http.StatusOK)
+			return quoteID
+		}, models.WebhookQuoteUpdated},
+		{"restore revision", func() int {
+			status, _ := alice.do(http.MethodPost, path+"/revisions/1/restore", nil)
+			expectStatus(t, "restore revision", status, http.StatusOK)
+			return quoteID
+		}, models.WebhookQuoteUpdated},
+		{"bulk retag", func() int { bulk("add_tags", gin.H{"tags": []string{"stoicism"}}); return quoteID }, models.WebhookQuoteUpdated},
+		{"bulk visibility", func() int { bulk("set_visibility", gin.H{"visibility": "private"}); return quoteID }, models.WebhookQuoteUpdated},
+		{"bulk delete", func() int { bulk("delete", nil); return quoteID }, models.WebhookQuoteDeleted},
+		{"bulk restore", func() int { bulk("restore", nil); return quoteID }, models.WebhookQuoteRestored},
+		{"delete", func() int {
+			status, _ := alice.do(http.MethodDelete, path, nil)
+			expectStatus(t, "delete", status, http.StatusOK)
+			return quoteID
+		}, models.WebhookQuoteDeleted},
+		{"restore from trash", func() int {
+			status, _ := alice.do(http.MethodPost, "/api/trash/"+strconv.Itoa(quoteID)+"/restore", nil)
+			expectStatus(t, "restore from trash", status, http.StatusOK)
+			return quoteID
+		}, models.WebhookQuoteRestored},
+		{"trash again", func() int {
+			status, _ := alice.do(http.MethodDelete, path, nil)
+			expectStatus(t, "delete", status, http.StatusOK)
+			return quoteID
+		}, models.WebhookQuoteDeleted},
+		{"purge", func() int {
+			status, _ := alice.do(http.MethodDelete, "/api/trash/"+strconv.Itoa(quoteID), nil)
+			expectStatus(t, "purge", status, http.StatusOK)
+			return quoteID
+		}, models.WebhookQuoteDeleted},
+		{"save someone else's quote", func() int {
+			status, body := alice.do(http.MethodPost, "/api/quote/"+strconv.Itoa(bobQuote)+"/save", nil)
+			expectStatus(t, "save", status, http.StatusCreated)
+			return int(body["quote_id"].(float64))
+		}, models.WebhookQuoteCreated},
+	}
+
+	for _, step := range steps {
+		before := len(alice.deliveries(webhookID))
+		wantID := step.run()
+
+		deliveries := alice.deliveries(webhookID)
+		if len(deliveries) != before+1 {
+			t.Fatalf("%s queued %d deliveries, want 1", step.name, len(deliveries)-before)
+		}
+		var payload services.WebhookPayload
+		if err := json.Unmarshal(deliveries[0].Payload, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if deliveries[0].Event != step.event || payload.Quote == nil || payload.Quote.QuoteID != wantID {
+			t.Fatalf("%s queued %s for %+v, want %s for quote %d", step.name, deliveries[0].Event, payload.Quote, step.event, wantID)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// RequestIDHeader is the header used to pass request IDs between clients and the server
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey struct{}
+
+// Setup installs a JSON slog logger as the process default
+func Setup(level slog.Level) *slog.Logger {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+	slog.SetDefault(logger)
+	return logger
+}
+
+// WithContext returns a copy of ctx carrying logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger.
+// Loggers attached by the request-ID middleware include a request_id attribute.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// sensitiveKeys are attribute keys whose values are never written to logs
+var sensitiveKeys = []string{"password", "token", "secret", "api_key", "apikey", "authorization", "cookie"}
+
+// redact masks sensitive attributes: secrets are replaced entirely and
+// email addresses keep only their first character and domain.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(a.Key, "[REDACTED]")
+		}
+	}
+
+	if strings.Contains(key, "email") && a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, MaskEmail(a.Value.String()))
+	}
+
+	return a
+}
+
+// MaskEmail hides the local part of an email address, e.g. j***@example.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "[REDACTED]"
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/postgres"
@@ -15,29 +20,20 @@ import (
 	"github.com/zach-monroe/zetl/server/config"
 	"github.com/zach-monroe/zetl/server/database"
 	"github.com/zach-monroe/zetl/server/handlers"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/metrics"
 	"github.com/zach-monroe/zetl/server/middleware"
-	"github.com/zach-monroe/zetl/server/models"
 	"github.com/zach-monroe/zetl/server/services"
 )
 
-func UnmarshalQuotes(data []byte) (models.Quotes, error) {
-	var q models.Quotes
-	err := json.Unmarshal(data, &q)
-	return q, err
-}
-
-func setupRouter(dbConn *database.DBConnection, emailService *services.EmailService, geminiService *services.GeminiService) *gin.Engine {
-	r := gin.Default()
+func setupRouter(cfg *config.Config, app *handlers.App) (*gin.Engine, error) {
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.RequestLogger(), middleware.Recovery(), middleware.Metrics())
 
 	// Set up PostgreSQL session store
-	sessionSecret := os.Getenv("SESSION_SECRET")
-	if sessionSecret == "" {
-		panic("SESSION_SECRET environment variable is not set")
-	}
-
-	store, err := postgres.NewStore(dbConn.DB, []byte(sessionSecret))
+	store, err := postgres.NewStore(app.DB, []byte(cfg.Session.Secret))
 	if err != nil {
-		panic(fmt.Sprintf("Failed to create session store: %v", err))
+		return nil, fmt.Errorf("failed to create session store: %w", err)
 	}
 
 	// Configure session options
@@ -60,90 +56,182 @@ func setupRouter(dbConn *database.DBConnection, emailService *services.EmailServ
 	r.Static("/css", "../client/css")
 	r.Static("/js", "../client/js")
 
-	// Health check endpoint (used by k8s probes)
-	r.GET("/healthz", func(c *gin.Context) {
-		c.String(http.StatusOK, "ok")
-	})
+	app.Routes(r)
 
-	// Public page routes
-	r.GET("/", func(c *gin.Context) {
-		// Fetch quotes from database as JSON and unmarshal
-		quotesJSON := database.FetchQuotesAsJson(dbConn.DB)
-		quotes, err := UnmarshalQuotes([]byte(quotesJSON))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load quotes"})
-			return
-		}
-		user := handlers.GetUserFromSession(c, dbConn.DB)
-		c.HTML(http.StatusOK, "index.html", gin.H{"items": quotes, "user": user})
-	})
+	return r, nil
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
 
-	r.GET("/login", handlers.LoginPageHandler(dbConn.DB))
-	r.GET("/signup", handlers.SignupPageHandler(dbConn.DB))
-	r.GET("/forgot-password", handlers.ForgotPasswordPageHandler(dbConn.DB))
-	r.GET("/reset-password", handlers.ResetPasswordPageHandler(dbConn.DB))
-
-	// Public API routes
-	r.GET("/user/:id/quotes", handlers.GetUserQuotesHandler(dbConn.DB))
-
-	// Authentication routes
-	authGroup := r.Group("/auth")
-	{
-		authGroup.POST("/signup", handlers.SignupHandler(dbConn.DB))
-		authGroup.POST("/login", handlers.LoginHandler(dbConn.DB))
-		authGroup.POST("/logout", handlers.LogoutHandler())
-		authGroup.POST("/forgot-password", handlers.ForgotPasswordHandler(dbConn.DB, emailService))
-		authGroup.POST("/reset-password", handlers.ResetPasswordHandler(dbConn.DB))
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logging.Setup(cfg.SlogLevel())
+
+	dbConn, err := database.StartDatabase(context.Background(), cfg.Database)
+	if err != nil {
+		fatal("failed to start database", err)
 	}
 
-	// Protected page routes - require authentication
-	r.GET("/settings", middleware.AuthRequired(), handlers.SettingsPageHandler(dbConn.DB))
-	r.GET("/profile", middleware.AuthRequired(), handlers.ProfilePageHandler(dbConn.DB))
+	if err := database.RunMigrations(context.Background(), dbConn.DB); err != nil {
+		fatal("failed to run migrations", err)
+	}
 
-	// Protected API routes - require authentication
-	apiGroup := r.Group("/api")
-	apiGroup.Use(middleware.AuthRequired())
-	{
-		// Get current user
-		apiGroup.GET("/user", handlers.GetCurrentUserHandler(dbConn.DB))
+	metrics.RegisterDB(dbConn.DB)
 
-		// User settings
-		apiGroup.PUT("/user/profile", handlers.UpdateProfileHandler(dbConn.DB))
-		apiGroup.PUT("/user/password", handlers.UpdatePasswordHandler(dbConn.DB))
-		apiGroup.PUT("/user/privacy", handlers.UpdatePrivacyHandler(dbConn.DB))
+	// Background work stops when this context is cancelled during shutdown
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
 
-		// Quote creation
-		apiGroup.POST("/quote", handlers.CreateQuoteHandler(dbConn.DB))
+	store := database.NewPostgresStore(dbConn.DB)
 
-		// Quote modification (requires ownership)
-		apiGroup.PUT("/quote/:id", middleware.QuoteOwnershipRequired(dbConn.DB), handlers.UpdateQuoteHandler(dbConn.DB))
-		apiGroup.DELETE("/quote/:id", middleware.QuoteOwnershipRequired(dbConn.DB), handlers.DeleteQuoteHandler(dbConn.DB))
+	// Initialize services
+	emailService := services.NewEmailService(cfg.SMTP, cfg.AppURL)
+	geminiService := services.NewGeminiService(cfg.Gemini)
 
-		// Writing prompt generation
-		apiGroup.POST("/generate-prompt", handlers.GeneratePromptHandler(dbConn.DB, geminiService))
+	bookCatalog, err := services.NewBookCatalog(cfg.Books)
+	if err != nil {
+		fatal("failed to create book catalog", err)
 	}
+	bookEnricher := services.NewBookEnricher(store, bookCatalog)
+	bookEnricher.Start(backgroundCtx)
+
+	webhookSender := services.NewWebhookSender(store, services.WebhookGuard{})
+	webhookSender.Start(backgroundCtx)
+
+	trashPurger := services.NewTrashPurger(store, webhookSender, cfg.Trash)
+	trashPurger.Start(backgroundCtx)
 
-	// Device API routes - token-based auth for Pi client and other devices
-	deviceGroup := r.Group("/api/device")
-	deviceGroup.Use(middleware.APITokenRequired())
-	{
-		deviceGroup.POST("/quote", handlers.CreateQuoteHandler(dbConn.DB))
+	// Digests can only go out when email is configured
+	digestSender := services.NewDigestSender(store, emailService)
+	if emailService.IsConfigured() {
+		digestSender.Start(backgroundCtx)
 	}
 
-	return r
-}
+	cardRenderer, err := services.NewQuoteCardRenderer(config.QuoteCardCacheSize)
+	if err != nil {
+		fatal("failed to create card renderer", err)
+	}
 
-func main() {
-	dbConn, err := database.StartDatabase()
+	readiness := &handlers.Readiness{}
+	app := &handlers.App{
+		DB:           dbConn.DB,
+		Quotes:       store,
+		Users:        store,
+		Tokens:       store,
+		Filters:      store,
+		Interactions: store,
+		Follows:      store,
+		Digests:      store,
+		Reviews:      store,
+		Webhooks:     store,
+		Email:        emailService,
+		Gemini:       geminiService,
+		Catalog:      bookCatalog,
+		Enricher:     bookEnricher,
+		Cards:        cardRenderer,
+		Readiness:    readiness,
+		Device:       cfg.Device,
+
+		AppURL:         cfg.AppURL,
+		TrashRetention: cfg.Trash.Retention,
+	}
+	r, err := setupRouter(cfg, app)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to start database: %v", err))
+		fatal("failed to set up router", err)
 	}
-	defer dbConn.DB.Close()
 
-	// Initialize services
-	emailService := services.NewEmailService()
-	geminiService := services.NewGeminiService()
+	srv := &http.Server{
+		Addr:              cfg.Server.ListenAddr,
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Info("server listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// Metrics are served on their own port so the public ingress never exposes them
+	var metricsSrv *http.Server
+	if cfg.Server.MetricsAddr != "" {
+		metricsSrv = &http.Server{
+			Addr:              cfg.Server.MetricsAddr,
+			Handler:           metrics.Handler(),
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			WriteTimeout:      cfg.Server.WriteTimeout,
+			IdleTimeout:       cfg.Server.IdleTimeout,
+		}
+		go func() {
+			slog.Info("metrics listening", "addr", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- err
+			}
+		}()
+	}
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	select {
+	case err := <-serverErr:
+		slog.Error("server failed", "error", err)
+		shutdown(stopBackground, dbConn, bookEnricher, trashPurger, digestSender, webhookSender)
+		os.Exit(1)
+	case <-signalCtx.Done():
+		slog.Info("shutdown signal received, draining", "drain_delay", cfg.Server.DrainDelay.String())
+
+		// Fail readiness first so the load balancer stops routing to this pod
+		readiness.StartDraining()
+		time.Sleep(cfg.Server.DrainDelay)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("server did not drain in time", "error", err)
+		}
+		if metricsSrv != nil {
+			if err := metricsSrv.Shutdown(shutdownCtx); err != nil {
+				slog.Error("metrics server did not stop in time", "error", err)
+			}
+		}
+	}
+
+	shutdown(stopBackground, dbConn, bookEnricher, trashPurger, digestSender, webhookSender)
+}
+
+// fatal logs a startup failure and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-	r := setupRouter(dbConn, emailService, geminiService)
-	r.Run(":8080")
+// backgroundWorker is a service whose goroutines run until the background context is cancelled
+type backgroundWorker interface {
+	Wait()
+}
+
+// shutdown stops background workers, then closes the database once nothing can use it
+func shutdown(stopBackground context.CancelFunc, dbConn *database.DBConnection, workers ...backgroundWorker) {
+	stopBackground()
+	for _, w := range workers {
+		w.Wait()
+	}
+
+	if err := dbConn.DB.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+	}
+	slog.Info("shutdown complete")
 }
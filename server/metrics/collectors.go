@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/zach-monroe/zetl/server/database"
+)
+
+// RegisterDB exposes connection pool stats and row totals for the database
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(
+		collectors.NewDBStatsCollector(db, "zetl"),
+		&totalsCollector{db: db},
+	)
+}
+
+var (
+	usersDesc       = prometheus.NewDesc(namespace+"_users", "Registered users.", nil, nil)
+	quotesDesc      = prometheus.NewDesc(namespace+"_quotes", "Stored quotes.", nil, nil)
+	collectionsDesc = prometheus.NewDesc(namespace+"_collections", "Quote collections.", nil, nil)
+	booksDesc       = prometheus.NewDesc(namespace+"_books", "Tracked books.", nil, nil)
+)
+
+// totalsCollector reads business gauges from the database at scrape time
+type totalsCollector struct {
+	db *sql.DB
+}
+
+func (t *totalsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- usersDesc
+	ch <- quotesDesc
+	ch <- collectionsDesc
+	ch <- booksDesc
+}
+
+func (t *totalsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	totals, err := database.GetSiteTotals(ctx, t.db)
+	if err != nil {
+		slog.Warn("failed to read site totals", "component", "metrics", "error", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(usersDesc, prometheus.GaugeValue, float64(totals.Users))
+	ch <- prometheus.MustNewConstMetric(quotesDesc, prometheus.GaugeValue, float64(totals.Quotes))
+	ch <- prometheus.MustNewConstMetric(collectionsDesc, prometheus.GaugeValue, float64(totals.Collections))
+	ch <- prometheus.MustNewConstMetric(booksDesc, prometheus.GaugeValue, float64(totals.Books))
+}
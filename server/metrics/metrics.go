@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "zetl"
+
+// HTTP
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// LLM
+var (
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Latency of calls to the LLM provider.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32},
+	}, []string{"model", "outcome"})
+
+	LLMErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_errors_total",
+		Help:      "Failed calls to the LLM provider by reason.",
+	}, []string{"model", "reason"})
+
+	LLMTokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Tokens consumed by the LLM provider, split into prompt and completion.",
+	}, []string{"model", "type"})
+)
+
+// Email
+var EmailsSentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "emails_sent_total",
+	Help:      "Emails sent by kind and outcome (sent, failed, not_configured).",
+}, []string{"kind", "outcome"})
+
+// Business
+var (
+	QuotesCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quotes_created_total",
+		Help:      "Quotes created.",
+	})
+
+	QuotesDeletedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quotes_deleted_total",
+		Help:      "Quotes moved to the trash.",
+	})
+
+	QuotesPurgedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quotes_purged_total",
+		Help:      "Quotes permanently deleted from the trash, by the user or after the retention period.",
+	})
+
+	QuotesSavedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quotes_saved_total",
+		Help:      "Copies of other users' quotes saved to a user's own quotes.",
+	})
+
+	SignupsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "signups_total",
+		Help:      "New user accounts created.",
+	})
+
+	PromptsGeneratedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prompts_generated_total",
+		Help:      "Writing prompts generated from quotes.",
+	})
+)
+
+// Outcome returns "success" or "error" for use as a label value
+func Outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// Handler serves the Prometheus scrape endpoint at /metrics. It is mounted on
+// the internal metrics listener, never on the public router.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
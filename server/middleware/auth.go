@@ -1,15 +1,16 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"database/sql"
 	"errors"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/config"
 	"github.com/zach-monroe/zetl/server/database"
 )
 
@@ -45,14 +46,11 @@ func OptionalAuth() gin.HandlerFunc {
 	}
 }
 
-// APITokenRequired validates a Bearer token against the API_TOKEN env var
-// and sets user_id from API_TOKEN_USER_ID env var
-func APITokenRequired() gin.HandlerFunc {
+// APITokenRequired validates a Bearer token against the configured device
+// token and sets user_id to the configured device user
+func APITokenRequired(cfg config.Device) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		apiToken := os.Getenv("API_TOKEN")
-		apiTokenUserID := os.Getenv("API_TOKEN_USER_ID")
-
-		if apiToken == "" || apiTokenUserID == "" {
+		if !cfg.IsConfigured() {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "API token not configured"})
 			c.Abort()
 			return
@@ -66,26 +64,19 @@ func APITokenRequired() gin.HandlerFunc {
 		}
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token != apiToken {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(cfg.APIToken)) != 1 {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		userID, err := strconv.Atoi(apiTokenUserID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid API_TOKEN_USER_ID configuration"})
-			c.Abort()
-			return
-		}
-
-		c.Set("user_id", userID)
+		c.Set("user_id", cfg.UserID)
 		c.Next()
 	}
 }
 
 // QuoteOwnershipRequired verifies that the authenticated user owns the quote
-func QuoteOwnershipRequired(db *sql.DB) gin.HandlerFunc {
+func QuoteOwnershipRequired(quotes database.QuoteStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user_id from context (set by AuthRequired middleware)
 		userID, exists := c.Get("user_id")
@@ -105,7 +96,7 @@ func QuoteOwnershipRequired(db *sql.DB) gin.HandlerFunc {
 		}
 
 		// Verify ownership
-		isOwner, err := database.VerifyQuoteOwnership(c.Request.Context(), db, quoteID, userID.(int))
+		isOwner, err := quotes.VerifyQuoteOwnership(c.Request.Context(), quoteID, userID.(int))
 		if err != nil {
 			if errors.Is(err, database.ErrQuoteNotFound) {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Quote not found"})
@@ -127,3 +118,114 @@ func QuoteOwnershipRequired(db *sql.DB) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// BookOwnershipRequired verifies that the authenticated user owns the book
+func BookOwnershipRequired(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		bookID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID"})
+			c.Abort()
+			return
+		}
+
+		isOwner, err := database.VerifyBookOwnership(c.Request.Context(), db, bookID, userID.(int))
+		if err != nil {
+			if errors.Is(err, database.ErrBookNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ownership"})
+			}
+			c.Abort()
+			return
+		}
+
+		if !isOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to modify this book"})
+			c.Abort()
+			return
+		}
+
+		c.Set("book_id", bookID)
+		c.Next()
+	}
+}
+
+// CollectionOwnershipRequired verifies that the authenticated user owns the collection
+func CollectionOwnershipRequired(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		collectionID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+			c.Abort()
+			return
+		}
+
+		isOwner, err := database.VerifyCollectionOwnership(c.Request.Context(), db, collectionID, userID.(int))
+		if err != nil {
+			if errors.Is(err, database.ErrCollectionNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ownership"})
+			}
+			c.Abort()
+			return
+		}
+
+		if !isOwner {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to modify this collection"})
+			c.Abort()
+			return
+		}
+
+		c.Set("collection_id", collectionID)
+		c.Next()
+	}
+}
+
+// WebhookOwnershipRequired verifies that the authenticated user owns the
+// webhook. Other users' webhooks are reported as not found.
+func WebhookOwnershipRequired(webhooks database.WebhookStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		webhookID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+			c.Abort()
+			return
+		}
+
+		if _, err := webhooks.GetWebhook(c.Request.Context(), userID.(int), webhookID); err != nil {
+			if errors.Is(err, database.ErrWebhookNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ownership"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("webhook_id", webhookID)
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/logging"
+)
+
+// RequestLogger writes one structured line per request. Only the route
+// template is logged for matched routes so tokens in paths (share links,
+// reset links) never reach the logs.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if c.FullPath() == "" {
+			attrs = append(attrs, "path", c.Request.URL.Path)
+		}
+		if userID, exists := c.Get("user_id"); exists {
+			attrs = append(attrs, "user_id", userID)
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns panics into 500 responses and logs them with the request ID
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logging.FromContext(c.Request.Context()).Error("panic recovered",
+			"error", err,
+			"route", c.FullPath(),
+			"stack", string(debug.Stack()),
+		)
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
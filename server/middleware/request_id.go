@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zach-monroe/zetl/server/logging"
+)
+
+// maxRequestIDLength bounds client-supplied request IDs so they can't bloat logs
+const maxRequestIDLength = 128
+
+// RequestID reuses a valid X-Request-ID from the client or generates one,
+// echoes it in the response, and attaches a logger carrying it to the request context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(logging.RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(logging.RequestIDHeader, requestID)
+
+		logger := slog.Default().With("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.WithContext(c.Request.Context(), logger))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts short IDs made of letters, digits, '-', '_' and '.'
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Book enrichment statuses
+const (
+	BookStatusPending     = "pending"
+	BookStatusNeedsReview = "needs_review"
+	BookStatusConfirmed   = "confirmed"
+	BookStatusNotFound    = "not_found"
+)
+
+type Book struct {
+	BookID      int             `json:"book_id"`
+	UserID      int             `json:"user_id"`
+	Title       string          `json:"title"`
+	Author      string          `json:"author"`
+	ISBN        string          `json:"isbn"`
+	CoverURL    string          `json:"cover_url"`
+	PublishYear *int            `json:"publish_year,omitempty"`
+	PageCount   *int            `json:"page_count,omitempty"`
+	Status      string          `json:"status"`
+	Candidates  []BookCandidate `json:"candidates,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// BookCandidate is a possible catalog match for a Book
+type BookCandidate struct {
+	CandidateID int    `json:"candidate_id"`
+	BookID      int    `json:"book_id"`
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	ISBN        string `json:"isbn"`
+	CoverURL    string `json:"cover_url"`
+	PublishYear *int   `json:"publish_year,omitempty"`
+	PageCount   *int   `json:"page_count,omitempty"`
+	SourceID    string `json:"source_id"`
+}
+
+// ConfirmBookRequest selects a candidate match or supplies an ISBN directly
+type ConfirmBookRequest struct {
+	CandidateID int    `json:"candidate_id"`
+	ISBN        string `json:"isbn"`
+}
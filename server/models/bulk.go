@@ -0,0 +1,61 @@
+package models
+
+import "slices"
+
+// Bulk quote actions
+const (
+	BulkAddTags          = "add_tags"
+	BulkRemoveTags       = "remove_tags"
+	BulkSetVisibility    = "set_visibility"
+	BulkMoveToCollection = "move_to_collection"
+	BulkDelete           = "delete"
+	BulkRestore          = "restore"
+)
+
+// Per-quote outcomes of a bulk operation
+const (
+	BulkResultOK       = "ok"
+	BulkResultNotFound = "not_found"
+)
+
+// BulkQuoteOperation is one action applied to many quotes. Only the fields
+// used by Action are read.
+type BulkQuoteOperation struct {
+	Action       string
+	QuoteIDs     []int
+	Tags         []string
+	Visibility   string
+	CollectionID int
+}
+
+// BulkQuoteResult reports what a bulk operation did to one quote
+type BulkQuoteResult struct {
+	QuoteID int    `json:"quote_id"`
+	Status  string `json:"status"`
+}
+
+// ApplyBulkTags returns current with tags added or removed. Added tags that
+// are already present are not duplicated.
+func ApplyBulkTags(action string, current, tags []string) []string {
+	next := make([]string, 0, len(current)+len(tags))
+
+	switch action {
+	case BulkAddTags:
+		next = append(next, current...)
+		for _, tag := range tags {
+			if !slices.Contains(next, tag) {
+				next = append(next, tag)
+			}
+		}
+	case BulkRemoveTags:
+		for _, tag := range current {
+			if !slices.Contains(tags, tag) {
+				next = append(next, tag)
+			}
+		}
+	default:
+		next = append(next, current...)
+	}
+
+	return next
+}
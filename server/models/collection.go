@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Visibility values. "inherit" follows the owner's QuotesPublic setting and
+// is only used by collections; "unlisted" is only used by quotes.
+const (
+	VisibilityInherit  = "inherit"
+	VisibilityPublic   = "public"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPrivate  = "private"
+)
+
+type Collection struct {
+	CollectionID int       `json:"collection_id"`
+	UserID       int       `json:"user_id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Visibility   string    `json:"visibility"`
+	QuoteCount   int       `json:"quote_count"`
+	Quotes       Quotes    `json:"quotes,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsPublic reports whether the collection can be viewed by anyone,
+// falling back to the owner's account-level privacy when set to inherit
+func (c *Collection) IsPublic(ownerSettings *PrivacySettings) bool {
+	switch c.Visibility {
+	case VisibilityPublic:
+		return true
+	case VisibilityPrivate:
+		return false
+	}
+	if ownerSettings == nil {
+		ownerSettings = DefaultPrivacySettings()
+	}
+	return ownerSettings.QuotesPublic
+}
+
+type CollectionRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=1000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=inherit public private"`
+}
+
+type AddCollectionQuoteRequest struct {
+	QuoteID int `json:"quote_id" binding:"required"`
+}
+
+type ReorderCollectionRequest struct {
+	QuoteIDs []int `json:"quote_ids" binding:"required"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Digest frequencies
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// Digest quote selections. Random picks any of the user's quotes; spaced
+// prefers the quotes that have gone longest without appearing in a digest.
+const (
+	DigestRandom = "random"
+	DigestSpaced = "spaced"
+)
+
+// DigestSubscription is a user's opt-in to the quote digest email
+type DigestSubscription struct {
+	UserID    int    `json:"-"`
+	Frequency string `json:"frequency"`
+	Selection string `json:"selection"`
+	// Timezone is an IANA name; SendHour and Weekday are in that zone
+	Timezone string       `json:"timezone"`
+	SendHour int          `json:"send_hour"`
+	Weekday  time.Weekday `json:"weekday"`
+
+	UnsubscribeToken string     `json:"-"`
+	LastSentAt       *time.Time `json:"last_sent_at,omitempty"`
+	NextSendAt       time.Time  `json:"next_send_at"`
+
+	// Username and Email are filled in for due digests
+	Username string `json:"-"`
+	Email    string `json:"-"`
+}
+
+// Location returns the subscription's time zone, falling back to UTC
+func (s *DigestSubscription) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NextSend returns the first scheduled send time strictly after t: SendHour
+// in the subscription's time zone, every day or on Weekday for weekly digests
+func (s *DigestSubscription) NextSend(t time.Time) time.Time {
+	local := t.In(s.Location())
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.SendHour, 0, 0, 0, local.Location())
+
+	step := 1
+	if s.Frequency == DigestWeekly {
+		step = 7
+		next = next.AddDate(0, 0, (int(s.Weekday)-int(next.Weekday())+7)%7)
+	}
+	for !next.After(t) {
+		next = next.AddDate(0, 0, step)
+	}
+	return next
+}
+
+// UpdateDigestRequest turns the digest on or changes its schedule
+type UpdateDigestRequest struct {
+	Frequency string `json:"frequency" binding:"required,oneof=daily weekly"`
+	Selection string `json:"selection" binding:"omitempty,oneof=random spaced"`
+	Timezone  string `json:"timezone" binding:"required"`
+	SendHour  *int   `json:"send_hour" binding:"omitempty,min=0,max=23"`
+	Weekday   *int   `json:"weekday" binding:"omitempty,min=0,max=6"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// MutedUser is a user whose quotes are left out of the viewer's feed
+type MutedUser struct {
+	ID       int       `json:"id"`
+	Username string    `json:"username"`
+	MutedAt  time.Time `json:"muted_at"`
+}
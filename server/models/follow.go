@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FollowedUser is a user whose public quotes appear in the follower's feed
+type FollowedUser struct {
+	ID         int       `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FeedCursor marks the last quote of a feed page. The next page starts with
+// the quotes created before it, using the quote ID to break ties.
+type FeedCursor struct {
+	CreatedAt time.Time
+	QuoteID   int
+}
+
+// ErrInvalidCursor is returned when a feed cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorAfter returns the cursor for the page following q
+func CursorAfter(q Quote) FeedCursor {
+	return FeedCursor{CreatedAt: q.CreatedAt, QuoteID: q.QuoteID}
+}
+
+// IsZero reports whether c is the start of the feed
+func (c FeedCursor) IsZero() bool {
+	return c.QuoteID == 0
+}
+
+// Before reports whether q comes after the cursor in newest-first order
+func (c FeedCursor) Before(q Quote) bool {
+	if c.IsZero() {
+		return true
+	}
+	if q.CreatedAt.Equal(c.CreatedAt) {
+		return q.QuoteID < c.QuoteID
+	}
+	return q.CreatedAt.Before(c.CreatedAt)
+}
+
+// Encode returns the opaque string form of the cursor used in URLs
+func (c FeedCursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.QuoteID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseFeedCursor decodes a cursor produced by Encode. An empty string is
+// the start of the feed.
+func ParseFeedCursor(s string) (FeedCursor, error) {
+	if s == "" {
+		return FeedCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return FeedCursor{}, ErrInvalidCursor
+	}
+
+	var nanos int64
+	var quoteID int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &quoteID); err != nil || quoteID <= 0 {
+		return FeedCursor{}, ErrInvalidCursor
+	}
+
+	return FeedCursor{CreatedAt: time.Unix(0, nanos).UTC(), QuoteID: quoteID}, nil
+}
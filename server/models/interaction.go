@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// SavedFrom attributes a saved copy to the quote and owner it was saved from
+type SavedFrom struct {
+	// QuoteID is nil once the original quote has been purged
+	QuoteID  *int   `json:"quote_id,omitempty"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// QuoteSaver is a user who saved a copy of a quote
+type QuoteSaver struct {
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	QuoteID  int       `json:"quote_id"`
+	SavedAt  time.Time `json:"saved_at"`
+}
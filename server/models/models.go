@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Quote struct {
 	QuoteID int      `json:"quote_id"`
 	UserID  int      `json:"user_id"`
@@ -8,19 +10,56 @@ type Quote struct {
 	Book    string   `json:"book"`
 	Tags    []string `json:"tags"`
 	Notes   string   `json:"notes"`
+	Citation
+
+	// Visibility is public (listed everywhere), unlisted (reachable by
+	// link only) or private (owner and share links only)
+	Visibility string `json:"visibility"`
+
+	// SavedFrom is set on copies saved from another user's quote
+	SavedFrom *SavedFrom `json:"saved_from,omitempty"`
+
+	// LikeCount is read with the quote; Liked is only filled in for
+	// listings that know the viewer
+	LikeCount int  `json:"like_count"`
+	Liked     bool `json:"liked"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// DeletedAt is set while the quote is in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
-// ToMap converts a Quote to a map for JSON serialization
-func (q Quote) ToMap() map[string]interface{} {
-	return map[string]interface{}{
-		"quote_id": q.QuoteID,
-		"user_id":  q.UserID,
-		"quote":    q.Quote,
-		"author":   q.Author,
-		"book":     q.Book,
-		"tags":     q.Tags,
-		"notes":    q.Notes,
-	}
+// Citation records where in a source a quote came from
+type Citation struct {
+	Page      string `json:"page" binding:"omitempty,max=20"`
+	Chapter   string `json:"chapter" binding:"omitempty,max=200"`
+	Location  string `json:"location" binding:"omitempty,max=50"`
+	Edition   string `json:"edition" binding:"omitempty,max=100"`
+	SourceURL string `json:"source_url" binding:"omitempty,url,max=2048"`
+	DateRead  string `json:"date_read" binding:"omitempty,datetime=2006-01-02"` // YYYY-MM-DD
+}
+
+// IsEmpty reports whether no citation fields are set
+func (c Citation) IsEmpty() bool {
+	return c == Citation{}
+}
+
+// IsLinkable reports whether anyone with a direct link may view the quote
+func (q *Quote) IsLinkable() bool {
+	return q.Visibility != VisibilityPrivate
 }
 
 type Quotes []Quote
+
+// Linkable returns the quotes that anyone with a direct link may view
+func (qs Quotes) Linkable() Quotes {
+	linkable := make(Quotes, 0, len(qs))
+	for _, q := range qs {
+		if q.IsLinkable() {
+			linkable = append(linkable, q)
+		}
+	}
+	return linkable
+}
@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// QuoteFilter selects the quotes a single random or daily quote is picked
+// from. Viewers see public quotes plus their own, minus the quotes and users
+// they have hidden or muted; a ViewerID of 0 sees public quotes only.
+type QuoteFilter struct {
+	ViewerID int
+	// UserID, when set, limits the pick to one user's quotes
+	UserID int
+	// Tag, when set, must be one of the quote's tags
+	Tag string
+	// Author, when set, must appear in the quote's author, ignoring case
+	Author string
+}
+
+// Matches reports whether q passes every field of the filter except the
+// viewer's hidden quotes and muted users
+func (f QuoteFilter) Matches(q Quote) bool {
+	if q.DeletedAt != nil || (q.Visibility != VisibilityPublic && (f.ViewerID == 0 || q.UserID != f.ViewerID)) {
+		return false
+	}
+	if f.UserID != 0 && q.UserID != f.UserID {
+		return false
+	}
+	if f.Author != "" && !strings.Contains(strings.ToLower(q.Author), strings.ToLower(f.Author)) {
+		return false
+	}
+	if f.Tag == "" {
+		return true
+	}
+	for _, t := range q.Tags {
+		if t == f.Tag {
+			return true
+		}
+	}
+	return false
+}
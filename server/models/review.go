@@ -0,0 +1,125 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// SM-2 review grades run from MinGrade (complete blackout) to MaxGrade
+// (perfect recall). Grades below PassingGrade start the quote over.
+const (
+	MinGrade     = 0
+	PassingGrade = 3
+	MaxGrade     = 5
+)
+
+const (
+	// DefaultEaseFactor is the ease of a quote that has never been reviewed
+	DefaultEaseFactor = 2.5
+	// MinEaseFactor stops hard quotes from being shown ever more often
+	MinEaseFactor = 1.3
+)
+
+// QuoteReview is a user's spaced-repetition state for one of their quotes
+type QuoteReview struct {
+	QuoteID        int       `json:"quote_id"`
+	EaseFactor     float64   `json:"ease_factor"`
+	IntervalDays   int       `json:"interval_days"`
+	Repetitions    int       `json:"repetitions"`
+	LastGrade      int       `json:"last_grade"`
+	LastReviewedAt time.Time `json:"last_reviewed_at"`
+	DueAt          time.Time `json:"due_at"`
+}
+
+// NewQuoteReview returns the state of a quote that has never been reviewed
+func NewQuoteReview(quoteID int) QuoteReview {
+	return QuoteReview{QuoteID: quoteID, EaseFactor: DefaultEaseFactor}
+}
+
+// Grade applies an SM-2 review graded at now: a pass lengthens the interval
+// (1 day, then 6, then by the ease factor), a fail starts the quote over
+// tomorrow, and either way the ease factor moves with the grade.
+func (r *QuoteReview) Grade(grade int, now time.Time) {
+	if grade < PassingGrade {
+		r.Repetitions = 0
+		r.IntervalDays = 1
+	} else {
+		switch r.Repetitions {
+		case 0:
+			r.IntervalDays = 1
+		case 1:
+			r.IntervalDays = 6
+		default:
+			r.IntervalDays = int(math.Round(float64(r.IntervalDays) * r.EaseFactor))
+		}
+		r.Repetitions++
+	}
+
+	miss := float64(MaxGrade - grade)
+	r.EaseFactor = math.Max(MinEaseFactor, r.EaseFactor+0.1-miss*(0.08+miss*0.02))
+
+	r.LastGrade = grade
+	r.LastReviewedAt = now
+	r.DueAt = now.AddDate(0, 0, r.IntervalDays)
+}
+
+// ReviewFilter narrows the quotes offered for review by tag
+type ReviewFilter struct {
+	// Tags, when set, limits review to quotes with at least one of them
+	Tags []string
+	// ExcludeTags leaves out quotes with any of them
+	ExcludeTags []string
+}
+
+// Matches reports whether q passes the filter
+func (f ReviewFilter) Matches(q Quote) bool {
+	has := func(tags []string) bool {
+		for _, t := range tags {
+			for _, qt := range q.Tags {
+				if qt == t {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return (len(f.Tags) == 0 || has(f.Tags)) && !has(f.ExcludeTags)
+}
+
+// ReviewCard is the next quote due for review. Review is nil for quotes
+// that have never been reviewed.
+type ReviewCard struct {
+	Quote  Quote
+	Review *QuoteReview
+}
+
+// Cloze modes choose what a review card hides
+const (
+	ClozeWords  = "words"
+	ClozeAuthor = "author"
+)
+
+// Cloze is a quote with parts hidden for recall practice. It carries none
+// of the hidden text, so it can be sent before the review is graded.
+type Cloze struct {
+	Mode string `json:"mode"`
+	// Parts is the quote text split into shown pieces and blanks
+	Parts        []ClozePart `json:"parts"`
+	AuthorHidden bool        `json:"author_hidden"`
+	// Author and Book are only set when the author isn't hidden
+	Author string `json:"author,omitempty"`
+	Book   string `json:"book,omitempty"`
+}
+
+// ClozePart is a shown piece of quote text, or a blank standing in for a
+// hidden word of Length characters
+type ClozePart struct {
+	Text   string `json:"text,omitempty"`
+	Hidden bool   `json:"hidden,omitempty"`
+	Length int    `json:"length,omitempty"`
+}
+
+// GradeReviewRequest grades a review on the SM-2 scale
+type GradeReviewRequest struct {
+	Grade *int `json:"grade" binding:"required,min=0,max=5"`
+}
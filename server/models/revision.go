@@ -0,0 +1,94 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// QuoteRevision is a quote's content as it stood before an edit replaced it.
+// Revisions are numbered from 1 in the order the edits happened.
+type QuoteRevision struct {
+	QuoteID  int      `json:"quote_id"`
+	Revision int      `json:"revision"`
+	Quote    string   `json:"quote"`
+	Author   string   `json:"author"`
+	Book     string   `json:"book"`
+	Tags     []string `json:"tags"`
+	Notes    string   `json:"notes"`
+	Citation
+	CreatedAt time.Time `json:"created_at"`
+
+	// Changes lists what the edit that replaced this revision changed
+	Changes []FieldChange `json:"changes"`
+}
+
+// AsQuote returns the revision's content as a quote
+func (r QuoteRevision) AsQuote() Quote {
+	return Quote{
+		QuoteID:  r.QuoteID,
+		Quote:    r.Quote,
+		Author:   r.Author,
+		Book:     r.Book,
+		Tags:     r.Tags,
+		Notes:    r.Notes,
+		Citation: r.Citation,
+	}
+}
+
+// FieldChange describes how one field differs between two versions of a
+// quote. Tags are compared as sets and rendered comma-separated.
+type FieldChange struct {
+	Field   string   `json:"field"`
+	Old     string   `json:"old"`
+	New     string   `json:"new"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// DiffQuotes lists the user-editable fields that differ from old to new
+func DiffQuotes(old, new Quote) []FieldChange {
+	changes := make([]FieldChange, 0)
+	text := func(field, a, b string) {
+		if a != b {
+			changes = append(changes, FieldChange{Field: field, Old: a, New: b})
+		}
+	}
+
+	text("quote", old.Quote, new.Quote)
+	text("author", old.Author, new.Author)
+	text("book", old.Book, new.Book)
+
+	added := missingFrom(old.Tags, new.Tags)
+	removed := missingFrom(new.Tags, old.Tags)
+	if len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, FieldChange{
+			Field:   "tags",
+			Old:     strings.Join(old.Tags, ", "),
+			New:     strings.Join(new.Tags, ", "),
+			Added:   added,
+			Removed: removed,
+		})
+	}
+
+	text("notes", old.Notes, new.Notes)
+	text("page", old.Page, new.Page)
+	text("chapter", old.Chapter, new.Chapter)
+	text("location", old.Location, new.Location)
+	text("edition", old.Edition, new.Edition)
+	text("source_url", old.SourceURL, new.SourceURL)
+	text("date_read", old.DateRead, new.DateRead)
+
+	return changes
+}
+
+// missingFrom returns the tags in b that are not in a
+func missingFrom(a, b []string) []string {
+	var missing []string
+	for _, tag := range b {
+		if !slices.Contains(a, tag) && !slices.Contains(missing, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ShareLink grants read-only access to a quote or collection without login
+type ShareLink struct {
+	ShareID      int        `json:"share_id"`
+	Token        string     `json:"token"`
+	UserID       int        `json:"user_id"`
+	QuoteID      *int       `json:"quote_id,omitempty"`
+	CollectionID *int       `json:"collection_id,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Path returns the public path for the link
+func (s *ShareLink) Path() string {
+	return "/s/" + s.Token
+}
+
+// IsActive reports whether the link is neither revoked nor expired
+func (s *ShareLink) IsActive() bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || s.ExpiresAt.After(time.Now())
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
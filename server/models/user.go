@@ -8,6 +8,24 @@ import (
 type PrivacySettings struct {
 	ProfilePublic bool `json:"profile_public"`
 	QuotesPublic  bool `json:"quotes_public"`
+
+	// DefaultVisibility is given to new quotes that don't set their own
+	DefaultVisibility string `json:"default_visibility,omitempty"`
+}
+
+// QuoteVisibility returns the visibility for a new quote. Accounts that
+// haven't chosen a default follow QuotesPublic.
+func (p *PrivacySettings) QuoteVisibility() string {
+	switch {
+	case p == nil:
+		return VisibilityPublic
+	case p.DefaultVisibility != "":
+		return p.DefaultVisibility
+	case p.QuotesPublic:
+		return VisibilityPublic
+	default:
+		return VisibilityPrivate
+	}
 }
 
 type User struct {
@@ -78,8 +96,9 @@ type UpdatePasswordRequest struct {
 }
 
 type UpdatePrivacyRequest struct {
-	ProfilePublic *bool `json:"profile_public"`
-	QuotesPublic  *bool `json:"quotes_public"`
+	ProfilePublic     *bool   `json:"profile_public"`
+	QuotesPublic      *bool   `json:"quotes_public"`
+	DefaultVisibility *string `json:"default_visibility" binding:"omitempty,oneof=public unlisted private"`
 }
 
 // Password reset types
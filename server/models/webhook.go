@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Quote lifecycle events a webhook can subscribe to. A deleted quote goes to
+// the trash, and is announced as restored if it comes back out under the same
+// ID. Quotes purged from the trash, by hand or when the retention period runs
+// out, are announced as deleted a second time, with the purge recorded in the
+// payload's occurred_at.
+const (
+	WebhookQuoteCreated  = "quote.created"
+	WebhookQuoteUpdated  = "quote.updated"
+	WebhookQuoteDeleted  = "quote.deleted"
+	WebhookQuoteRestored = "quote.restored"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{WebhookQuoteCreated, WebhookQuoteUpdated, WebhookQuoteDeleted, WebhookQuoteRestored}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	// MaxDeliveryAttempts is how many times a delivery is tried before it is
+	// marked failed
+	MaxDeliveryAttempts = 8
+	// DeliveryRetryDelay is the wait after the first failed attempt; it
+	// doubles after each further failure
+	DeliveryRetryDelay = time.Minute
+)
+
+// Webhook posts a user's quote events to a URL
+type Webhook struct {
+	WebhookID int    `json:"webhook_id"`
+	UserID    int    `json:"-"`
+	URL       string `json:"url"`
+	// Secret signs each payload; it is only shown when the webhook is created
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook is active and wants event
+func (w *Webhook) Subscribes(event string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent, or waiting to be sent, to a webhook
+type WebhookDelivery struct {
+	DeliveryID int             `json:"delivery_id"`
+	WebhookID  int             `json:"webhook_id"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	Status     string          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	// NextAttemptAt is only set while the delivery is pending
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// URL and Secret are filled in for claimed deliveries
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// RecordAttempt applies the outcome of an attempt made at now. A 2xx
+// response succeeds; anything else is retried after DeliveryRetryDelay,
+// doubling each time, until MaxDeliveryAttempts have failed.
+func (d *WebhookDelivery) RecordAttempt(now time.Time, responseStatus int, err error) {
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.ResponseStatus = nil
+	d.Error = ""
+	if responseStatus != 0 {
+		d.ResponseStatus = &responseStatus
+	}
+
+	switch {
+	case err != nil:
+		d.Error = err.Error()
+	case responseStatus < 200 || responseStatus > 299:
+		d.Error = "unexpected response status"
+	default:
+		d.Status = DeliverySucceeded
+		d.NextAttemptAt = nil
+		return
+	}
+
+	if d.Attempts >= MaxDeliveryAttempts {
+		d.Status = DeliveryFailed
+		d.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(DeliveryRetryDelay << (d.Attempts - 1))
+	d.Status = DeliveryPending
+	d.NextAttemptAt = &next
+}
+
+// WebhookRequest creates or updates a webhook. Active defaults to true.
+type WebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=2000"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=quote.created quote.updated quote.deleted quote.restored"`
+	Active *bool    `json:"active"`
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+)
+
+// ErrBookNotInCatalog is returned when a catalog has no record for a lookup
+var ErrBookNotInCatalog = errors.New("book not found in catalog")
+
+// BookMetadata represents a single catalog record for a book
+type BookMetadata struct {
+	Title       string `json:"title"`
+	Author      string `json:"author"`
+	ISBN        string `json:"isbn"`
+	CoverURL    string `json:"cover_url"`
+	PublishYear int    `json:"publish_year"`
+	PageCount   int    `json:"page_count"`
+	SourceID    string `json:"source_id"`
+}
+
+// BookCatalog looks up book metadata from an external source
+type BookCatalog interface {
+	// SearchBooks returns candidate matches for a title and optional author
+	SearchBooks(ctx context.Context, title, author string) ([]BookMetadata, error)
+	// LookupISBN returns the record for a specific ISBN
+	LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error)
+}
+
+// NewBookCatalog returns the fixture catalog when a fixture path is configured,
+// otherwise the Open Library catalog
+func NewBookCatalog(cfg config.Books) (BookCatalog, error) {
+	if cfg.CatalogFixture != "" {
+		return NewFixtureBookCatalog(cfg.CatalogFixture)
+	}
+	return NewOpenLibraryCatalog(), nil
+}
+
+// OpenLibraryCatalog implements BookCatalog against the Open Library API
+type OpenLibraryCatalog struct {
+	baseURL    string
+	coversURL  string
+	maxResults int
+	client     *http.Client
+}
+
+// NewOpenLibraryCatalog creates a new OpenLibraryCatalog instance
+func NewOpenLibraryCatalog() *OpenLibraryCatalog {
+	return &OpenLibraryCatalog{
+		baseURL:    "https://openlibrary.org",
+		coversURL:  "https://covers.openlibrary.org",
+		maxResults: 5,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// openLibrarySearchResponse is the subset of /search.json we use
+type openLibrarySearchResponse struct {
+	Docs []struct {
+		Key              string   `json:"key"`
+		Title            string   `json:"title"`
+		AuthorName       []string `json:"author_name"`
+		ISBN             []string `json:"isbn"`
+		CoverID          int      `json:"cover_i"`
+		FirstPublishYear int      `json:"first_publish_year"`
+		PageCount        int      `json:"number_of_pages_median"`
+	} `json:"docs"`
+}
+
+// openLibraryEdition is the subset of /isbn/{isbn}.json we use
+type openLibraryEdition struct {
+	Key           string `json:"key"`
+	Title         string `json:"title"`
+	ByStatement   string `json:"by_statement"`
+	PublishDate   string `json:"publish_date"`
+	NumberOfPages int    `json:"number_of_pages"`
+	Covers        []int  `json:"covers"`
+}
+
+// SearchBooks queries the Open Library search API
+func (o *OpenLibraryCatalog) SearchBooks(ctx context.Context, title, author string) ([]BookMetadata, error) {
+	params := url.Values{}
+	params.Set("title", title)
+	if author != "" {
+		params.Set("author", author)
+	}
+	params.Set("limit", fmt.Sprintf("%d", o.maxResults))
+	params.Set("fields", "key,title,author_name,isbn,cover_i,first_publish_year,number_of_pages_median")
+
+	var resp openLibrarySearchResponse
+	if err := o.getJSON(ctx, o.baseURL+"/search.json?"+params.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]BookMetadata, 0, len(resp.Docs))
+	for _, doc := range resp.Docs {
+		meta := BookMetadata{
+			Title:       doc.Title,
+			Author:      strings.Join(doc.AuthorName, ", "),
+			PublishYear: doc.FirstPublishYear,
+			PageCount:   doc.PageCount,
+			SourceID:    doc.Key,
+		}
+		if len(doc.ISBN) > 0 {
+			meta.ISBN = doc.ISBN[0]
+		}
+		if doc.CoverID > 0 {
+			meta.CoverURL = fmt.Sprintf("%s/b/id/%d-M.jpg", o.coversURL, doc.CoverID)
+		}
+		results = append(results, meta)
+	}
+
+	return results, nil
+}
+
+// LookupISBN queries the Open Library edition API for an ISBN
+func (o *OpenLibraryCatalog) LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	isbn = NormalizeISBN(isbn)
+	if isbn == "" {
+		return nil, errors.New("invalid ISBN")
+	}
+
+	var edition openLibraryEdition
+	if err := o.getJSON(ctx, fmt.Sprintf("%s/isbn/%s.json", o.baseURL, isbn), &edition); err != nil {
+		return nil, err
+	}
+
+	meta := &BookMetadata{
+		Title:       edition.Title,
+		Author:      strings.TrimSuffix(edition.ByStatement, "."),
+		ISBN:        isbn,
+		PublishYear: parsePublishYear(edition.PublishDate),
+		PageCount:   edition.NumberOfPages,
+		SourceID:    edition.Key,
+	}
+	if len(edition.Covers) > 0 && edition.Covers[0] > 0 {
+		meta.CoverURL = fmt.Sprintf("%s/b/id/%d-M.jpg", o.coversURL, edition.Covers[0])
+	}
+
+	return meta, nil
+}
+
+func (o *OpenLibraryCatalog) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Open Library: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrBookNotInCatalog
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Open Library returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
+
+// NormalizeISBN strips hyphens and spaces, returning "" if the result
+// isn't a plausible ISBN-10 or ISBN-13
+func NormalizeISBN(isbn string) string {
+	var b strings.Builder
+	for _, r := range isbn {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'X' || r == 'x':
+			b.WriteRune('X')
+		case r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+
+	normalized := b.String()
+	if len(normalized) != 10 && len(normalized) != 13 {
+		return ""
+	}
+	return normalized
+}
+
+// parsePublishYear extracts a four digit year from free-form dates like "March 3, 1998"
+func parsePublishYear(date string) int {
+	for i := 0; i+4 <= len(date); i++ {
+		var year int
+		if _, err := fmt.Sscanf(date[i:i+4], "%4d", &year); err == nil && year >= 1000 {
+			return year
+		}
+	}
+	return 0
+}
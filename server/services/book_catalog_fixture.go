@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FixtureBookCatalog implements BookCatalog from a local JSON file.
+// It is used for tests and offline development.
+type FixtureBookCatalog struct {
+	books []BookMetadata
+}
+
+// NewFixtureBookCatalog loads a JSON array of BookMetadata from path
+func NewFixtureBookCatalog(path string) (*FixtureBookCatalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read book fixture: %w", err)
+	}
+
+	var books []BookMetadata
+	if err := json.Unmarshal(data, &books); err != nil {
+		return nil, fmt.Errorf("failed to parse book fixture: %w", err)
+	}
+
+	return &FixtureBookCatalog{books: books}, nil
+}
+
+// SearchBooks returns fixtures whose title contains the query and whose
+// author matches when one is given
+func (f *FixtureBookCatalog) SearchBooks(ctx context.Context, title, author string) ([]BookMetadata, error) {
+	title = strings.ToLower(strings.TrimSpace(title))
+	author = strings.ToLower(strings.TrimSpace(author))
+
+	results := make([]BookMetadata, 0)
+	for _, b := range f.books {
+		if !strings.Contains(strings.ToLower(b.Title), title) {
+			continue
+		}
+		if author != "" && !strings.Contains(strings.ToLower(b.Author), author) {
+			continue
+		}
+		results = append(results, b)
+	}
+
+	return results, nil
+}
+
+// LookupISBN returns the fixture with a matching ISBN
+func (f *FixtureBookCatalog) LookupISBN(ctx context.Context, isbn string) (*BookMetadata, error) {
+	isbn = NormalizeISBN(isbn)
+	for _, b := range f.books {
+		if NormalizeISBN(b.ISBN) == isbn && isbn != "" {
+			match := b
+			return &match, nil
+		}
+	}
+	return nil, ErrBookNotInCatalog
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// BookEnricher looks up catalog metadata for newly seen books in the background
+type BookEnricher struct {
+	books   database.BookStore
+	catalog BookCatalog
+	jobs    chan int
+	wg      sync.WaitGroup
+}
+
+// NewBookEnricher creates a new BookEnricher instance
+func NewBookEnricher(books database.BookStore, catalog BookCatalog) *BookEnricher {
+	return &BookEnricher{
+		books:   books,
+		catalog: catalog,
+		jobs:    make(chan int, 100),
+	}
+}
+
+// Start processes queued books until ctx is cancelled. Books left pending
+// from a previous run are re-queued first.
+func (e *BookEnricher) Start(ctx context.Context) {
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		pending, err := e.books.GetPendingBookIDs(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to load pending books", "component", "book_enricher", "error", err)
+		}
+		for _, bookID := range pending {
+			e.Enqueue(ctx, bookID)
+		}
+	}()
+
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case bookID := <-e.jobs:
+				if err := e.Enrich(ctx, bookID); err != nil {
+					logging.FromContext(ctx).Error("failed to enrich book", "component", "book_enricher", "book_id", bookID, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until the workers started by Start have returned. Cancel the
+// context passed to Start first; a lookup in progress is abandoned and its
+// book stays pending until the next start.
+func (e *BookEnricher) Wait() {
+	e.wg.Wait()
+}
+
+// Enqueue schedules a book for enrichment without blocking the caller.
+// If the queue is full the book stays pending and is picked up on next start.
+func (e *BookEnricher) Enqueue(ctx context.Context, bookID int) {
+	select {
+	case e.jobs <- bookID:
+	default:
+		logging.FromContext(ctx).Warn("queue full, deferring book", "component", "book_enricher", "book_id", bookID)
+	}
+}
+
+// TrackBook records a quote's book for the user and queues a catalog
+// lookup the first time the title appears
+func (e *BookEnricher) TrackBook(ctx context.Context, userID int, title, author string) {
+	title = strings.TrimSpace(title)
+	if !ShouldEnrichBook(title) {
+		return
+	}
+
+	bookID, created, err := e.books.EnsureBook(ctx, userID, title, strings.TrimSpace(author))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to record book", "user_id", userID, "title", title, "error", err)
+		return
+	}
+
+	if created {
+		e.Enqueue(ctx, bookID)
+	}
+}
+
+// Enrich searches the catalog for a book and stores the candidate matches
+// for the user to confirm
+func (e *BookEnricher) Enrich(ctx context.Context, bookID int) error {
+	book, err := e.books.GetBookByID(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if book.Status == models.BookStatusConfirmed {
+		return nil
+	}
+
+	results, err := e.catalog.SearchBooks(ctx, book.Title, book.Author)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		return e.books.ReplaceBookCandidates(ctx, bookID, nil, models.BookStatusNotFound)
+	}
+
+	candidates := make([]models.BookCandidate, len(results))
+	for i, r := range results {
+		candidates[i] = MetadataToCandidate(r)
+	}
+
+	return e.books.ReplaceBookCandidates(ctx, bookID, candidates, models.BookStatusNeedsReview)
+}
+
+// ShouldEnrichBook reports whether a book title is worth looking up.
+// The Pi client records illegible titles as "Unknown".
+func ShouldEnrichBook(title string) bool {
+	title = strings.TrimSpace(title)
+	return title != "" && !strings.EqualFold(title, "unknown")
+}
+
+// MetadataToCandidate converts catalog metadata into a storable candidate
+func MetadataToCandidate(m BookMetadata) models.BookCandidate {
+	c := models.BookCandidate{
+		Title:    m.Title,
+		Author:   m.Author,
+		ISBN:     m.ISBN,
+		CoverURL: m.CoverURL,
+		SourceID: m.SourceID,
+	}
+	if m.PublishYear > 0 {
+		year := m.PublishYear
+		c.PublishYear = &year
+	}
+	if m.PageCount > 0 {
+		pages := m.PageCount
+		c.PageCount = &pages
+	}
+	return c
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+func newTestCatalog(t *testing.T) *FixtureBookCatalog {
+	t.Helper()
+
+	catalog, err := NewFixtureBookCatalog("testdata/books.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return catalog
+}
+
+func TestFixtureBookCatalogSearch(t *testing.T) {
+	catalog := newTestCatalog(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		title  string
+		author string
+		want   []string
+	}{
+		{"match", "letters from a stoic", "Seneca", []string{"Letters from a Stoic"}},
+		{"author narrows matches", "Meditations", "marcus", []string{"Meditations", "Meditations: A New Translation"}},
+		{"author mismatch", "Meditations", "Seneca", nil},
+		{"missing author", " meditations ", "", []string{"Meditations", "Meditations: A New Translation"}},
+		{"miss", "The Name of the Wind", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := catalog.SearchBooks(ctx, tt.title, tt.author)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d: %+v", len(results), len(tt.want), results)
+			}
+			for i, r := range results {
+				if r.Title != tt.want[i] {
+					t.Errorf("result %d: got %q, want %q", i, r.Title, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFixtureBookCatalogLookupISBN(t *testing.T) {
+	catalog := newTestCatalog(t)
+	ctx := context.Background()
+
+	book, err := catalog.LookupISBN(ctx, "978-0-8070-1429-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Title != "Man's Search for Meaning" {
+		t.Errorf("got %q", book.Title)
+	}
+
+	if _, err := catalog.LookupISBN(ctx, "9780000000000"); !errors.Is(err, ErrBookNotInCatalog) {
+		t.Errorf("unknown ISBN: got %v, want ErrBookNotInCatalog", err)
+	}
+	if _, err := catalog.LookupISBN(ctx, ""); !errors.Is(err, ErrBookNotInCatalog) {
+		t.Errorf("empty ISBN: got %v, want ErrBookNotInCatalog", err)
+	}
+}
+
+func TestBookEnricherEnrich(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		author     string
+		wantStatus string
+		wantISBNs  []string
+	}{
+		{"match", "Letters from a Stoic", "Seneca", models.BookStatusNeedsReview, []string{"9780140442106"}},
+		{"missing author", "Meditations", "", models.BookStatusNeedsReview, []string{"9780812968255", "9780679642602"}},
+		{"miss", "The Name of the Wind", "Patrick Rothfuss", models.BookStatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := database.NewMemoryStore()
+			enricher := NewBookEnricher(store, newTestCatalog(t))
+
+			bookID, created, err := store.EnsureBook(ctx, 1, tt.title, tt.author)
+			if err != nil || !created {
+				t.Fatalf("EnsureBook: created=%v err=%v", created, err)
+			}
+
+			if err := enricher.Enrich(ctx, bookID); err != nil {
+				t.Fatal(err)
+			}
+
+			book, err := store.GetBookByID(ctx, bookID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if book.Status != tt.wantStatus {
+				t.Errorf("status: got %q, want %q", book.Status, tt.wantStatus)
+			}
+			if len(book.Candidates) != len(tt.wantISBNs) {
+				t.Fatalf("got %d candidates, want %d", len(book.Candidates), len(tt.wantISBNs))
+			}
+			for i, c := range book.Candidates {
+				if c.ISBN != tt.wantISBNs[i] {
+					t.Errorf("candidate %d: got ISBN %q, want %q", i, c.ISBN, tt.wantISBNs[i])
+				}
+				if c.BookID != bookID {
+					t.Errorf("candidate %d: got book %d, want %d", i, c.BookID, bookID)
+				}
+			}
+		})
+	}
+}
+
+func TestBookEnricherSkipsConfirmedBooks(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	enricher := NewBookEnricher(store, newTestCatalog(t))
+
+	bookID, _, err := store.EnsureBook(ctx, 1, "Meditations", "Marcus Aurelius")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ReplaceBookCandidates(ctx, bookID, nil, models.BookStatusConfirmed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enricher.Enrich(ctx, bookID); err != nil {
+		t.Fatal(err)
+	}
+
+	book, err := store.GetBookByID(ctx, bookID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Status != models.BookStatusConfirmed || len(book.Candidates) != 0 {
+		t.Errorf("confirmed book was re-enriched: status %q, %d candidates", book.Status, len(book.Candidates))
+	}
+}
+
+func TestBookEnricherTrackBook(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	enricher := NewBookEnricher(store, newTestCatalog(t))
+
+	enricher.TrackBook(ctx, 1, "  Meditations ", "Marcus Aurelius")
+	enricher.TrackBook(ctx, 1, "Meditations", "Marcus Aurelius")
+	enricher.TrackBook(ctx, 1, "Unknown", "")
+	enricher.TrackBook(ctx, 1, "", "")
+
+	pending, err := store.GetPendingBookIDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("got %d pending books, want 1", len(pending))
+	}
+	if got := len(enricher.jobs); got != 1 {
+		t.Errorf("got %d queued lookups, want 1", got)
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// Supported citation styles
+const (
+	CitationStyleMLA     = "mla"
+	CitationStyleAPA     = "apa"
+	CitationStyleChicago = "chicago"
+)
+
+// CitationStyles lists the supported styles in display order
+var CitationStyles = []string{CitationStyleMLA, CitationStyleAPA, CitationStyleChicago}
+
+// CitationSource is everything needed to cite a quote
+type CitationSource struct {
+	Author      string
+	Title       string
+	PublishYear int
+	models.Citation
+}
+
+// FormattedCitation is a rendered citation in plain text and HTML
+type FormattedCitation struct {
+	Style string `json:"style"`
+	Text  string `json:"text"`
+	HTML  string `json:"html"`
+}
+
+// ValidateCitation checks citation fields beyond what request binding covers
+func ValidateCitation(c models.Citation) error {
+	if c.SourceURL != "" {
+		u, err := url.Parse(c.SourceURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("source_url must be an http or https URL")
+		}
+	}
+
+	if c.DateRead != "" {
+		date, err := time.Parse("2006-01-02", c.DateRead)
+		if err != nil {
+			return errors.New("date_read must be formatted YYYY-MM-DD")
+		}
+		if date.After(time.Now()) {
+			return errors.New("date_read cannot be in the future")
+		}
+	}
+
+	return nil
+}
+
+// FormatCitation renders a citation in the given style
+func FormatCitation(style string, src CitationSource) (*FormattedCitation, error) {
+	var parts []citationPart
+
+	switch style {
+	case CitationStyleMLA:
+		parts = formatMLA(src)
+	case CitationStyleAPA:
+		parts = formatAPA(src)
+	case CitationStyleChicago:
+		parts = formatChicago(src)
+	default:
+		return nil, fmt.Errorf("unsupported citation style: %s", style)
+	}
+
+	var text, markup strings.Builder
+	for _, p := range parts {
+		text.WriteString(p.text)
+		if p.italic {
+			markup.WriteString("<em>" + html.EscapeString(p.text) + "</em>")
+		} else {
+			markup.WriteString(html.EscapeString(p.text))
+		}
+	}
+
+	return &FormattedCitation{Style: style, Text: text.String(), HTML: markup.String()}, nil
+}
+
+// citationPart is a run of citation text, optionally italicised
+type citationPart struct {
+	text   string
+	italic bool
+}
+
+func plain(format string, args ...interface{}) citationPart {
+	return citationPart{text: fmt.Sprintf(format, args...)}
+}
+
+func italic(text string) citationPart {
+	return citationPart{text: text, italic: true}
+}
+
+// formatMLA renders MLA 9th edition: Last, First. Title. Edition, Year, p. 42.
+func formatMLA(src CitationSource) []citationPart {
+	parts := []citationPart{}
+	if name := invertName(src.Author); name != "" {
+		parts = append(parts, plain("%s. ", strings.TrimSuffix(name, ".")))
+	}
+	parts = append(parts, italic(src.Title), plain(". "))
+
+	var details []string
+	if src.Edition != "" {
+		details = append(details, editionLabel(src.Edition))
+	}
+	if src.PublishYear > 0 {
+		details = append(details, fmt.Sprintf("%d", src.PublishYear))
+	}
+	if src.Chapter != "" {
+		details = append(details, "ch. "+src.Chapter)
+	}
+	if loc := pageLabel(src.Page, "p. ", "pp. "); loc != "" {
+		details = append(details, loc)
+	} else if src.Location != "" {
+		details = append(details, "loc. "+src.Location)
+	}
+	if len(details) > 0 {
+		parts = append(parts, plain("%s. ", strings.Join(details, ", ")))
+	}
+
+	if src.SourceURL != "" {
+		parts = append(parts, plain("%s. ", src.SourceURL))
+		if date := formatDate(src.DateRead, "2 Jan. 2006"); date != "" {
+			parts = append(parts, plain("Accessed %s. ", date))
+		}
+	}
+
+	return trimParts(parts)
+}
+
+// formatAPA renders APA 7th edition: Last, F. (Year). Title (2nd ed.). p. 42.
+func formatAPA(src CitationSource) []citationPart {
+	parts := []citationPart{}
+	if name := initialName(src.Author); name != "" {
+		parts = append(parts, plain("%s ", name))
+	}
+	if src.PublishYear > 0 {
+		parts = append(parts, plain("(%d). ", src.PublishYear))
+	} else {
+		parts = append(parts, plain("(n.d.). "))
+	}
+	parts = append(parts, italic(src.Title))
+	if src.Edition != "" {
+		parts = append(parts, plain(" (%s)", editionLabel(src.Edition)))
+	}
+	parts = append(parts, plain(". "))
+
+	if loc := pageLabel(src.Page, "p. ", "pp. "); loc != "" {
+		parts = append(parts, plain("%s. ", loc))
+	} else if src.Chapter != "" {
+		parts = append(parts, plain("Chapter %s. ", src.Chapter))
+	}
+
+	if src.SourceURL != "" {
+		parts = append(parts, plain("%s", src.SourceURL))
+	}
+
+	return trimParts(parts)
+}
+
+// formatChicago renders a Chicago notes-bibliography footnote:
+// First Last, Title, 2nd ed. (Year), 42.
+func formatChicago(src CitationSource) []citationPart {
+	parts := []citationPart{}
+	if src.Author != "" {
+		parts = append(parts, plain("%s, ", strings.TrimSpace(src.Author)))
+	}
+	parts = append(parts, italic(src.Title))
+	if src.Edition != "" {
+		parts = append(parts, plain(", %s", editionLabel(src.Edition)))
+	}
+	if src.PublishYear > 0 {
+		parts = append(parts, plain(" (%d)", src.PublishYear))
+	}
+	if src.Page != "" {
+		parts = append(parts, plain(", %s", src.Page))
+	} else if src.Location != "" {
+		parts = append(parts, plain(", loc. %s", src.Location))
+	} else if src.Chapter != "" {
+		parts = append(parts, plain(", chap. %s", src.Chapter))
+	}
+	if src.SourceURL != "" {
+		parts = append(parts, plain(", %s", src.SourceURL))
+	}
+	parts = append(parts, plain("."))
+
+	return parts
+}
+
+// invertName turns "Marcus Aurelius" into "Aurelius, Marcus"
+func invertName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) < 2 {
+		return strings.TrimSpace(name)
+	}
+	last := fields[len(fields)-1]
+	return last + ", " + strings.Join(fields[:len(fields)-1], " ")
+}
+
+// initialName turns "Viktor E. Frankl" into "Frankl, V. E."
+func initialName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) < 2 {
+		return strings.TrimSpace(name)
+	}
+
+	initials := make([]string, 0, len(fields)-1)
+	for _, f := range fields[:len(fields)-1] {
+		initials = append(initials, strings.ToUpper(string([]rune(f)[0]))+".")
+	}
+	return fields[len(fields)-1] + ", " + strings.Join(initials, " ")
+}
+
+// editionLabel normalizes "2", "2nd" and "2nd ed." to "2nd ed."
+func editionLabel(edition string) string {
+	edition = strings.TrimSpace(edition)
+	edition = strings.TrimSuffix(strings.TrimSuffix(edition, "."), " ed")
+	edition = strings.TrimSuffix(edition, " edition")
+
+	var n int
+	if _, err := fmt.Sscanf(edition, "%d", &n); err == nil && fmt.Sprintf("%d", n) == edition {
+		edition = fmt.Sprintf("%d%s", n, ordinalSuffix(n))
+	}
+	return edition + " ed."
+}
+
+func ordinalSuffix(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// pageLabel prefixes a page or page range
+func pageLabel(page, single, multiple string) string {
+	page = strings.TrimSpace(page)
+	if page == "" {
+		return ""
+	}
+	if strings.ContainsAny(page, "-–,") {
+		return multiple + page
+	}
+	return single + page
+}
+
+func formatDate(date, layout string) string {
+	if date == "" {
+		return ""
+	}
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// trimParts removes trailing whitespace from the final part
+func trimParts(parts []citationPart) []citationPart {
+	if len(parts) > 0 {
+		last := &parts[len(parts)-1]
+		last.text = strings.TrimRight(last.text, " ")
+	}
+	return parts
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+const (
+	// clozeWordsPerBlank is roughly how many words share each blank
+	clozeWordsPerBlank = 6
+	// maxClozeBlanks caps the blanks in a long quote
+	maxClozeBlanks = 4
+	// minClozeWordLength skips short words, which are rarely the key ones
+	minClozeWordLength = 4
+)
+
+var clozeWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’]\p{L}+)*`)
+
+// clozeStopWords are common words never worth blanking out
+var clozeStopWords = map[string]bool{
+	"about": true, "after": true, "again": true, "also": true, "because": true, "been": true,
+	"before": true, "being": true, "could": true, "does": true, "doing": true, "each": true,
+	"even": true, "every": true, "from": true, "have": true, "having": true, "here": true,
+	"into": true, "just": true, "like": true, "made": true, "make": true, "many": true,
+	"more": true, "most": true, "much": true, "must": true, "only": true, "other": true,
+	"over": true, "same": true, "shall": true, "should": true, "some": true, "such": true,
+	"than": true, "that": true, "their": true, "them": true, "then": true, "there": true,
+	"these": true, "they": true, "this": true, "those": true, "through": true, "very": true,
+	"want": true, "were": true, "what": true, "when": true, "where": true, "which": true,
+	"while": true, "will": true, "with": true, "would": true, "your": true, "yours": true,
+}
+
+// ValidClozeMode reports whether mode is a supported cloze mode
+func ValidClozeMode(mode string) bool {
+	return mode == models.ClozeWords || mode == models.ClozeAuthor
+}
+
+// BuildCloze hides part of q for a review card. Author mode hides the
+// author; words mode blanks out the quote's key words, taken to be its
+// longest words that aren't stop words. Ties go to the earlier word so a
+// quote's blanks stay the same between reviews. A quote with no key words
+// falls back to hiding the author. Hidden words are left out of the cloze.
+func BuildCloze(q models.Quote, mode string) models.Cloze {
+	whole := models.Cloze{
+		Mode:         models.ClozeAuthor,
+		Parts:        []models.ClozePart{{Text: q.Quote}},
+		AuthorHidden: true,
+	}
+	if mode == models.ClozeAuthor {
+		return whole
+	}
+
+	words := clozeWordPattern.FindAllStringIndex(q.Quote, -1)
+	candidates := make([]int, 0, len(words))
+	for i, w := range words {
+		word := q.Quote[w[0]:w[1]]
+		if utf8.RuneCountInString(word) >= minClozeWordLength && !clozeStopWords[strings.ToLower(word)] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return whole
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := words[candidates[i]], words[candidates[j]]
+		return utf8.RuneCountInString(q.Quote[a[0]:a[1]]) > utf8.RuneCountInString(q.Quote[b[0]:b[1]])
+	})
+	blanks := len(words) / clozeWordsPerBlank
+	if blanks < 1 {
+		blanks = 1
+	}
+	if blanks > maxClozeBlanks {
+		blanks = maxClozeBlanks
+	}
+	if blanks > len(candidates) {
+		blanks = len(candidates)
+	}
+	hidden := candidates[:blanks]
+	sort.Ints(hidden)
+
+	cloze := models.Cloze{
+		Mode:   models.ClozeWords,
+		Parts:  make([]models.ClozePart, 0, 2*blanks+1),
+		Author: q.Author,
+		Book:   q.Book,
+	}
+	start := 0
+	for _, i := range hidden {
+		w := words[i]
+		if w[0] > start {
+			cloze.Parts = append(cloze.Parts, models.ClozePart{Text: q.Quote[start:w[0]]})
+		}
+		cloze.Parts = append(cloze.Parts, models.ClozePart{Hidden: true, Length: utf8.RuneCountInString(q.Quote[w[0]:w[1]])})
+		start = w[1]
+	}
+	if start < len(q.Quote) {
+		cloze.Parts = append(cloze.Parts, models.ClozePart{Text: q.Quote[start:]})
+	}
+	return cloze
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	htmltemplate "html/template"
+	"text/template"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// Digest is one quote digest email
+type Digest struct {
+	Username  string
+	Frequency string
+	// Date is the send date in the subscriber's time zone
+	Date      time.Time
+	Quotes    models.Quotes
+	OnThisDay models.Quotes
+
+	UnsubscribeToken string
+}
+
+// Subject returns the email subject line
+func (d *Digest) Subject() string {
+	if d.Frequency == models.DigestWeekly {
+		return "Your weekly Zetl digest"
+	}
+	return "Your daily Zetl digest"
+}
+
+// IsEmpty reports whether the digest has no quotes to send
+func (d *Digest) IsEmpty() bool {
+	return len(d.Quotes) == 0 && len(d.OnThisDay) == 0
+}
+
+// QuoteIDs returns the IDs of every quote in the digest
+func (d *Digest) QuoteIDs() []int {
+	ids := make([]int, 0, len(d.Quotes)+len(d.OnThisDay))
+	for _, q := range d.OnThisDay {
+		ids = append(ids, q.QuoteID)
+	}
+	for _, q := range d.Quotes {
+		ids = append(ids, q.QuoteID)
+	}
+	return ids
+}
+
+// ComposeDigest picks the quotes for sub's digest sent at now: an "on this
+// day" section of quotes saved on the same date in earlier years, and up to
+// config.DigestQuoteCount other quotes chosen by the subscription's selection
+func ComposeDigest(ctx context.Context, store database.DigestStore, sub *models.DigestSubscription, now time.Time) (*Digest, error) {
+	day := now.In(sub.Location())
+
+	onThisDay, err := store.GetOnThisDayQuotes(ctx, sub.UserID, day)
+	if err != nil {
+		return nil, err
+	}
+	if len(onThisDay) > config.DigestOnThisDayCount {
+		onThisDay = onThisDay[:config.DigestOnThisDayCount]
+	}
+
+	// Ask for extra quotes so the selection stays full after dropping the
+	// ones already shown under "on this day"
+	candidates, err := store.GetDigestQuotes(ctx, sub.UserID, sub.Selection, config.DigestQuoteCount+len(onThisDay))
+	if err != nil {
+		return nil, err
+	}
+
+	shown := make(map[int]bool, len(onThisDay))
+	for _, q := range onThisDay {
+		shown[q.QuoteID] = true
+	}
+	quotes := make(models.Quotes, 0, config.DigestQuoteCount)
+	for _, q := range candidates {
+		if !shown[q.QuoteID] && len(quotes) < config.DigestQuoteCount {
+			quotes = append(quotes, q)
+		}
+	}
+
+	return &Digest{
+		Username:         sub.Username,
+		Frequency:        sub.Frequency,
+		Date:             day,
+		Quotes:           quotes,
+		OnThisDay:        onThisDay,
+		UnsubscribeToken: sub.UnsubscribeToken,
+	}, nil
+}
+
+// digestView is the data passed to the digest templates
+type digestView struct {
+	*Digest
+	Year           int
+	SettingsURL    string
+	UnsubscribeURL string
+}
+
+// yearsAgo formats how long before year a quote was saved
+func yearsAgo(year int, q models.Quote) int {
+	return year - q.CreatedAt.Year()
+}
+
+var digestTextTemplate = template.Must(template.New("digest.txt").Funcs(template.FuncMap{"yearsAgo": yearsAgo}).Parse(
+	`Hello {{ .Username }},
+{{ if .OnThisDay }}
+ON THIS DAY
+{{ range .OnThisDay }}
+"{{ .Quote }}"
+- {{ .Author }}{{ if .Book }}, {{ .Book }}{{ end }} (saved {{ yearsAgo $.Year . }} year{{ if ne (yearsAgo $.Year .) 1 }}s{{ end }} ago)
+{{ end }}{{ end }}{{ if .Quotes }}
+FROM YOUR QUOTES
+{{ range .Quotes }}
+"{{ .Quote }}"
+- {{ .Author }}{{ if .Book }}, {{ .Book }}{{ end }}
+{{ end }}{{ end }}
+--
+Change your digest settings: {{ .SettingsURL }}
+Unsubscribe: {{ .UnsubscribeURL }}
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(htmltemplate.FuncMap{"yearsAgo": yearsAgo}).Parse(
+	`<!DOCTYPE html>
+<html lang="en">
+<body style="margin:0;padding:24px;background:#09090b;font-family:Georgia,serif;color:#e4e4e7;">
+  <div style="max-width:560px;margin:0 auto;">
+    <p style="font-size:16px;">Hello {{ .Username }},</p>
+    {{ if .OnThisDay }}
+    <h2 style="font-size:13px;letter-spacing:0.1em;text-transform:uppercase;color:#22d3ee;margin-top:32px;">On this day</h2>
+    {{ range .OnThisDay }}
+    <div style="background:#18181b;border:1px solid #27272a;border-radius:12px;padding:20px;margin-bottom:16px;">
+      <p style="font-size:17px;line-height:1.6;margin:0 0 12px;">&ldquo;{{ .Quote }}&rdquo;</p>
+      <p style="font-size:14px;color:#22d3ee;margin:0;">{{ .Author }}{{ if .Book }} &middot; <em style="color:#a1a1aa;">{{ .Book }}</em>{{ end }}</p>
+      <p style="font-size:12px;color:#71717a;margin:8px 0 0;">Saved {{ yearsAgo $.Year . }} year{{ if ne (yearsAgo $.Year .) 1 }}s{{ end }} ago</p>
+    </div>
+    {{ end }}
+    {{ end }}
+    {{ if .Quotes }}
+    <h2 style="font-size:13px;letter-spacing:0.1em;text-transform:uppercase;color:#22d3ee;margin-top:32px;">From your quotes</h2>
+    {{ range .Quotes }}
+    <div style="background:#18181b;border:1px solid #27272a;border-radius:12px;padding:20px;margin-bottom:16px;">
+      <p style="font-size:17px;line-height:1.6;margin:0 0 12px;">&ldquo;{{ .Quote }}&rdquo;</p>
+      <p style="font-size:14px;color:#22d3ee;margin:0;">{{ .Author }}{{ if .Book }} &middot; <em style="color:#a1a1aa;">{{ .Book }}</em>{{ end }}</p>
+    </div>
+    {{ end }}
+    {{ end }}
+    <p style="font-size:12px;color:#71717a;margin-top:32px;border-top:1px solid #27272a;padding-top:16px;">
+      <a href="{{ .SettingsURL }}" style="color:#71717a;">Digest settings</a> &middot;
+      <a href="{{ .UnsubscribeURL }}" style="color:#71717a;">Unsubscribe</a>
+    </p>
+  </div>
+</body>
+</html>
+`))
+
+// renderDigest renders d as plain text and HTML with links based at appURL
+func renderDigest(d *Digest, appURL string) (string, string, error) {
+	view := digestView{
+		Digest:         d,
+		Year:           d.Date.Year(),
+		SettingsURL:    appURL + "/settings",
+		UnsubscribeURL: digestUnsubscribeURL(appURL, d.UnsubscribeToken),
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, view); err != nil {
+		return "", "", err
+	}
+	if err := digestHTMLTemplate.Execute(&html, view); err != nil {
+		return "", "", err
+	}
+	return text.String(), html.String(), nil
+}
+
+// digestUnsubscribeURL is the one-click unsubscribe link for a token
+func digestUnsubscribeURL(appURL, token string) string {
+	return appURL + "/digest/unsubscribe?token=" + token
+}
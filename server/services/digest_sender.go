@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+)
+
+// DigestMailer sends a composed digest to a subscriber
+type DigestMailer interface {
+	SendDigestEmail(toEmail string, d *Digest) error
+}
+
+// DigestSender emails quote digests to subscribers as they come due
+type DigestSender struct {
+	store database.DigestStore
+	email DigestMailer
+	wg    sync.WaitGroup
+}
+
+// NewDigestSender creates a new DigestSender instance
+func NewDigestSender(store database.DigestStore, email DigestMailer) *DigestSender {
+	return &DigestSender{store: store, email: email}
+}
+
+// Start sends due digests now and then every DigestSendInterval until ctx
+// is cancelled
+func (s *DigestSender) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(config.DigestSendInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.SendDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).Error("failed to send digests", "component", "digest_sender", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the worker started by Start has returned
+func (s *DigestSender) Wait() {
+	s.wg.Wait()
+}
+
+// SendDue sends every digest due at now, in batches of DigestBatchSize.
+// Each batch is claimed for DigestLease before sending, so other replicas
+// leave it alone. Each subscription is rescheduled whether or not its email
+// went out, so a failing address is retried at its next scheduled time rather
+// than on every tick. Digests with no quotes are skipped.
+func (s *DigestSender) SendDue(ctx context.Context, now time.Time) error {
+	log := logging.FromContext(ctx)
+
+	for {
+		subs, err := s.store.ClaimDueDigests(ctx, now, now.Add(config.DigestLease), config.DigestBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range subs {
+			sub := &subs[i]
+
+			digest, err := ComposeDigest(ctx, s.store, sub, now)
+			if err != nil {
+				return err
+			}
+
+			next := sub.NextSend(now)
+			if digest.IsEmpty() {
+				err = s.store.RescheduleDigest(ctx, sub.UserID, next)
+			} else if sendErr := s.email.SendDigestEmail(sub.Email, digest); sendErr != nil {
+				log.Error("failed to send digest", "component", "digest_sender", "user_id", sub.UserID, "error", sendErr)
+				err = s.store.RescheduleDigest(ctx, sub.UserID, next)
+			} else {
+				err = s.store.MarkDigestSent(ctx, sub.UserID, now, next, digest.QuoteIDs())
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(subs) < config.DigestBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// fakeMailer records digests instead of sending them. Each send takes a
+// moment so concurrent senders overlap the way SMTP round trips do.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent map[string]int
+}
+
+func (m *fakeMailer) SendDigestEmail(toEmail string, d *Digest) error {
+	time.Sleep(time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent[toEmail]++
+	return nil
+}
+
+func TestDigestSenderConcurrentSendersEmailOnce(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	const subscribers = 20
+	for i := 0; i < subscribers; i++ {
+		user := &models.User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i), IsActive: true}
+		if err := store.CreateUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.CreateQuote(ctx, user.ID, "You have power over your mind", "Marcus Aurelius", "Meditations", nil, "", models.Citation{}, models.VisibilityPrivate); err != nil {
+			t.Fatal(err)
+		}
+		sub := &models.DigestSubscription{
+			UserID: user.ID, Frequency: models.DigestDaily, Selection: models.DigestRandom,
+			Timezone: "UTC", SendHour: 9, NextSendAt: now.Add(-time.Minute),
+		}
+		if err := store.SaveDigestSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mailer := &fakeMailer{sent: make(map[string]int)}
+	senders := []*DigestSender{NewDigestSender(store, mailer), NewDigestSender(store, mailer)}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(senders))
+	for _, s := range senders {
+		wg.Add(1)
+		go func(s *DigestSender) {
+			defer wg.Done()
+			errs <- s.SendDue(ctx, now)
+		}(s)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(mailer.sent) != subscribers {
+		t.Fatalf("emailed %d subscribers, want %d", len(mailer.sent), subscribers)
+	}
+	for email, n := range mailer.sent {
+		if n != 1 {
+			t.Errorf("%s got %d digests, want 1", email, n)
+		}
+	}
+
+	// Sent digests are rescheduled to their next send time, not left on the lease
+	sub, err := store.GetDigestSubscription(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(24 * time.Hour); !sub.NextSendAt.Equal(want) {
+		t.Errorf("next send = %v, want %v", sub.NextSendAt, want)
+	}
+}
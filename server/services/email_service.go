@@ -1,11 +1,18 @@
 package services
 
 import (
+	"bytes"
 	"crypto/tls"
 	"fmt"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net"
 	"net/smtp"
-	"os"
+	"net/textproto"
+	"strconv"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/metrics"
 )
 
 type EmailService struct {
@@ -17,15 +24,15 @@ type EmailService struct {
 	appURL   string
 }
 
-// NewEmailService creates a new email service from environment variables
-func NewEmailService() *EmailService {
+// NewEmailService creates a new email service. appURL is the base for links in emails.
+func NewEmailService(cfg config.SMTP, appURL string) *EmailService {
 	return &EmailService{
-		host:     os.Getenv("SMTP_HOST"),
-		port:     os.Getenv("SMTP_PORT"),
-		username: os.Getenv("SMTP_USERNAME"),
-		password: os.Getenv("SMTP_PASSWORD"),
-		from:     os.Getenv("SMTP_FROM"),
-		appURL:   os.Getenv("APP_URL"),
+		host:     cfg.Host,
+		port:     strconv.Itoa(cfg.Port),
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     cfg.From,
+		appURL:   appURL,
 	}
 }
 
@@ -37,6 +44,7 @@ func (e *EmailService) IsConfigured() bool {
 // SendPasswordResetEmail sends a password reset email to the user
 func (e *EmailService) SendPasswordResetEmail(toEmail, token string) error {
 	if !e.IsConfigured() {
+		metrics.EmailsSentTotal.WithLabelValues("password_reset", "not_configured").Inc()
 		return fmt.Errorf("email service is not configured")
 	}
 
@@ -57,12 +65,85 @@ If you didn't request this, please ignore this email. Your password won't be cha
 Best,
 The Zetl Team`, resetLink)
 
-	return e.sendEmailWithTLS(toEmail, subject, body)
+	return e.send("password_reset", toEmail, subject, body)
+}
+
+// RenderDigest renders a digest as plain text and HTML
+func (e *EmailService) RenderDigest(d *Digest) (text, html string, err error) {
+	return renderDigest(d, e.appURL)
+}
+
+// SendDigestEmail sends a quote digest as a multipart plain text and HTML
+// email with one-click unsubscribe headers
+func (e *EmailService) SendDigestEmail(toEmail string, d *Digest) error {
+	if !e.IsConfigured() {
+		metrics.EmailsSentTotal.WithLabelValues("digest", "not_configured").Inc()
+		return fmt.Errorf("email service is not configured")
+	}
+
+	text, html, err := e.RenderDigest(d)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, alt := range []struct{ contentType, content string }{
+		{"text/plain; charset=\"utf-8\"", text},
+		{"text/html; charset=\"utf-8\"", html},
+	} {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alt.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(alt.content)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	headers := fmt.Sprintf("List-Unsubscribe: <%s>\r\nList-Unsubscribe-Post: List-Unsubscribe=One-Click\r\nContent-Type: multipart/alternative; boundary=%q",
+		digestUnsubscribeURL(e.appURL, d.UnsubscribeToken), mw.Boundary())
+
+	return e.deliver("digest", toEmail, e.message(toEmail, d.Subject(), headers, body.String()))
+}
+
+// send delivers a plain text email and records its outcome under the given kind
+func (e *EmailService) send(kind, toEmail, subject, body string) error {
+	return e.deliver(kind, toEmail, e.message(toEmail, subject, "Content-Type: text/plain; charset=\"utf-8\"", body))
+}
+
+// message builds a complete email from extra headers and a body
+func (e *EmailService) message(toEmail, subject, headers, body string) string {
+	return fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\n%s\r\n\r\n%s",
+		e.from, toEmail, subject, headers, body)
+}
+
+// deliver sends a built message and records its outcome under the given kind
+func (e *EmailService) deliver(kind, toEmail, message string) error {
+	err := e.sendEmailWithTLS(toEmail, message)
+
+	outcome := "sent"
+	if err != nil {
+		outcome = "failed"
+	}
+	metrics.EmailsSentTotal.WithLabelValues(kind, outcome).Inc()
+
+	return err
 }
 
 // sendEmailWithTLS sends an email using STARTTLS (required for Gmail port 587)
-func (e *EmailService) sendEmailWithTLS(toEmail, subject, body string) error {
-	addr := fmt.Sprintf("%s:%s", e.host, e.port)
+func (e *EmailService) sendEmailWithTLS(toEmail, message string) error {
+	addr := net.JoinHostPort(e.host, e.port)
 
 	// Connect to the SMTP server
 	conn, err := net.Dial("tcp", addr)
@@ -106,9 +187,6 @@ func (e *EmailService) sendEmailWithTLS(toEmail, subject, body string) error {
 		return fmt.Errorf("failed to get data writer: %w", err)
 	}
 
-	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=\"utf-8\"\r\n\r\n%s",
-		e.from, toEmail, subject, body)
-
 	_, err = writer.Write([]byte(message))
 	if err != nil {
 		return fmt.Errorf("failed to write message: %w", err)
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// Syndication formats
+const (
+	FeedFormatAtom = "atom"
+	FeedFormatRSS  = "rss"
+	FeedFormatJSON = "json"
+)
+
+// FeedContentTypes maps each syndication format to its MIME type
+var FeedContentTypes = map[string]string{
+	FeedFormatAtom: "application/atom+xml; charset=utf-8",
+	FeedFormatRSS:  "application/rss+xml; charset=utf-8",
+	FeedFormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// feedTitleLength is where entry titles taken from quote text are cut off
+const feedTitleLength = 80
+
+// QuoteFeed is a syndication feed of public quotes, newest first
+type QuoteFeed struct {
+	Title       string
+	Description string
+	// Author is credited for the whole feed, since entries don't carry one
+	Author string
+	// BaseURL is the site's absolute URL; SelfURL is the feed's own URL and
+	// HomeURL the page it describes
+	BaseURL string
+	SelfURL string
+	HomeURL string
+	// Updated is when anything in the feed last changed
+	Updated time.Time
+	Quotes  models.Quotes
+}
+
+// NewQuoteFeed builds a feed of quotes. Its Updated time is the latest
+// quote update, or since when there are no quotes.
+func NewQuoteFeed(title, description, author, baseURL, path string, quotes models.Quotes, since time.Time) *QuoteFeed {
+	f := &QuoteFeed{
+		Title:       title,
+		Description: description,
+		Author:      author,
+		BaseURL:     baseURL,
+		SelfURL:     baseURL + path,
+		HomeURL:     baseURL + "/explore",
+		Updated:     since,
+		Quotes:      quotes,
+	}
+	for _, q := range quotes {
+		if q.UpdatedAt.After(f.Updated) {
+			f.Updated = q.UpdatedAt
+		}
+	}
+	return f
+}
+
+// ETag identifies this version of the feed in format. It changes whenever
+// a quote is added, edited or removed.
+func (f *QuoteFeed) ETag(format string) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join([]string{format, f.SelfURL, f.Title, f.Author}, "\x00")))
+	for _, q := range f.Quotes {
+		h.Write([]byte("\x00" + strconv.Itoa(q.QuoteID) + ":" + strconv.FormatInt(q.UpdatedAt.UnixNano(), 10)))
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// Render encodes the feed in format
+func (f *QuoteFeed) Render(format string) ([]byte, error) {
+	switch format {
+	case FeedFormatRSS:
+		return f.rss()
+	case FeedFormatJSON:
+		return f.jsonFeed()
+	default:
+		return f.atom()
+	}
+}
+
+// entryURL links an entry to the quote's public image card
+func (f *QuoteFeed) entryURL(q models.Quote) string {
+	return f.BaseURL + "/quote/" + strconv.Itoa(q.QuoteID) + "/card.png"
+}
+
+// entryTitle is the start of the quote followed by its author
+func entryTitle(q models.Quote) string {
+	text := strings.Join(strings.Fields(q.Quote), " ")
+	if runes := []rune(text); len(runes) > feedTitleLength {
+		text = strings.TrimSpace(string(runes[:feedTitleLength-1])) + "…"
+	}
+	if q.Author == "" {
+		return text
+	}
+	return text + " — " + q.Author
+}
+
+// entryText is the quote with its attribution as plain text
+func entryText(q models.Quote) string {
+	text := "“" + q.Quote + "”"
+	if q.Author != "" {
+		text += "\n— " + q.Author
+		if q.Book != "" {
+			text += ", " + q.Book
+		}
+	}
+	return text
+}
+
+// entryHTML is the quote with its attribution as escaped HTML
+func entryHTML(q models.Quote) string {
+	var b strings.Builder
+	b.WriteString("<blockquote><p>")
+	b.WriteString(strings.ReplaceAll(html.EscapeString(q.Quote), "\n", "<br>"))
+	b.WriteString("</p></blockquote>")
+	if q.Author != "" {
+		b.WriteString("<p>— ")
+		b.WriteString(html.EscapeString(q.Author))
+		if q.Book != "" {
+			b.WriteString(", <cite>")
+			b.WriteString(html.EscapeString(q.Book))
+			b.WriteString("</cite>")
+		}
+		b.WriteString("</p>")
+	}
+	return b.String()
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Link       atomLink       `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func (f *QuoteFeed) atom() ([]byte, error) {
+	feed := atomFeed{
+		ID:       f.SelfURL,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.SelfURL},
+			{Rel: "alternate", Type: "text/html", Href: f.HomeURL},
+		},
+		Author:  atomPerson{Name: f.Author},
+		Entries: make([]atomEntry, 0, len(f.Quotes)),
+	}
+	for _, q := range f.Quotes {
+		entry := atomEntry{
+			ID:        f.entryURL(q),
+			Title:     entryTitle(q),
+			Updated:   q.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: q.CreatedAt.UTC().Format(time.RFC3339),
+			Link:      atomLink{Rel: "alternate", Type: "image/png", Href: f.entryURL(q)},
+			Content:   atomContent{Type: "html", Body: entryHTML(q)},
+		}
+		for _, tag := range q.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (f *QuoteFeed) rss() ([]byte, error) {
+	description := f.Description
+	if description == "" {
+		description = f.Title
+	}
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.HomeURL,
+			Description:   description,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			AtomLink:      atomLink{Rel: "self", Type: "application/rss+xml", Href: f.SelfURL},
+			Items:         make([]rssItem, 0, len(f.Quotes)),
+		},
+	}
+	for _, q := range f.Quotes {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       entryTitle(q),
+			Link:        f.entryURL(q),
+			GUID:        rssGUID{IsPermaLink: false, Value: f.entryURL(q)},
+			PubDate:     q.CreatedAt.UTC().Format(time.RFC1123Z),
+			Categories:  q.Tags,
+			Description: entryHTML(q),
+		})
+	}
+	return marshalXML(doc)
+}
+
+// marshalXML encodes v as an indented XML document
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Authors     []jsonAuthor   `json:"authors"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	ContentText   string   `json:"content_text"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+func (f *QuoteFeed) jsonFeed() ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.SelfURL,
+		Description: f.Description,
+		Authors:     []jsonAuthor{{Name: f.Author}},
+		Items:       make([]jsonFeedItem, 0, len(f.Quotes)),
+	}
+	for _, q := range f.Quotes {
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            f.entryURL(q),
+			URL:           f.entryURL(q),
+			Title:         entryTitle(q),
+			ContentHTML:   entryHTML(q),
+			ContentText:   entryText(q),
+			DatePublished: q.CreatedAt.UTC().Format(time.RFC3339),
+			DateModified:  q.UpdatedAt.UTC().Format(time.RFC3339),
+			Tags:          q.Tags,
+		})
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -7,9 +7,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/metrics"
 )
 
+// geminiModel is the model used for prompt generation
+const geminiModel = "gemini-2.0-flash"
+
 // GeminiService handles interactions with the Gemini API
 type GeminiService struct {
 	apiKey string
@@ -39,8 +45,16 @@ type GeminiPart struct {
 
 // GeminiResponse represents the response from Gemini API
 type GeminiResponse struct {
-	Candidates []GeminiCandidate `json:"candidates"`
-	Error      *GeminiError      `json:"error,omitempty"`
+	Candidates    []GeminiCandidate    `json:"candidates"`
+	UsageMetadata *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
+	Error         *GeminiError         `json:"error,omitempty"`
+}
+
+// GeminiUsageMetadata reports token usage for a request
+type GeminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
 // GeminiCandidate represents a response candidate
@@ -55,9 +69,9 @@ type GeminiError struct {
 }
 
 // NewGeminiService creates a new GeminiService instance
-func NewGeminiService() *GeminiService {
+func NewGeminiService(cfg config.Gemini) *GeminiService {
 	return &GeminiService{
-		apiKey: os.Getenv("GEMINI_API_KEY"),
+		apiKey: cfg.APIKey,
 	}
 }
 
@@ -113,10 +127,20 @@ Requirements:
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	start := time.Now()
+	text, err := s.generateContent(jsonBody)
+	metrics.LLMRequestDuration.WithLabelValues(geminiModel, metrics.Outcome(err)).Observe(time.Since(start).Seconds())
+
+	return text, err
+}
+
+// generateContent sends a request body to the Gemini API and returns the first candidate's text
+func (s *GeminiService) generateContent(jsonBody []byte) (string, error) {
 	// Make the API request
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent?key=%s", s.apiKey)
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", geminiModel, s.apiKey)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
+		metrics.LLMErrorsTotal.WithLabelValues(geminiModel, "request").Inc()
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -125,25 +149,35 @@ Requirements:
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
+		metrics.LLMErrorsTotal.WithLabelValues(geminiModel, "transport").Inc()
 		return "", fmt.Errorf("failed to call Gemini API: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		metrics.LLMErrorsTotal.WithLabelValues(geminiModel, "transport").Inc()
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
 	var geminiResp GeminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		metrics.LLMErrorsTotal.WithLabelValues(geminiModel, "decode").Inc()
 		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	if usage := geminiResp.UsageMetadata; usage != nil {
+		metrics.LLMTokensTotal.WithLabelValues(geminiModel, "prompt").Add(float64(usage.PromptTokenCount))
+		metrics.LLMTokensTotal.WithLabelValues(geminiModel, "completion").Add(float64(usage.CandidatesTokenCount))
+	}
+
 	if geminiResp.Error != nil {
+		metrics.LLMErrorsTotal.WithLabelValues(geminiModel, "api").Inc()
 		return "", fmt.Errorf("Gemini API error: %s", geminiResp.Error.Message)
 	}
 
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		metrics.LLMErrorsTotal.WithLabelValues(geminiModel, "empty").Inc()
 		return "", errors.New("no response from Gemini API")
 	}
 
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	ErrUnknownCardTheme  = errors.New("unknown card theme")
+	ErrUnknownCardAspect = errors.New("unknown card aspect")
+)
+
+const (
+	DefaultCardTheme  = "dark"
+	DefaultCardAspect = "og"
+)
+
+// CardTheme is the color palette used to render a quote card
+type CardTheme struct {
+	Background color.RGBA
+	Text       color.RGBA
+	Accent     color.RGBA
+	Muted      color.RGBA
+}
+
+// CardThemes are the available card palettes, keyed by name
+var CardThemes = map[string]CardTheme{
+	"dark": {
+		Background: color.RGBA{9, 9, 11, 255},
+		Text:       color.RGBA{228, 228, 231, 255},
+		Accent:     color.RGBA{34, 211, 238, 255},
+		Muted:      color.RGBA{113, 113, 122, 255},
+	},
+	"light": {
+		Background: color.RGBA{250, 250, 250, 255},
+		Text:       color.RGBA{24, 24, 27, 255},
+		Accent:     color.RGBA{8, 145, 178, 255},
+		Muted:      color.RGBA{113, 113, 122, 255},
+	},
+	"sepia": {
+		Background: color.RGBA{244, 236, 216, 255},
+		Text:       color.RGBA{67, 52, 34, 255},
+		Accent:     color.RGBA{145, 85, 40, 255},
+		Muted:      color.RGBA{120, 100, 80, 255},
+	},
+}
+
+// CardAspects are the available card dimensions, keyed by name
+var CardAspects = map[string]image.Point{
+	"square": {1080, 1080},
+	"story":  {1080, 1920},
+	"og":     {1200, 630},
+}
+
+// QuoteCard is the content rendered onto an image card
+type QuoteCard struct {
+	Quote  string
+	Author string
+	Book   string
+}
+
+// QuoteCardRenderer draws quotes onto PNG images using the embedded Go fonts
+// and keeps recently rendered cards in memory.
+type QuoteCardRenderer struct {
+	quoteFont  *opentype.Font
+	authorFont *opentype.Font
+	bookFont   *opentype.Font
+
+	mu         sync.Mutex
+	cache      map[string][]byte
+	order      []string
+	maxEntries int
+}
+
+// NewQuoteCardRenderer parses the embedded fonts and creates a renderer that
+// caches up to maxEntries images.
+func NewQuoteCardRenderer(maxEntries int) (*QuoteCardRenderer, error) {
+	quoteFont, err := opentype.Parse(goitalic.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quote font: %w", err)
+	}
+	authorFont, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse author font: %w", err)
+	}
+	bookFont, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse book font: %w", err)
+	}
+
+	return &QuoteCardRenderer{
+		quoteFont:  quoteFont,
+		authorFont: authorFont,
+		bookFont:   bookFont,
+		cache:      make(map[string][]byte),
+		maxEntries: maxEntries,
+	}, nil
+}
+
+// CardKey identifies a rendered card. It changes whenever the content,
+// theme or aspect changes, so it doubles as an ETag.
+func CardKey(card QuoteCard, theme, aspect string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{card.Quote, card.Author, card.Book, theme, aspect}, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ValidateCardOptions reports whether theme and aspect name a known card style
+func ValidateCardOptions(theme, aspect string) error {
+	if _, ok := CardThemes[theme]; !ok {
+		return ErrUnknownCardTheme
+	}
+	if _, ok := CardAspects[aspect]; !ok {
+		return ErrUnknownCardAspect
+	}
+	return nil
+}
+
+// Render returns the PNG for the card along with its cache key
+func (r *QuoteCardRenderer) Render(card QuoteCard, theme, aspect string) ([]byte, string, error) {
+	if err := ValidateCardOptions(theme, aspect); err != nil {
+		return nil, "", err
+	}
+	palette, size := CardThemes[theme], CardAspects[aspect]
+
+	key := CardKey(card, theme, aspect)
+
+	r.mu.Lock()
+	cached, ok := r.cache[key]
+	r.mu.Unlock()
+	if ok {
+		return cached, key, nil
+	}
+
+	data, err := r.draw(card, palette, size)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.store(key, data)
+	return data, key, nil
+}
+
+// store adds an image to the cache, evicting the oldest entry when full
+func (r *QuoteCardRenderer) store(key string, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.cache[key]; exists {
+		return
+	}
+	if r.maxEntries > 0 && len(r.order) >= r.maxEntries {
+		oldest := r.order[0]
+		r.order = r.order[1:]
+		delete(r.cache, oldest)
+	}
+	r.cache[key] = data
+	r.order = append(r.order, key)
+}
+
+func (r *QuoteCardRenderer) draw(card QuoteCard, palette CardTheme, size image.Point) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	draw.Draw(img, img.Bounds(), image.NewUniform(palette.Background), image.Point{}, draw.Src)
+
+	unit := float64(min(size.X, size.Y))
+	margin := size.X / 12
+	textWidth := size.X - 2*margin
+
+	// Accent rule in the top left corner
+	ruleHeight := int(unit / 150)
+	draw.Draw(img, image.Rect(margin, margin, margin+int(unit/10), margin+ruleHeight), image.NewUniform(palette.Accent), image.Point{}, draw.Src)
+
+	// Footer: author, then book
+	authorSize := unit * 0.045
+	bookSize := unit * 0.035
+	footerHeight := int(authorSize * 1.5)
+	if card.Book != "" {
+		footerHeight += int(bookSize * 1.5)
+	}
+	footerTop := size.Y - margin - footerHeight
+
+	authorFace, err := newFace(r.authorFont, authorSize)
+	if err != nil {
+		return nil, err
+	}
+	defer authorFace.Close()
+	drawLine(img, authorFace, palette.Accent, truncateLine(authorFace, card.Author, textWidth), margin, footerTop+int(authorSize))
+
+	if card.Book != "" {
+		bookFace, err := newFace(r.bookFont, bookSize)
+		if err != nil {
+			return nil, err
+		}
+		defer bookFace.Close()
+		drawLine(img, bookFace, palette.Muted, truncateLine(bookFace, card.Book, textWidth), margin, footerTop+int(authorSize*1.5+bookSize))
+	}
+
+	// Quote: shrink the font until the wrapped text fits between the rule and the footer
+	boxTop := margin + ruleHeight + int(unit/20)
+	boxHeight := footerTop - int(unit/20) - boxTop
+	text := "“" + strings.TrimSpace(card.Quote) + "”"
+
+	var (
+		quoteFace  font.Face
+		lines      []string
+		lineHeight int
+	)
+	for quoteSize := unit * 0.08; ; quoteSize *= 0.9 {
+		if quoteFace != nil {
+			quoteFace.Close()
+		}
+		quoteFace, err = newFace(r.quoteFont, quoteSize)
+		if err != nil {
+			return nil, err
+		}
+		lines = wrapText(quoteFace, text, textWidth)
+		lineHeight = int(quoteSize * 1.35)
+
+		if len(lines)*lineHeight <= boxHeight {
+			break
+		}
+		if quoteSize < unit*0.025 {
+			// Still too long at the smallest size: cut it off
+			maxLines := max(boxHeight/lineHeight, 1)
+			lines = lines[:maxLines]
+			lines[maxLines-1] = truncateLine(quoteFace, lines[maxLines-1]+"…", textWidth)
+			break
+		}
+	}
+	defer quoteFace.Close()
+
+	ascent := quoteFace.Metrics().Ascent.Ceil()
+	y := boxTop + (boxHeight-len(lines)*lineHeight)/2 + ascent
+	for _, line := range lines {
+		drawLine(img, quoteFace, palette.Text, line, margin, y)
+		y += lineHeight
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+}
+
+func drawLine(img draw.Image, face font.Face, col color.Color, text string, x, y int) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+// wrapText breaks text into lines no wider than width pixels. Words that are
+// wider than a full line are split by character.
+func wrapText(face font.Face, text string, width int) []string {
+	limit := fixed.I(width)
+	var lines []string
+	line := ""
+
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate) <= limit {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+
+		line = ""
+		for _, r := range word {
+			if line != "" && font.MeasureString(face, line+string(r)) > limit {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncateLine shortens text with an ellipsis so it fits within width pixels
+func truncateLine(face font.Face, text string, width int) string {
+	limit := fixed.I(width)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+	runes := []rune(strings.TrimSuffix(text, "…"))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "…"
+		if font.MeasureString(face, candidate) <= limit {
+			return candidate
+		}
+	}
+	return ""
+}
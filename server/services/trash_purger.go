@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/metrics"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// TrashPurger permanently deletes quotes that have been in the trash
+// longer than the retention period
+type TrashPurger struct {
+	quotes    database.QuoteStore
+	webhooks  *WebhookSender
+	retention time.Duration
+	wg        sync.WaitGroup
+}
+
+// NewTrashPurger creates a new TrashPurger instance. Purged quotes are
+// announced to their owners' webhooks through webhooks.
+func NewTrashPurger(quotes database.QuoteStore, webhooks *WebhookSender, cfg config.Trash) *TrashPurger {
+	return &TrashPurger{quotes: quotes, webhooks: webhooks, retention: cfg.Retention}
+}
+
+// Start purges expired quotes now and then every TrashPurgeInterval until
+// ctx is cancelled
+func (p *TrashPurger) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(config.TrashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).Error("failed to purge trash", "component", "trash_purger", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the worker started by Start has returned
+func (p *TrashPurger) Wait() {
+	p.wg.Wait()
+}
+
+// Purge permanently deletes quotes trashed more than the retention period
+// ago and queues a quote.deleted event for each
+func (p *TrashPurger) Purge(ctx context.Context) error {
+	now := time.Now()
+	purged, err := p.quotes.PurgeDeletedQuotes(ctx, now.Add(-p.retention))
+	if err != nil {
+		return err
+	}
+
+	for i := range purged {
+		if err := p.webhooks.QueueQuoteEvent(ctx, models.WebhookQuoteDeleted, &purged[i], now); err != nil {
+			logging.FromContext(ctx).Error("failed to queue webhook event", "component", "trash_purger",
+				"event", models.WebhookQuoteDeleted, "quote_id", purged[i].QuoteID, "error", err)
+		}
+	}
+
+	if len(purged) > 0 {
+		metrics.QuotesPurgedTotal.Add(float64(len(purged)))
+		logging.FromContext(ctx).Info("purged expired quotes from trash", "component", "trash_purger", "count", len(purged))
+	}
+	return nil
+}
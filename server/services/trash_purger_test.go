@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+func TestTrashPurgerAnnouncesPurgedQuotes(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+
+	user := &models.User{Username: "alice", Email: "alice@example.com", IsActive: true}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	hook := &models.Webhook{UserID: user.ID, URL: "https://example.com/hook", Secret: "secret", Events: models.WebhookEvents, Active: true}
+	if err := store.CreateWebhook(ctx, hook); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := store.CreateQuote(ctx, user.ID, "You have power over your mind", "Marcus Aurelius", "Meditations", nil, "", models.Citation{}, models.VisibilityPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := store.CreateQuote(ctx, user.ID, "The obstacle is the way", "Marcus Aurelius", "Meditations", nil, "", models.Citation{}, models.VisibilityPublic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteQuote(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the deletion is strictly before the purge cutoff
+	time.Sleep(time.Millisecond)
+
+	purger := NewTrashPurger(store, NewWebhookSender(store, WebhookGuard{}), config.Trash{})
+	if err := purger.Purge(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetQuoteByID(ctx, expired); err == nil {
+		t.Fatal("expired quote was not purged")
+	}
+	if _, err := store.GetQuoteByID(ctx, kept); err != nil {
+		t.Fatalf("live quote was purged: %v", err)
+	}
+
+	deliveries, err := store.GetWebhookDeliveries(ctx, hook.WebhookID, config.WebhookDeliveryLogMax)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Event != models.WebhookQuoteDeleted {
+		t.Fatalf("deliveries = %+v, want one quote.deleted", deliveries)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(deliveries[0].Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Quote == nil || payload.Quote.QuoteID != expired || payload.Quote.DeletedAt == nil {
+		t.Fatalf("payload quote = %+v, want the purged quote %d", payload.Quote, expired)
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+var (
+	ErrWebhookURLInvalid     = errors.New("webhook URL must be an http or https URL")
+	ErrWebhookAddressBlocked = errors.New("webhook URL must not point at a loopback, private, link-local or unspecified address")
+	ErrWebhookHostUnresolved = errors.New("webhook URL host could not be resolved")
+)
+
+// blockedWebhookPrefixes are ranges the net.IP predicates don't cover:
+// "this network" and the carrier-grade NAT range often used inside clusters
+var blockedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// WebhookGuard keeps webhook deliveries away from internal services. The
+// zero value blocks loopback, private, link-local and unspecified addresses,
+// both when a URL is registered and on every connection, so a hostname that
+// later resolves to an internal address is still refused.
+type WebhookGuard struct {
+	// AllowPrivate turns the checks off, for tests that deliver to a
+	// receiver on localhost
+	AllowPrivate bool
+}
+
+// CheckURL validates a webhook URL and resolves its host, rejecting it when
+// any of the host's addresses is blocked
+func (g WebhookGuard) CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrWebhookURLInvalid
+	}
+	if g.AllowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if blockedWebhookAddr(ip) {
+			return ErrWebhookAddressBlocked
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return ErrWebhookHostUnresolved
+	}
+	for _, ip := range addrs {
+		if blockedWebhookAddr(ip) {
+			return ErrWebhookAddressBlocked
+		}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control function that refuses connections to
+// blocked addresses after DNS resolution, which also covers DNS rebinding
+func (g WebhookGuard) control(network, address string, _ syscall.RawConn) error {
+	if g.AllowPrivate {
+		return nil
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || blockedWebhookAddr(addrPort.Addr()) {
+		return ErrWebhookAddressBlocked
+	}
+	return nil
+}
+
+// blockedWebhookAddr reports whether ip is an address webhooks may not reach
+func blockedWebhookAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, p := range blockedWebhookPrefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zach-monroe/zetl/server/config"
+	"github.com/zach-monroe/zetl/server/database"
+	"github.com/zach-monroe/zetl/server/logging"
+	"github.com/zach-monroe/zetl/server/models"
+)
+
+// Headers sent with every webhook delivery. The signature is
+// "sha256=" followed by the hex HMAC-SHA256 of the body, keyed with the
+// webhook's secret.
+const (
+	WebhookEventHeader     = "X-Zetl-Event"
+	WebhookDeliveryHeader  = "X-Zetl-Delivery"
+	WebhookSignatureHeader = "X-Zetl-Signature-256"
+)
+
+// webhookResponseLimit caps how much of a response body is read before the
+// connection is released
+const webhookResponseLimit = 64 << 10
+
+// WebhookPayload is the JSON body posted for a quote event
+type WebhookPayload struct {
+	Event      string        `json:"event"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Quote      *models.Quote `json:"quote"`
+}
+
+// NewWebhookPayload encodes the payload for event on quote
+func NewWebhookPayload(event string, quote *models.Quote, now time.Time) ([]byte, error) {
+	return json.Marshal(WebhookPayload{Event: event, OccurredAt: now.UTC(), Quote: quote})
+}
+
+// SignWebhookPayload returns the signature header value for body
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// QueueQuoteEvent queues event on quote for each of its owner's webhooks
+// subscribed to it
+func (s *WebhookSender) QueueQuoteEvent(ctx context.Context, event string, quote *models.Quote, now time.Time) error {
+	payload, err := NewWebhookPayload(event, quote, now)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.EnqueueWebhookEvent(ctx, quote.UserID, event, payload, now)
+	return err
+}
+
+// WebhookSender posts queued webhook deliveries as they come due and
+// schedules retries for the ones that fail
+type WebhookSender struct {
+	store  database.WebhookStore
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+// NewWebhookSender creates a new WebhookSender instance. Redirects are not
+// followed, so a 3xx response counts as a failed delivery. Every connection
+// goes through guard and never through a proxy, so the guard sees the
+// address actually dialed.
+func NewWebhookSender(store database.WebhookStore, guard WebhookGuard) *WebhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: config.WebhookTimeout, Control: guard.control}).DialContext
+
+	return &WebhookSender{
+		store: store,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.WebhookTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Start sends due deliveries now and then every WebhookPollInterval until
+// ctx is cancelled
+func (s *WebhookSender) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(config.WebhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.DeliverDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				logging.FromContext(ctx).Error("failed to send webhooks", "component", "webhook_sender", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the worker started by Start has returned
+func (s *WebhookSender) Wait() {
+	s.wg.Wait()
+}
+
+// DeliverDue sends every delivery due at now, in batches of
+// WebhookBatchSize. Each batch is leased for long enough to send all of it,
+// so other replicas leave it alone.
+func (s *WebhookSender) DeliverDue(ctx context.Context, now time.Time) error {
+	log := logging.FromContext(ctx)
+	lease := config.WebhookBatchSize * config.WebhookTimeout
+
+	for {
+		deliveries, err := s.store.ClaimWebhookDeliveries(ctx, now, now.Add(lease), config.WebhookBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range deliveries {
+			d := &deliveries[i]
+
+			status, sendErr := s.send(ctx, d)
+			d.RecordAttempt(time.Now(), status, sendErr)
+			if d.Status != models.DeliverySucceeded {
+				log.Warn("webhook delivery failed", "component", "webhook_sender", "delivery_id", d.DeliveryID,
+					"attempts", d.Attempts, "status", status, "error", d.Error)
+			}
+
+			if err := s.store.SaveWebhookDeliveryAttempt(ctx, d); err != nil {
+				return err
+			}
+		}
+
+		if len(deliveries) < config.WebhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// send posts a delivery's signed payload and returns the response status
+func (s *WebhookSender) send(ctx context.Context, d *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "zetl-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, d.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(d.DeliveryID))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(d.Secret, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	return resp.StatusCode, nil
+}